@@ -0,0 +1,162 @@
+package api
+
+import (
+	"ascenda/openapi"
+	"ascenda/types"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bodyErrors are the errors of every route decoding a json body.
+var bodyErrors = []*types.Error{types.ErrorInvalidBody, types.ErrorBodyTooLarge, types.ErrorValidationFailed}
+
+// serverErrors are the errors every route may fail with.
+var serverErrors = []*types.Error{types.ErrorInternal, types.ErrorCouldNotMarshalItem,
+	types.ErrorFailedToUnmarshalRecord, types.ErrorFailedToFetchRecord, types.ErrorCouldNotDynamoPutItem,
+	types.ErrorCouldNotDeleteItem, types.ErrorCouldNotQueryDB, types.ErrorCognitoActionFailed,
+	types.ErrorLoadingConfig, types.ErrorServiceUnavailable}
+
+// Document generates the OpenAPI document of the routes. The error responses
+// of each status list the codes the route answers with.
+func Document() *openapi.Document {
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title: "Ascenda admin api",
+			Description: "Manages the users, points accounts, roles and maker checker requests of the Ascenda " +
+				"admin console. Failed requests answer with an ErrorResponse whose code is listed for the status.",
+			Version: "1",
+		},
+		Security: []map[string][]string{{"authorization": {}}},
+		Paths:    map[string]openapi.PathItem{},
+		Components: openapi.Components{
+			SecuritySchemes: map[string]openapi.SecurityScheme{
+				"authorization": {Type: "apiKey", In: "header", Name: "Authorization",
+					Description: "Token checked by the lambda authorizer."},
+			},
+		},
+	}
+
+	for _, route := range Routes {
+		if doc.Paths[route.Path] == nil {
+			doc.Paths[route.Path] = openapi.PathItem{}
+		}
+		doc.Paths[route.Path][strings.ToLower(route.Method)] = operation(&doc.Components, route)
+	}
+	return doc
+}
+
+func operation(components *openapi.Components, route Route) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: route.Operation,
+		Summary:     route.Summary,
+		Tags:        []string{tag(route.Function)},
+		Responses:   map[string]*openapi.Response{},
+	}
+
+	for _, param := range route.Query {
+		op.Parameters = append(op.Parameters, openapi.Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      &openapi.Schema{Type: "string", Enum: param.Enum},
+		})
+	}
+
+	switch {
+	case route.Body != nil:
+		op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			"application/json": {Schema: route.Body.Schema()},
+		}}
+	case route.RawBody != "":
+		op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			route.RawBody: {Schema: &openapi.Schema{Type: "string"}},
+		}}
+	}
+
+	for _, response := range route.Responses {
+		status := strconv.Itoa(response.Status)
+		if op.Responses[status] == nil {
+			op.Responses[status] = &openapi.Response{Content: map[string]openapi.MediaType{}}
+		}
+		content := op.Responses[status]
+		if content.Description != "" {
+			content.Description += " "
+		}
+		content.Description += response.Description
+
+		contentType, schema := "application/json", &openapi.Schema{Type: "string"}
+		if _, text := response.Body.(string); text {
+			contentType = "text/plain"
+		} else {
+			schema = components.SchemaOf(response.Body)
+		}
+		if response.ContentType != "" {
+			contentType = response.ContentType
+		}
+
+		//a second body of the same content type makes the schema a oneOf
+		if existing, ok := content.Content[contentType]; ok {
+			if existing.Schema.OneOf == nil {
+				existing.Schema = &openapi.Schema{OneOf: []*openapi.Schema{existing.Schema}}
+			}
+			existing.Schema.OneOf = append(existing.Schema.OneOf, schema)
+			content.Content[contentType] = existing
+			continue
+		}
+		content.Content[contentType] = openapi.MediaType{Schema: schema}
+	}
+
+	errs := append([]*types.Error{}, route.Errors...)
+	if route.Body != nil {
+		errs = append(errs, bodyErrors...)
+	}
+	errs = append(errs, serverErrors...)
+	errorResponse := components.SchemaOf(types.ErrorResponse{})
+	for status, codes := range errorCodes(errs) {
+		op.Responses[strconv.Itoa(status)] = &openapi.Response{
+			Description: fmt.Sprintf("%s: %s.", http.StatusText(status), strings.Join(codes, ", ")),
+			Content: map[string]openapi.MediaType{"application/json": {Schema: &openapi.Schema{AllOf: []*openapi.Schema{
+				errorResponse,
+				{Properties: map[string]*openapi.Schema{"code": {Type: "string", Enum: codes}}},
+			}}}},
+		}
+	}
+	return op
+}
+
+// errorCodes groups the distinct codes of errs by status, sorted.
+func errorCodes(errs []*types.Error) map[int][]string {
+	codes := map[int][]string{}
+	for _, err := range errs {
+		if !contains(codes[err.Status], err.Code) {
+			codes[err.Status] = append(codes[err.Status], err.Code)
+		}
+	}
+	for _, c := range codes {
+		sort.Strings(c)
+	}
+	return codes
+}
+
+// tag is the group of the function, such as user for functions/user/get-users.
+func tag(function string) string {
+	parts := strings.Split(function, "/")
+	if len(parts) < 2 {
+		return function
+	}
+	return parts[1]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
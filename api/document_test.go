@@ -0,0 +1,246 @@
+package api_test
+
+import (
+	"ascenda/api"
+	"ascenda/awstest"
+	"ascenda/dynamotest"
+	"ascenda/localserver"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	adminID  = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e01"
+	janeID   = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e02"
+	bobID    = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e03"
+	pointsID = "0b9e3c2a-5f4d-4e6b-8a7c-1d2e3f4a5b01"
+	reqID    = "1f2e3d4c-5b6a-4978-8a6b-5c4d3e2f1a01"
+)
+
+func newServer(t *testing.T) *localserver.Server {
+	db := dynamotest.New(dynamotest.Tables...)
+	now := time.Now()
+	db.Seed(t, "roles",
+		types.Role{Role: "admin", Access: map[string][]string{"*": {"*"}}},
+		types.Role{Role: "customer", Access: map[string][]string{"/me": {"GET", "PATCH"}}})
+	db.Seed(t, "users",
+		types.User{User_ID: adminID, Email: "admin@example.com", FirstName: "Ada", LastName: "Admin", Role: "admin"},
+		types.User{User_ID: janeID, Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+		types.User{User_ID: bobID, Email: "bob@example.com", FirstName: "Bob", LastName: "Lee", Role: "customer"})
+	db.Seed(t, "emails",
+		map[string]string{"email": "admin@example.com", "user_id": adminID},
+		map[string]string{"email": "jane@example.com", "user_id": janeID},
+		map[string]string{"email": "bob@example.com", "user_id": bobID})
+	db.Seed(t, "points", types.UserPoint{User_ID: janeID, Points_ID: pointsID, Points: 10})
+	db.Seed(t, "makers", types.MakerRequest{RequestUUID: reqID, CheckerRole: "admin", MakerUUID: adminID,
+		RequestStatus: "pending", ResourceType: "points",
+		RequestData: json.RawMessage(`{"user_id":"` + janeID + `","points_id":"` + pointsID + `","points":15}`)})
+	db.Seed(t, "logs",
+		types.Log{Log_ID: "log-1", Description: "Ada Admin created user Jane Doe", Timestamp: now.Unix()},
+		types.Log{Log_ID: utility.ReconciliationReportID, Timestamp: now.Unix(),
+			Report: &types.DriftReport{GeneratedAt: now.Unix(), Drifts: map[string][]types.Drift{}}})
+	db.Seed(t, "sessions", types.SignInEvent{User_ID: janeID, Timestamp: now.Unix(), Outcome: "success"})
+	db.Seed(t, "import-jobs", types.ImportJob{Job_ID: "job-1", Status: "completed", Requester: "Ada-Admin",
+		Summary: map[string]int{"created": 1}, Rows: []types.ImportRow{{Line: 2, Email: "new@example.com"}}})
+
+	routes, err := localserver.ReadRoutes("../template.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := localserver.New(awstest.Deps(db), routes, localserver.Handlers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.UserID = adminID
+	return server
+}
+
+// scenarios call every route, in an order where each finds the records it
+// needs, and give the status the handler should answer with.
+var scenarios = []struct {
+	method string
+	target string
+	body   string
+	status int
+}{
+	{"GET", "/makers?req_id=" + reqID, "", 200},
+	{"GET", "/makers?maker_id=" + adminID + "&status=pending", "", 200},
+	{"GET", "/makers", "", 200},
+	{"GET", "/makers?req_id=nope", "", 404},
+	{"POST", "/makers", `{"checker_roles":["admin"],"maker_id":"` + adminID + `","resource_type":"points",` +
+		`"request_data":{"user_id":"` + janeID + `","points_id":"` + pointsID + `","points":20}}`, 200},
+	{"POST", "/makers", `{"checker_roles":["admin"],"maker_id":"` + adminID + `","resource_type":"role",` +
+		`"request_data":{}}`, 422},
+	{"GET", "/checkers?role=admin&status=pending", "", 200},
+	{"GET", "/checkers?role=admin", "", 400},
+	{"PUT", "/checkers", `{"request_id":"` + reqID + `","checker_role":"admin","checker_id":"` + adminID +
+		`","decision":"approve"}`, 200},
+	{"GET", "/points?id=" + janeID, "", 200},
+	{"GET", "/points", "", 200},
+	{"PUT", "/points?id=" + janeID + "&requester=Ada-Admin", `{"points_id":"` + pointsID + `","points":30}`, 200},
+	{"PUT", "/points?requester=Ada-Admin", `{"points_id":"` + pointsID + `","points":30}`, 400},
+	{"POST", "/points", `{"user_id":"` + adminID + `"}`, 200},
+	{"GET", "/users?id=" + janeID, "", 200},
+	{"GET", "/users?role=customer", "", 200},
+	{"GET", "/users", "", 200},
+	{"GET", "/users?id=nope", "", 404},
+	{"POST", "/users?requester=Ada-Admin", `{"email":"new@example.com","first_name":"New","last_name":"User",` +
+		`"role":"customer"}`, 200},
+	{"POST", "/users?requester=Ada-Admin", `{"email":"jane@example.com","first_name":"Jane","last_name":"Doe",` +
+		`"role":"customer"}`, 409},
+	{"PUT", "/users?id=" + janeID + "&requester=Ada-Admin", `{"first_name":"Janet"}`, 200},
+	{"PATCH", "/users?id=" + janeID + "&requester=Ada-Admin", `{"role":"nope"}`, 422},
+	{"PATCH", "/users?id=" + janeID + "&requester=Ada-Admin", `{"first_name":"Jane"}`, 200},
+	{"PUT", "/users/disable?id=" + bobID + "&requester=Ada-Admin", "", 200},
+	{"PUT", "/users/restore?id=" + bobID + "&requester=Ada-Admin", "", 200},
+	{"PUT", "/users/restore?id=" + bobID + "&requester=Ada-Admin", "", 409},
+	{"DELETE", "/users?id=" + bobID + "&requester=Ada-Admin", "", 200},
+	{"DELETE", "/users?id=" + bobID + "&requester=Ada-Admin", "", 409},
+	{"POST", "/users/password/reset?id=" + janeID + "&requester=Ada-Admin", "", 200},
+	{"POST", "/users/password/temporary?id=" + janeID + "&requester=Ada-Admin", `{"password":"Temp-Passw0rd!"}`, 200},
+	{"PUT", "/users/mfa?id=" + janeID + "&requester=Ada-Admin", `{"sms":true,"preferred":"SMS"}`, 200},
+	{"POST", "/users/signout?id=" + janeID + "&requester=Ada-Admin", "", 200},
+	{"POST", "/users/signout?requester=Ada-Admin", "", 400},
+	{"GET", "/users/sessions?id=" + janeID, "", 200},
+	{"POST", "/users/import?requester=Ada-Admin", "email,first_name,last_name,role\nimp@example.com,Imp,Orter,customer\n", 202},
+	{"POST", "/users/import?requester=Ada-Admin", "name\nImp\n", 400},
+	{"GET", "/users/import?id=job-1", "", 200},
+	{"GET", "/users/import?id=job-1&format=csv", "", 200},
+	{"GET", "/users/import?id=nope", "", 404},
+	{"GET", "/me", "", 200},
+	{"PATCH", "/me", `{"first_name":"Ada"}`, 200},
+	{"GET", "/me/points", "", 200},
+	{"GET", "/logs?id=log-1", "", 200},
+	{"GET", "/logs", "", 200},
+	{"GET", "/reconciliation", "", 200},
+	{"GET", "/openapi.json", "", 200},
+	{"GET", "/roles?role=admin", "", 200},
+	{"GET", "/roles?role=customer&effective=true", "", 200},
+	{"GET", "/roles", "", 200},
+	{"POST", "/roles", `{"role":"auditor","access":{"/logs":["GET"]}}`, 200},
+	{"POST", "/roles", `{"role":"looped","inherits":["looped"]}`, 422},
+	{"PUT", "/roles?role=auditor", `{"inherits":["customer"],"access":{"/logs":["GET"]}}`, 200},
+	{"DELETE", "/roles?role=customer", "", 409},
+	{"DELETE", "/roles?role=auditor", "", 200},
+}
+
+// route returns the route serving the method and target.
+func route(method, target string) (api.Route, bool) {
+	path, _, _ := strings.Cut(target, "?")
+	for _, route := range api.Routes {
+		if route.Method == method && route.Path == path {
+			return route, true
+		}
+	}
+	return api.Route{}, false
+}
+
+// TestResponsesMatchDocument calls every route through the local server and
+// checks each response against the schema the document gives its status.
+func TestResponsesMatchDocument(t *testing.T) {
+	doc := api.Document()
+	server := newServer(t)
+
+	called := map[string]bool{}
+	for _, scenario := range scenarios {
+		name := scenario.method + " " + scenario.target
+		r, ok := route(scenario.method, scenario.target)
+		if !ok {
+			t.Fatalf("%s: no route", name)
+		}
+		if scenario.status < 300 {
+			called[r.Method+" "+r.Path] = true
+		}
+
+		req := httptest.NewRequest(scenario.method, scenario.target, strings.NewReader(scenario.body))
+		res := httptest.NewRecorder()
+		server.ServeHTTP(res, req)
+		if res.Code != scenario.status {
+			t.Errorf("%s = %d %s, want %d", name, res.Code, res.Body.String(), scenario.status)
+			continue
+		}
+
+		response := doc.Paths[r.Path][strings.ToLower(r.Method)].Responses[strconv.Itoa(res.Code)]
+		if response == nil {
+			t.Errorf("%s: status %d is not documented", name, res.Code)
+			continue
+		}
+		contentType := res.Header().Get("Content-Type")
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+		media, ok := response.Content[contentType]
+		if !ok {
+			t.Errorf("%s: content type %s of status %d is not documented", name, contentType, res.Code)
+			continue
+		}
+		var body interface{} = res.Body.String()
+		if contentType == "application/json" {
+			if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+		}
+		if problems := doc.Validate(media.Schema, body); len(problems) > 0 {
+			t.Errorf("%s = %d %s, which does not match the document:\n%s", name, res.Code, res.Body.String(),
+				strings.Join(problems, "\n"))
+		}
+	}
+
+	for _, r := range api.Routes {
+		if !called[r.Method+" "+r.Path] {
+			t.Errorf("no scenario calls %s %s successfully", r.Method, r.Path)
+		}
+	}
+}
+
+// TestRequiredFieldsMatchDocument sends an empty body to every route taking
+// json and checks it is refused for the fields the document requires.
+func TestRequiredFieldsMatchDocument(t *testing.T) {
+	doc := api.Document()
+	server := newServer(t)
+
+	for _, r := range api.Routes {
+		if r.Body == nil {
+			continue
+		}
+		t.Run(r.Method+" "+r.Path, func(t *testing.T) {
+			op := doc.Paths[r.Path][strings.ToLower(r.Method)]
+			schema := op.RequestBody.Content["application/json"].Schema
+			if len(schema.Required) == 0 {
+				return
+			}
+
+			target := r.Path + "?id=" + janeID + "&role=admin&requester=Ada-Admin"
+			res := httptest.NewRecorder()
+			server.ServeHTTP(res, httptest.NewRequest(r.Method, target, strings.NewReader("{}")))
+			var body struct {
+				types.ErrorResponse
+				Details []struct {
+					Field   string `json:"field"`
+					Message string `json:"message"`
+				} `json:"details"`
+			}
+			if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil || res.Code != 422 {
+				t.Fatalf("empty body = %d %s, want 422", res.Code, res.Body.String())
+			}
+			var required []string
+			for _, detail := range body.Details {
+				if detail.Message == "is required" {
+					required = append(required, detail.Field)
+				}
+			}
+			sort.Strings(required)
+			if strings.Join(required, ",") != strings.Join(schema.Required, ",") {
+				t.Errorf("handler requires %v, the document %v", required, schema.Required)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+// Package api declares the routes of the api in Go, with their query
+// parameters, bodies, responses and errors, and generates the OpenAPI document
+// served at /openapi.json from them and the types structs. template.yaml
+// deploys the same routes, which a test checks, along with each handler's
+// behaviour against its route.
+package api
+
+import (
+	"ascenda/types"
+	"ascenda/validation"
+)
+
+// Param is a query parameter of a route.
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+	Enum        []string
+}
+
+// Response is a successful response of a route. A route answering with
+// different bodies, depending on its parameters, lists one Response for each.
+type Response struct {
+	Status      int
+	Description string
+	// Body is a value of the type of the json body, or a string for a plain
+	// text body.
+	Body interface{}
+	// ContentType replaces the json or plain text content type, such as
+	// text/csv.
+	ContentType string
+}
+
+// Route is an api route and the function serving it.
+type Route struct {
+	Method string
+	Path   string
+	// Function is the CodeUri of the function in template.yaml.
+	Function string
+	// Operation is the operationId, which clients name the call after.
+	Operation string
+	Summary   string
+	Query     []Param
+	// Body holds the rules the handler decodes a json body with.
+	Body validation.Rules
+	// RawBody is the content type of a body that is not json, such as a csv
+	// upload.
+	RawBody   string
+	Responses []Response
+	// Errors lists the client errors of the route besides the body errors of
+	// routes with a Body. Every route may fail with a 500 or 503.
+	Errors []*types.Error
+}
+
+var (
+	userID    = Param{Name: "id", Description: "user_id of the user.", Required: true}
+	key       = Param{Name: "key", Description: "Key returned with the previous page, to get the next one."}
+	requester = Param{Name: "requester", Description: "First and last name of the caller joined by a hyphen, " +
+		"such as Ada-Admin, recorded in the logs."}
+	makerStatus = Param{Name: "status", Description: "Status of the maker requests.",
+		Enum: []string{"pending", "approved", "rejected"}}
+)
+
+// Routes are the routes of the api in the order of template.yaml.
+var Routes = []Route{
+	{
+		Method: "GET", Path: "/makers", Function: "functions/maker/get-makers", Operation: "getMakers",
+		Summary: "Get a maker request, the requests of a maker with a status, or a page of every request",
+		Query: []Param{
+			{Name: "req_id", Description: "req_id of the maker request, with one row per checker role merged."},
+			{Name: "maker_id", Description: "user_id of the maker, together with status."},
+			makerStatus,
+			{Name: "keyReq", Description: "key_req returned with the previous page."},
+			{Name: "keyRole", Description: "key_role returned with the previous page."},
+		},
+		Responses: []Response{
+			{Status: 200, Description: "The maker request, or the requests of the maker.", Body: []types.ReturnMakerRequest{}},
+			{Status: 200, Description: "A page of maker requests.", Body: types.ReturnMakerData{}},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorMakerReqDoesNotExist},
+	},
+	{
+		Method: "POST", Path: "/makers", Function: "functions/maker/create-makers", Operation: "createMakers",
+		Summary: "Request a change to a user or points account for checkers of the given roles to approve",
+		Body:    validation.NewMakerRequest,
+		Responses: []Response{
+			{Status: 200, Description: "The maker request.", Body: []types.ReturnMakerRequest{}},
+		},
+		Errors: []*types.Error{types.ErrorInvalidResourceType, types.ErrorInvalidPointsID, types.ErrorUserDoesNotExist,
+			types.ErrorPointsDoesNotExist},
+	},
+	{
+		Method: "GET", Path: "/checkers", Function: "functions/maker/get-checkers", Operation: "getCheckers",
+		Summary: "Get the maker requests of a checker role with a status",
+		Query: []Param{
+			{Name: "role", Description: "Checker role of the requests.", Required: true},
+			func() Param { status := makerStatus; status.Required = true; return status }(),
+		},
+		Responses: []Response{
+			{Status: 200, Description: "The maker requests.", Body: &[]types.MakerRequest{}},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter},
+	},
+	{
+		Method: "PUT", Path: "/checkers", Function: "functions/maker/update-checkers", Operation: "updateCheckers",
+		Summary: "Approve or reject a maker request, applying the change when approved",
+		Body:    validation.DecisionBody,
+		Responses: []Response{
+			{Status: 200, Description: "The decided maker request.", Body: []types.ReturnMakerRequest{}},
+		},
+		Errors: []*types.Error{types.ErrorInvalidDecision, types.ErrorInvalidResourceType, types.ErrorInvalidUserData,
+			types.ErrorInvalidUserID, types.ErrorInvalidPointsID, types.ErrorMakerDoesNotExist,
+			types.ErrorMakerReqDoesNotExist, types.ErrorUserDoesNotExist, types.ErrorPointsDoesNotExist,
+			types.ErrorUserAlreadyDeleted, types.ErrorPointsAccountClosed, types.ErrorPointsAccountFrozen,
+			types.ErrorEmailAlreadyExists, types.ErrorRecordChanged},
+	},
+	{
+		Method: "GET", Path: "/points", Function: "functions/point/get-points", Operation: "getPoints",
+		Summary: "Get the points accounts of a user, or a page of every account",
+		Query: []Param{
+			{Name: "id", Description: "user_id of the owner of the accounts."},
+			{Name: "keyUser", Description: "key_user returned with the previous page."},
+			{Name: "keyPoint", Description: "key_point returned with the previous page."},
+		},
+		Responses: []Response{
+			{Status: 200, Description: "The accounts of the user.", Body: &[]types.UserPoint{}},
+			{Status: 200, Description: "A page of accounts.", Body: types.ReturnUserPointData{}},
+		},
+		Errors: []*types.Error{types.ErrorPointsDoesNotExist},
+	},
+	{
+		Method: "PUT", Path: "/points", Function: "functions/point/update-points", Operation: "updatePoints",
+		Summary: "Set the balance of a points account",
+		Query:   []Param{userID, requester},
+		Body:    validation.UserPointUpdate,
+		Responses: []Response{
+			{Status: 200, Description: "The updated account.", Body: types.UserPoint{}},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorInvalidUserData, types.ErrorForbidden,
+			types.ErrorInvalidPolicy, types.ErrorNotPermittedByPolicy, types.ErrorUserDoesNotExist,
+			types.ErrorUserAlreadyDeleted, types.ErrorPointsDoesNotExist, types.ErrorPointsAccountClosed,
+			types.ErrorPointsAccountFrozen, types.ErrorPointsChanged},
+	},
+	{
+		Method: "POST", Path: "/points", Function: "functions/point/create-points", Operation: "createPoints",
+		Summary: "Open a points account for a user with a balance of 0",
+		Body:    validation.UserPoint,
+		Responses: []Response{
+			{Status: 200, Description: "The new account.", Body: types.UserPoint{}},
+		},
+		Errors: []*types.Error{types.ErrorUserDoesNotExist},
+	},
+	{
+		Method: "GET", Path: "/users", Function: "functions/user/get-users", Operation: "getUsers",
+		Summary: "Get a user, the users of a role, or a page of every user",
+		Query: []Param{
+			{Name: "id", Description: "user_id of the user."},
+			{Name: "role", Description: "Role of the users."},
+			{Name: "include_deleted", Description: "Include soft deleted users.", Enum: []string{"true", "false"}},
+			key,
+		},
+		Responses: []Response{
+			{Status: 200, Description: "The user.", Body: types.User{}},
+			{Status: 200, Description: "A page of users.", Body: types.ReturnUserData{}},
+		},
+		Errors: []*types.Error{types.ErrorForbidden, types.ErrorInvalidPolicy, types.ErrorNotPermittedByPolicy,
+			types.ErrorUserDoesNotExist},
+	},
+	{
+		Method: "POST", Path: "/users", Function: "functions/user/create-users", Operation: "createUsers",
+		Summary: "Create a user in the user pool and the users table",
+		Query:   []Param{requester},
+		Body:    validation.User,
+		Responses: []Response{
+			{Status: 200, Description: "The new user.", Body: types.User{}},
+		},
+		Errors: []*types.Error{types.ErrorAdminPasswordsDisabled, types.ErrorEmailAlreadyExists,
+			types.ErrorUnknownRole, types.ErrorInvalidPassword, types.ErrorRecordChanged},
+	},
+	{
+		Method: "PUT", Path: "/users", Function: "functions/user/update-users", Operation: "updateUsers",
+		Summary: "Change the email, names or role of a user",
+		Query:   []Param{userID, requester},
+		Body:    validation.UserPatch,
+		Responses: []Response{
+			{Status: 200, Description: "The updated user.", Body: types.User{}},
+		},
+		Errors: updateUserErrors,
+	},
+	{
+		Method: "PATCH", Path: "/users", Function: "functions/user/update-users", Operation: "patchUsers",
+		Summary: "Change the email, names or role of a user",
+		Query:   []Param{userID, requester},
+		Body:    validation.UserPatch,
+		Responses: []Response{
+			{Status: 200, Description: "The updated user.", Body: types.User{}},
+		},
+		Errors: updateUserErrors,
+	},
+	{
+		Method: "DELETE", Path: "/users", Function: "functions/user/delete-users", Operation: "deleteUsers",
+		Summary: "Soft delete a user and disable their login until they are restored or purged",
+		Query: []Param{userID, requester,
+			{Name: "hard", Description: "true removes the user and their points accounts at once, without a restore."},
+			{Name: "role", Description: "Accepted for compatibility and ignored."}},
+		Responses: []Response{
+			{Status: 200, Description: "The user was deleted.", Body: ""},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorForbidden, types.ErrorInvalidPolicy,
+			types.ErrorNotPermittedByPolicy, types.ErrorUserDoesNotExist, types.ErrorUserAlreadyDeleted,
+			types.ErrorRecordChanged},
+	},
+	{
+		Method: "PUT", Path: "/users/disable", Function: "functions/user/disable-users", Operation: "disableUsers",
+		Summary: "Disable the login of a user",
+		Query:   []Param{userID, requester},
+		Responses: []Response{
+			{Status: 200, Description: "The user was disabled.", Body: ""},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorForbidden, types.ErrorInvalidPolicy,
+			types.ErrorNotPermittedByPolicy, types.ErrorUserDoesNotExist, types.ErrorUserAlreadyDeleted},
+	},
+	{
+		Method: "PUT", Path: "/users/restore", Function: "functions/user/restore-users", Operation: "restoreUsers",
+		Summary: "Restore a disabled user, or a deleted user within the retention window",
+		Query:   []Param{userID, requester},
+		Responses: []Response{
+			{Status: 200, Description: "The user was restored.", Body: ""},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorForbidden, types.ErrorInvalidPolicy,
+			types.ErrorNotPermittedByPolicy, types.ErrorUserDoesNotExist, types.ErrorUserNotDeleted,
+			types.ErrorRetentionExpired},
+	},
+	{
+		Method: "POST", Path: "/users/password/reset", Function: "functions/user/reset-password",
+		Operation: "resetPassword",
+		Summary:   "Send the user a code to reset their password",
+		Query:     []Param{userID, requester},
+		Responses: []Response{
+			{Status: 200, Description: "The code was sent.", Body: ""},
+		},
+		Errors: userCognitoErrors,
+	},
+	{
+		Method: "POST", Path: "/users/password/temporary", Function: "functions/user/set-temporary-password",
+		Operation: "setTemporaryPassword",
+		Summary:   "Set a temporary password the user must change at their next sign in",
+		Query:     []Param{userID, requester},
+		Body:      validation.TemporaryPassword,
+		Responses: []Response{
+			{Status: 200, Description: "The password was set.", Body: ""},
+		},
+		Errors: append([]*types.Error{types.ErrorInvalidPassword, types.ErrorAdminPasswordsDisabled},
+			userCognitoErrors...),
+	},
+	{
+		Method: "PUT", Path: "/users/mfa", Function: "functions/user/update-mfa", Operation: "updateMFA",
+		Summary: "Enable, disable or prefer the MFA factors of a user",
+		Query:   []Param{userID, requester},
+		Body:    validation.MFAPreference,
+		Responses: []Response{
+			{Status: 200, Description: "The preference was updated.", Body: ""},
+		},
+		Errors: append([]*types.Error{types.ErrorInvalidMFAPreference}, userCognitoErrors...),
+	},
+	{
+		Method: "POST", Path: "/users/signout", Function: "functions/user/global-sign-out", Operation: "globalSignOut",
+		Summary: "Sign a user out of every device",
+		Query:   []Param{userID, requester},
+		Responses: []Response{
+			{Status: 200, Description: "The user was signed out.", Body: ""},
+		},
+		Errors: userCognitoErrors,
+	},
+	{
+		Method: "GET", Path: "/users/sessions", Function: "functions/user/get-sessions", Operation: "getSessions",
+		Summary: "Get a page of the sign ins of a user, newest first",
+		Query: []Param{userID, key,
+			{Name: "flagged", Description: "Only list flagged sign ins.", Enum: []string{"true", "false"}}},
+		Responses: []Response{
+			{Status: 200, Description: "A page of sign ins.", Body: types.ReturnSignInData{}},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorForbidden, types.ErrorInvalidPolicy,
+			types.ErrorNotPermittedByPolicy, types.ErrorUserDoesNotExist},
+	},
+	{
+		Method: "POST", Path: "/users/import", Function: "functions/user/import-users", Operation: "importUsers",
+		Summary: "Upload a csv of users to create in the background",
+		Query:   []Param{requester},
+		RawBody: "text/csv",
+		Responses: []Response{
+			{Status: 202, Description: "The queued job with a count of rows per status.", Body: types.ImportJob{}},
+		},
+		Errors: []*types.Error{types.ErrorInvalidCSV, types.ErrorImportTooLarge, types.ErrorForbidden,
+			types.ErrorInvalidPolicy},
+	},
+	{
+		Method: "GET", Path: "/users/import", Function: "functions/user/get-imports", Operation: "getImports",
+		Summary: "Get an import job with the outcome of each row",
+		Query: []Param{
+			{Name: "id", Description: "job_id of the import.", Required: true},
+			{Name: "format", Description: "csv downloads the outcome of each row as a csv.", Enum: []string{"csv"}},
+		},
+		Responses: []Response{
+			{Status: 200, Description: "The import job.", Body: types.ImportJob{}},
+			{Status: 200, Description: "The outcome of each row.", Body: "", ContentType: "text/csv"},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorImportJobDoesNotExist},
+	},
+	{
+		Method: "GET", Path: "/me", Function: "functions/profile/get-profile", Operation: "getProfile",
+		Summary: "Get the caller's own user",
+		Responses: []Response{
+			{Status: 200, Description: "The caller.", Body: types.User{}},
+		},
+		Errors: []*types.Error{types.ErrorNotAuthenticated, types.ErrorUserDoesNotExist},
+	},
+	{
+		Method: "PATCH", Path: "/me", Function: "functions/profile/update-profile", Operation: "updateProfile",
+		Summary: "Change the caller's own names",
+		Body:    validation.ProfilePatch,
+		Responses: []Response{
+			{Status: 200, Description: "The updated caller.", Body: types.User{}},
+		},
+		Errors: []*types.Error{types.ErrorNotAuthenticated, types.ErrorUserDoesNotExist, types.ErrorRecordChanged},
+	},
+	{
+		Method: "GET", Path: "/me/points", Function: "functions/profile/get-profile-points", Operation: "getProfilePoints",
+		Summary: "Get the caller's own points accounts",
+		Responses: []Response{
+			{Status: 200, Description: "The caller's accounts.", Body: []types.UserPoint{}},
+		},
+		Errors: []*types.Error{types.ErrorNotAuthenticated, types.ErrorUserDoesNotExist},
+	},
+	{
+		Method: "GET", Path: "/logs", Function: "functions/administrative/get-logs", Operation: "getLogs",
+		Summary: "Get a log, or a page of every log",
+		Query:   []Param{{Name: "id", Description: "log_id of the log."}, key},
+		Responses: []Response{
+			{Status: 200, Description: "The log.", Body: types.Log{}},
+			{Status: 200, Description: "A page of logs.", Body: types.ReturnLogData{}},
+		},
+		Errors: []*types.Error{types.ErrorLogDoesNotExist},
+	},
+	{
+		Method: "GET", Path: "/reconciliation", Function: "functions/administrative/get-reconciliation",
+		Operation: "getReconciliation",
+		Summary:   "Get the latest report of the drift between the users table and the user pool",
+		Responses: []Response{
+			{Status: 200, Description: "The latest report.", Body: types.DriftReport{}},
+		},
+		Errors: []*types.Error{types.ErrorReportDoesNotExist},
+	},
+	{
+		Method: "GET", Path: "/openapi.json", Function: "functions/administrative/get-openapi", Operation: "getOpenAPI",
+		Summary: "Get this document",
+		Responses: []Response{
+			{Status: 200, Description: "The OpenAPI document of the api.", Body: map[string]interface{}{}},
+		},
+	},
+	{
+		Method: "GET", Path: "/roles", Function: "functions/role/get-roles", Operation: "getRoles",
+		Summary: "Get a role, optionally with the access it inherits, or a page of every role",
+		Query: []Param{
+			{Name: "role", Description: "Name of the role."},
+			{Name: "effective", Description: "Merge in the access of inherited roles.", Enum: []string{"true", "false"}},
+			key,
+		},
+		Responses: []Response{
+			{Status: 200, Description: "The role.", Body: types.Role{}},
+			{Status: 200, Description: "A page of roles.", Body: types.ReturnRoleData{}},
+		},
+		Errors: []*types.Error{types.ErrorRoleDoesNotExist, types.ErrorRoleInheritanceCycle},
+	},
+	{
+		Method: "POST", Path: "/roles", Function: "functions/role/create-roles", Operation: "createRoles",
+		Summary: "Create or replace a role",
+		Body:    validation.Role,
+		Responses: []Response{
+			{Status: 200, Description: "The role.", Body: types.Role{}},
+		},
+		Errors: []*types.Error{types.ErrorRoleInheritanceCycle, types.ErrorParentRoleDoesNotExist},
+	},
+	{
+		Method: "PUT", Path: "/roles", Function: "functions/role/update-roles", Operation: "updateRoles",
+		Summary: "Replace the access, inheritance and policy of a role",
+		Query:   []Param{{Name: "role", Description: "Name of the role.", Required: true}},
+		Body:    validation.RoleUpdate,
+		Responses: []Response{
+			{Status: 200, Description: "The updated role.", Body: types.Role{}},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorInvalidRole, types.ErrorRoleDoesNotExist,
+			types.ErrorRoleInheritanceCycle, types.ErrorParentRoleDoesNotExist},
+	},
+	{
+		Method: "DELETE", Path: "/roles", Function: "functions/role/delete-roles", Operation: "deleteRoles",
+		Summary: "Delete a role, moving its users to another role first when given",
+		Query: []Param{
+			{Name: "role", Description: "Name of the role.", Required: true},
+			{Name: "reassign_to", Description: "Role given to the users of the deleted role."},
+		},
+		Responses: []Response{
+			{Status: 200, Description: "The role was deleted.", Body: ""},
+		},
+		Errors: []*types.Error{types.ErrorMissingParameter, types.ErrorRoleDoesNotExist, types.ErrorRoleInUse,
+			types.ErrorInvalidReassignRole, types.ErrorRecordChanged},
+	},
+}
+
+var updateUserErrors = []*types.Error{types.ErrorMissingParameter, types.ErrorInvalidUserID, types.ErrorForbidden,
+	types.ErrorInvalidPolicy, types.ErrorNotPermittedByPolicy, types.ErrorUserDoesNotExist,
+	types.ErrorUserAlreadyDeleted, types.ErrorEmailAlreadyExists, types.ErrorUnknownRole, types.ErrorRecordChanged}
+
+var userCognitoErrors = []*types.Error{types.ErrorMissingParameter, types.ErrorForbidden, types.ErrorInvalidPolicy,
+	types.ErrorNotPermittedByPolicy, types.ErrorUserDoesNotExist}
@@ -0,0 +1,186 @@
+package api_test
+
+import (
+	"ascenda/api"
+	"ascenda/localserver"
+	"ascenda/types"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRoutesMatchTemplate(t *testing.T) {
+	routes, err := localserver.ReadRoutes("../template.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var declared []localserver.Route
+	operations := map[string]bool{}
+	for _, route := range api.Routes {
+		declared = append(declared, localserver.Route{Method: route.Method, Path: route.Path, CodeURI: route.Function})
+		if operations[route.Operation] {
+			t.Errorf("operation %s is declared twice", route.Operation)
+		}
+		operations[route.Operation] = true
+		if route.Body != nil && route.RawBody != "" {
+			t.Errorf("%s %s has both a json and a raw body", route.Method, route.Path)
+		}
+	}
+	if len(declared) != len(routes) {
+		t.Fatalf("api.Routes has %d routes, template.yaml %d", len(declared), len(routes))
+	}
+	for i := range routes {
+		if declared[i] != routes[i] {
+			t.Errorf("route %d = %+v, template.yaml has %+v", i, declared[i], routes[i])
+		}
+	}
+}
+
+// handlerSource is what the source of a handler package reads and returns.
+type handlerSource struct {
+	query map[string]bool
+	//errors are the names of the types.Error variables referenced
+	errors map[string]bool
+	//logs is whether the handler logs with the requester of the request
+	logs bool
+}
+
+func readHandler(t *testing.T, dir string) handlerSource {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := handlerSource{query: map[string]bool{}, errors: map[string]bool{}}
+	selfService := false
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.IndexExpr:
+				selector, ok := n.X.(*ast.SelectorExpr)
+				key, isString := n.Index.(*ast.BasicLit)
+				if ok && isString && key.Kind == token.STRING && selector.Sel.Name == "QueryStringParameters" {
+					value, _ := strconv.Unquote(key.Value)
+					source.query[value] = true
+				}
+			case *ast.SelectorExpr:
+				pkg, ok := n.X.(*ast.Ident)
+				if !ok {
+					break
+				}
+				switch {
+				case pkg.Name == "types" && (strings.HasPrefix(n.Sel.Name, "Error") || n.Sel.Name == "MissingParameter"):
+					source.errors[strings.TrimPrefix(n.Sel.Name, "Error")] = true
+				case pkg.Name == "utility" && strings.HasPrefix(n.Sel.Name, "Send") && strings.HasSuffix(n.Sel.Name, "Logs"):
+					source.logs = true
+				case pkg.Name == "utility" && n.Sel.Name == "Requester":
+					source.query["requester"] = true
+				case pkg.Name == "utility" && n.Sel.Name == "UserIDFromRequest":
+					selfService = true
+				}
+			}
+			return true
+		})
+	}
+	//self service handlers always have an authorizer user_id to log instead
+	source.logs = source.logs && !selfService
+	return source
+}
+
+// errorVariables maps the names of the types.Error variables, without their
+// Error prefix, to their status and code, read from types/error_message.go.
+func errorVariables(t *testing.T) map[string]types.Error {
+	file, err := parser.ParseFile(token.NewFileSet(), "../types/error_message.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	variables := map[string]types.Error{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || len(spec.Values) != 1 {
+			return true
+		}
+		call, ok := spec.Values[0].(*ast.CallExpr)
+		if !ok || len(call.Args) != 3 {
+			return true
+		}
+		status, ok := call.Args[0].(*ast.BasicLit)
+		code, isString := call.Args[1].(*ast.BasicLit)
+		if !ok || !isString {
+			return true
+		}
+		variable := types.Error{}
+		variable.Status, _ = strconv.Atoi(status.Value)
+		variable.Code, _ = strconv.Unquote(code.Value)
+		variables[strings.TrimPrefix(spec.Names[0].Name, "Error")] = variable
+		return true
+	})
+	return variables
+}
+
+// TestHandlersMatchRoutes checks the source of every handler against its
+// route, so a query parameter or client error added to a handler without its
+// route fails here.
+func TestHandlersMatchRoutes(t *testing.T) {
+	variables := errorVariables(t)
+	variables["MissingParameter"] = *types.ErrorMissingParameter
+
+	for _, route := range api.Routes {
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			if _, err := os.Stat(filepath.Join("..", route.Function, "handler.go")); err != nil {
+				t.Fatal(err)
+			}
+			source := readHandler(t, filepath.Join("..", route.Function))
+
+			declared := map[string]bool{}
+			for _, param := range route.Query {
+				declared[param.Name] = true
+			}
+			for name := range source.query {
+				if !declared[name] {
+					t.Errorf("handler reads query parameter %s the route does not declare", name)
+				}
+			}
+			if source.logs && !declared["requester"] {
+				t.Error("handler logs the requester the route does not declare")
+			}
+			for name := range declared {
+				if !source.query[name] && !(name == "requester" && source.logs) {
+					t.Errorf("route declares query parameter %s the handler does not read", name)
+				}
+			}
+
+			for name := range source.errors {
+				variable, ok := variables[name]
+				if !ok {
+					t.Fatalf("types.Error%s is not in types/error_message.go", name)
+				}
+				if variable.Status >= 500 || declaresError(route, variable) {
+					continue
+				}
+				t.Errorf("handler returns types.Error%s the route does not declare", name)
+			}
+		})
+	}
+}
+
+func declaresError(route api.Route, variable types.Error) bool {
+	for _, err := range route.Errors {
+		if err.Status == variable.Status && err.Code == variable.Code {
+			return true
+		}
+	}
+	return false
+}
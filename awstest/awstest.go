@@ -0,0 +1,104 @@
+// Package awstest fakes the Cognito, SES and SQS clients for tests running
+// handlers end to end, such as through the local server, where the calls they
+// make matter less than the items they store. Every call the handlers make
+// succeeds; other calls panic.
+package awstest
+
+import (
+	"ascenda/utility"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// Config names the development tables of dynamotest.Tables and sets every
+// other parameter, allowing admin chosen passwords.
+var Config = utility.StaticConfig{UserTable: "users", EmailsTable: "emails", PointsTable: "points",
+	MakerTable: "makers", RolesTable: "roles", LogsTable: "logs", SessionsTable: "sessions",
+	ImportJobsTable: "import-jobs", MigrationsTable: "migrations", DriftsTable: "drifts", TTL: "30",
+	UserPoolID: "pool", ReconciliationQueueURL: "reconciliation", ImportQueueURL: "imports", ImportRate: 10,
+	RetentionDays: 30, AllowAdminPasswords: true, SessionTTL: 30, SignInFailureThreshold: 5}
+
+// Deps returns the deps of handlers storing items in db, with fake Cognito,
+// SES and SQS clients and Config.
+func Deps(db dynamodbiface.DynamoDBAPI) *utility.Deps {
+	return &utility.Deps{Dynamo: db, Cognito: Cognito{}, SES: SES{}, SQS: SQS{}, Config: Config}
+}
+
+// Cognito accepts every admin action on users.
+type Cognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+func (Cognito) AdminCreateUser(*cognitoidentityprovider.AdminCreateUserInput) (*cognitoidentityprovider.AdminCreateUserOutput, error) {
+	return &cognitoidentityprovider.AdminCreateUserOutput{}, nil
+}
+
+func (Cognito) AdminDeleteUser(*cognitoidentityprovider.AdminDeleteUserInput) (*cognitoidentityprovider.AdminDeleteUserOutput, error) {
+	return &cognitoidentityprovider.AdminDeleteUserOutput{}, nil
+}
+
+func (Cognito) AdminDisableUser(*cognitoidentityprovider.AdminDisableUserInput) (*cognitoidentityprovider.AdminDisableUserOutput, error) {
+	return &cognitoidentityprovider.AdminDisableUserOutput{}, nil
+}
+
+func (Cognito) AdminEnableUser(*cognitoidentityprovider.AdminEnableUserInput) (*cognitoidentityprovider.AdminEnableUserOutput, error) {
+	return &cognitoidentityprovider.AdminEnableUserOutput{}, nil
+}
+
+func (Cognito) AdminUpdateUserAttributes(*cognitoidentityprovider.AdminUpdateUserAttributesInput) (
+	*cognitoidentityprovider.AdminUpdateUserAttributesOutput, error) {
+	return &cognitoidentityprovider.AdminUpdateUserAttributesOutput{}, nil
+}
+
+func (Cognito) AdminResetUserPassword(*cognitoidentityprovider.AdminResetUserPasswordInput) (
+	*cognitoidentityprovider.AdminResetUserPasswordOutput, error) {
+	return &cognitoidentityprovider.AdminResetUserPasswordOutput{}, nil
+}
+
+func (Cognito) AdminSetUserPassword(*cognitoidentityprovider.AdminSetUserPasswordInput) (
+	*cognitoidentityprovider.AdminSetUserPasswordOutput, error) {
+	return &cognitoidentityprovider.AdminSetUserPasswordOutput{}, nil
+}
+
+func (Cognito) AdminSetUserMFAPreference(*cognitoidentityprovider.AdminSetUserMFAPreferenceInput) (
+	*cognitoidentityprovider.AdminSetUserMFAPreferenceOutput, error) {
+	return &cognitoidentityprovider.AdminSetUserMFAPreferenceOutput{}, nil
+}
+
+func (Cognito) AdminUserGlobalSignOut(*cognitoidentityprovider.AdminUserGlobalSignOutInput) (
+	*cognitoidentityprovider.AdminUserGlobalSignOutOutput, error) {
+	return &cognitoidentityprovider.AdminUserGlobalSignOutOutput{}, nil
+}
+
+// SES sends nothing and knows no verified identities.
+type SES struct {
+	sesiface.SESAPI
+}
+
+func (SES) GetIdentityVerificationAttributes(*ses.GetIdentityVerificationAttributesInput) (
+	*ses.GetIdentityVerificationAttributesOutput, error) {
+	return &ses.GetIdentityVerificationAttributesOutput{}, nil
+}
+
+func (SES) SendEmail(*ses.SendEmailInput) (*ses.SendEmailOutput, error) {
+	return &ses.SendEmailOutput{}, nil
+}
+
+func (SES) VerifyEmailIdentity(*ses.VerifyEmailIdentityInput) (*ses.VerifyEmailIdentityOutput, error) {
+	return &ses.VerifyEmailIdentityOutput{}, nil
+}
+
+// SQS queues nothing.
+type SQS struct {
+	sqsiface.SQSAPI
+}
+
+func (SQS) SendMessage(*sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	return &sqs.SendMessageOutput{}, nil
+}
@@ -0,0 +1,284 @@
+// Package client calls the admin api over http, sending and returning the
+// structs of the types package. Requests are retried when throttled or when
+// the api fails, and failed requests return an *Error that matches the
+// declared types errors with errors.Is:
+//
+//	c := client.New("https://api.example.com/Prod", client.StaticToken(token))
+//	c.Requester = "Ada-Admin"
+//	user, err := c.GetUser(ctx, id, false)
+//	if errors.Is(err, types.ErrorUserDoesNotExist) {
+//		...
+//	}
+package client
+
+import (
+	"ascenda/types"
+	"ascenda/validation"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is how many times New lets a request be retried.
+	DefaultMaxRetries = 3
+	// DefaultBackoff is the wait New sets before the first retry, doubled for
+	// each retry after it.
+	DefaultBackoff = 200 * time.Millisecond
+	// maxBackoff caps the wait between retries, including Retry-After.
+	maxBackoff = 10 * time.Second
+)
+
+// TokenSource returns the token sent in the Authorization header of a
+// request, which the lambda authorizer verifies. It is called for every
+// attempt so refreshed tokens are picked up.
+type TokenSource func(ctx context.Context) (string, error)
+
+// StaticToken always sends token.
+func StaticToken(token string) TokenSource {
+	return func(ctx context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// Client calls the api at BaseURL, such as the stage url of the deployment or
+// http://localhost:3000 for the local server.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Token authenticates requests, none are sent without it.
+	Token TokenSource
+	// Requester is the first and last name of the caller joined by a hyphen,
+	// such as Ada-Admin, recorded in the logs of the changes it makes when
+	// the api has no authorizer to identify the caller by their user_id.
+	Requester string
+	// MaxRetries is how many times a failed request is sent again.
+	MaxRetries int
+	// Backoff is the wait before the first retry, doubled for each retry after
+	// it, with jitter.
+	Backoff time.Duration
+}
+
+func New(baseURL string, token TokenSource) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Token:      token,
+		MaxRetries: DefaultMaxRetries,
+		Backoff:    DefaultBackoff,
+	}
+}
+
+// Error is a request the api failed, with the code of the types error it
+// answered with. errors.Is matches it against the types errors of the same
+// code, such as types.ErrorUserDoesNotExist.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	// Details holds the json details of the error, such as the field errors
+	// of validation_failed.
+	Details   json.RawMessage
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// Is matches the types error of the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*types.Error)
+	return ok && e.Code != "" && t.Code == e.Code
+}
+
+// FieldErrors returns the problems a body failed validation with, or nil for
+// other errors.
+func (e *Error) FieldErrors() []validation.FieldError {
+	if e.Code != types.ErrorValidationFailed.Code {
+		return nil
+	}
+	var errs []validation.FieldError
+	json.Unmarshal(e.Details, &errs)
+	return errs
+}
+
+// request is a call to a route of the api.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	//body is marshalled as json unless it is a []byte sent as contentType
+	body        interface{}
+	contentType string
+	//requester adds Client.Requester to the query, for routes logging changes
+	requester bool
+}
+
+// call sends the request, retrying it as retryable allows, and unmarshals the
+// json response into out unless it is nil.
+func (c *Client) call(ctx context.Context, r request, out interface{}) error {
+	data, err := c.send(ctx, r)
+	if err != nil || out == nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("client: decoding %s %s: %w", r.method, r.path, err)
+	}
+	return nil
+}
+
+// send returns the body of the response to r.
+func (c *Client) send(ctx context.Context, r request) ([]byte, error) {
+	var body []byte
+	contentType := r.contentType
+	switch b := r.body.(type) {
+	case nil:
+	case []byte:
+		body = b
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		body, contentType = encoded, "application/json"
+	}
+
+	query := url.Values{}
+	for name, values := range r.query {
+		for _, value := range values {
+			if value != "" {
+				query.Add(name, value)
+			}
+		}
+	}
+	if r.requester && c.Requester != "" {
+		query.Set("requester", c.Requester)
+	}
+	target := c.BaseURL + r.path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		status, header, data, err := c.attempt(ctx, r.method, target, body, contentType)
+		if err == nil && status < 300 {
+			return data, nil
+		}
+		if ctx.Err() != nil || attempt >= c.MaxRetries || !retryable(r.method, status, err) {
+			if err != nil {
+				return nil, err
+			}
+			return nil, decodeError(status, data)
+		}
+		if err := sleep(ctx, c.wait(attempt, header)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, target string, body []byte, contentType string) (
+	int, http.Header, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != nil {
+		token, err := c.Token(ctx)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		req.Header.Set("Authorization", token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	return res.StatusCode, res.Header, data, err
+}
+
+// retryable reports whether a request failing with status or err may be sent
+// again. Throttled requests and 503s, where an aws call failed, are retried
+// for every method. Other server errors and broken connections leave the
+// effect of the request unknown, so only requests that can be repeated safely
+// are retried on them.
+func retryable(method string, status int, err error) bool {
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		return true
+	}
+	idempotent := method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+	return idempotent && (err != nil || status >= 500)
+}
+
+// wait returns how long to wait before the retry after attempt, honouring the
+// Retry-After seconds of the response.
+func (c *Client) wait(attempt int, header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, maxBackoff)
+	}
+	backoff := min(c.Backoff<<attempt, maxBackoff)
+	if backoff <= 0 {
+		return 0
+	}
+	//half the backoff plus jitter, so clients failing together spread out
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// flag is the query value of a boolean parameter, left out when false.
+func flag(b bool) string {
+	if b {
+		return "true"
+	}
+	return ""
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// decodeError reads the types.ErrorResponse of a failed request. Bodies that
+// are not one, such as from API Gateway itself, keep their message.
+func decodeError(status int, data []byte) error {
+	var body struct {
+		Code      string          `json:"code"`
+		Message   string          `json:"message"`
+		Details   json.RawMessage `json:"details"`
+		RequestID string          `json:"request_id"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || body.Message == "" {
+		body.Message = strings.TrimSpace(string(data))
+		if body.Message == "" {
+			body.Message = http.StatusText(status)
+		}
+	}
+	return &Error{Status: status, Code: body.Code, Message: body.Message, Details: body.Details,
+		RequestID: body.RequestID}
+}
@@ -0,0 +1,381 @@
+package client_test
+
+import (
+	"ascenda/api"
+	"ascenda/awstest"
+	"ascenda/client"
+	"ascenda/dynamotest"
+	"ascenda/localserver"
+	"ascenda/types"
+	"ascenda/utility"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	adminID  = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e01"
+	janeID   = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e02"
+	bobID    = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e03"
+	pointsID = "0b9e3c2a-5f4d-4e6b-8a7c-1d2e3f4a5b01"
+	reqID    = "1f2e3d4c-5b6a-4978-8a6b-5c4d3e2f1a01"
+	token    = "admin-token"
+)
+
+// newServer returns the local server over seeded tables, authorizing requests
+// carrying token as the admin, and extra users for listing across pages.
+func newServer(t *testing.T, extraUsers int) *localserver.Server {
+	db := dynamotest.New(dynamotest.Tables...)
+	now := time.Now()
+	db.Seed(t, "roles",
+		types.Role{Role: "admin", Access: map[string][]string{"*": {"*"}}},
+		types.Role{Role: "customer", Access: map[string][]string{"/me": {"GET", "PATCH"}}})
+	db.Seed(t, "users",
+		types.User{User_ID: adminID, Email: "admin@example.com", FirstName: "Ada", LastName: "Admin", Role: "admin"},
+		types.User{User_ID: janeID, Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+		types.User{User_ID: bobID, Email: "bob@example.com", FirstName: "Bob", LastName: "Lee", Role: "customer"})
+	db.Seed(t, "emails",
+		map[string]string{"email": "admin@example.com", "user_id": adminID},
+		map[string]string{"email": "jane@example.com", "user_id": janeID},
+		map[string]string{"email": "bob@example.com", "user_id": bobID})
+	var users []interface{}
+	for i := 0; i < extraUsers; i++ {
+		users = append(users, types.User{User_ID: fmt.Sprintf("extra-%03d", i),
+			Email: fmt.Sprintf("extra%d@example.com", i), FirstName: "Extra", LastName: "User", Role: "customer"})
+	}
+	db.Seed(t, "users", users...)
+	db.Seed(t, "points", types.UserPoint{User_ID: janeID, Points_ID: pointsID, Points: 10})
+	db.Seed(t, "makers", types.MakerRequest{RequestUUID: reqID, CheckerRole: "admin", MakerUUID: adminID,
+		RequestStatus: "pending", ResourceType: "points",
+		RequestData: json.RawMessage(`{"user_id":"` + janeID + `","points_id":"` + pointsID + `","points":15}`)})
+	db.Seed(t, "logs",
+		types.Log{Log_ID: "log-1", Description: "Ada Admin created user Jane Doe", Timestamp: now.Unix()},
+		types.Log{Log_ID: utility.ReconciliationReportID, Timestamp: now.Unix(),
+			Report: &types.DriftReport{GeneratedAt: now.Unix(), Drifts: map[string][]types.Drift{}}})
+	db.Seed(t, "sessions", types.SignInEvent{User_ID: janeID, Timestamp: now.Unix(), Outcome: "success"})
+	db.Seed(t, "import-jobs", types.ImportJob{Job_ID: "job-1", Status: "completed", Requester: "Ada-Admin",
+		Summary: map[string]int{"created": 1}, Rows: []types.ImportRow{{Line: 2, Email: "new@example.com"}}})
+
+	routes, err := localserver.ReadRoutes("../template.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := localserver.New(awstest.Deps(db), routes, localserver.Handlers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Authorizer = func(deps *utility.Deps, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (
+		events.APIGatewayCustomAuthorizerResponse, error) {
+		if request.Headers["Authorization"] != token {
+			return events.APIGatewayCustomAuthorizerResponse{}, errors.New("Unauthorized")
+		}
+		return events.APIGatewayCustomAuthorizerResponse{
+			PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+				Statement: []events.IAMPolicyStatement{{Effect: "Allow", Resource: []string{request.MethodArn}}},
+			},
+			Context: map[string]interface{}{utility.UserIDContextKey: adminID, utility.PolicyContextKey: "{}"},
+		}, nil
+	}
+	return server
+}
+
+// newClient returns a client of handler as Ada-Admin, retrying without
+// waiting.
+func newClient(t *testing.T, handler http.Handler) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c := client.New(server.URL, client.StaticToken(token))
+	c.Requester = "Ada-Admin"
+	c.Backoff = time.Millisecond
+	return c
+}
+
+// recorder records the method and path of the requests the handler
+// answered successfully.
+type recorder struct {
+	http.Handler
+	mu     sync.Mutex
+	called map[string]bool
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	res := httptest.NewRecorder()
+	r.Handler.ServeHTTP(res, req)
+	if res.Code < 300 {
+		r.mu.Lock()
+		r.called[req.Method+" "+req.URL.Path] = true
+		r.mu.Unlock()
+	}
+	for name, values := range res.Header() {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(res.Code)
+	w.Write(res.Body.Bytes())
+}
+
+// TestClientCallsEveryRoute calls every method of the client, in an order
+// where each finds the records it needs, and checks every function was called
+// through one of its routes.
+func TestClientCallsEveryRoute(t *testing.T) {
+	ctx := context.Background()
+	rec := &recorder{Handler: newServer(t, 0), called: map[string]bool{}}
+	c := newClient(t, rec)
+
+	steps := []struct {
+		name string
+		call func() error
+	}{
+		{"GetMakerRequest", func() error { _, err := c.GetMakerRequest(ctx, reqID); return err }},
+		{"MakerRequestsOf", func() error { _, err := c.MakerRequestsOf(ctx, adminID, "pending"); return err }},
+		{"ListMakerRequests", func() error { _, err := c.ListMakerRequests(ctx).All(); return err }},
+		{"CreateMakerRequest", func() error {
+			_, err := c.CreateMakerRequest(ctx, types.NewMakerRequest{CheckerRoles: []string{"admin"}, MakerUUID: adminID,
+				ResourceType: "points",
+				RequestData:  json.RawMessage(`{"user_id":"` + janeID + `","points_id":"` + pointsID + `","points":20}`)})
+			return err
+		}},
+		{"CheckerRequests", func() error { _, err := c.CheckerRequests(ctx, "admin", "pending"); return err }},
+		{"DecideMakerRequest", func() error {
+			_, err := c.DecideMakerRequest(ctx, types.DecisionBody{RequestId: reqID, CheckerRole: "admin",
+				CheckerId: adminID, Decision: "approve"})
+			return err
+		}},
+		{"GetPoints", func() error { _, err := c.GetPoints(ctx, janeID); return err }},
+		{"ListPoints", func() error { _, err := c.ListPoints(ctx).All(); return err }},
+		{"UpdatePoints", func() error {
+			_, err := c.UpdatePoints(ctx, types.UserPoint{User_ID: janeID, Points_ID: pointsID, Points: 30})
+			return err
+		}},
+		{"UpdatePointsFrom", func() error {
+			_, err := c.UpdatePointsFrom(ctx, types.UserPoint{User_ID: janeID, Points_ID: pointsID, Points: 30}, 30)
+			return err
+		}},
+		{"CreatePoints", func() error { _, err := c.CreatePoints(ctx, adminID); return err }},
+		{"GetUser", func() error { _, err := c.GetUser(ctx, janeID, false); return err }},
+		{"ListUsers", func() error { _, err := c.ListUsers(ctx, client.ListUsersOptions{}).All(); return err }},
+		{"CreateUser", func() error {
+			_, err := c.CreateUser(ctx, types.User{Email: "new@example.com", FirstName: "New", LastName: "User",
+				Role: "customer"}, "")
+			return err
+		}},
+		{"UpdateUser", func() error {
+			_, err := c.UpdateUser(ctx, janeID, types.UserPatch{FirstName: aws.String("Janet")})
+			return err
+		}},
+		{"DisableUser", func() error { return c.DisableUser(ctx, bobID) }},
+		{"RestoreUser", func() error { return c.RestoreUser(ctx, bobID) }},
+		{"DeleteUser", func() error { return c.DeleteUser(ctx, bobID) }},
+		{"HardDeleteUser", func() error { return c.HardDeleteUser(ctx, bobID) }},
+		{"ResetPassword", func() error { return c.ResetPassword(ctx, janeID) }},
+		{"SetTemporaryPassword", func() error { return c.SetTemporaryPassword(ctx, janeID, "Temp-Passw0rd!") }},
+		{"UpdateMFA", func() error {
+			return c.UpdateMFA(ctx, janeID, types.MFAPreference{SMS: aws.Bool(true), Preferred: "SMS"})
+		}},
+		{"SignOutUser", func() error { return c.SignOutUser(ctx, janeID) }},
+		{"ListSessions", func() error { _, err := c.ListSessions(ctx, janeID, false).All(); return err }},
+		{"ImportUsers", func() error {
+			_, err := c.ImportUsers(ctx, []byte("email,first_name,last_name,role\nimp@example.com,Imp,Orter,customer\n"))
+			return err
+		}},
+		{"GetImport", func() error { _, err := c.GetImport(ctx, "job-1"); return err }},
+		{"GetImportCSV", func() error { _, err := c.GetImportCSV(ctx, "job-1"); return err }},
+		{"GetProfile", func() error { _, err := c.GetProfile(ctx); return err }},
+		{"UpdateProfile", func() error {
+			_, err := c.UpdateProfile(ctx, types.ProfilePatch{FirstName: aws.String("Ada")})
+			return err
+		}},
+		{"GetProfilePoints", func() error { _, err := c.GetProfilePoints(ctx); return err }},
+		{"GetLog", func() error { _, err := c.GetLog(ctx, "log-1"); return err }},
+		{"ListLogs", func() error { _, err := c.ListLogs(ctx).All(); return err }},
+		{"GetReconciliation", func() error { _, err := c.GetReconciliation(ctx); return err }},
+		{"OpenAPI", func() error { _, err := c.OpenAPI(ctx); return err }},
+		{"GetRole", func() error { _, err := c.GetRole(ctx, "customer", true); return err }},
+		{"ListRoles", func() error { _, err := c.ListRoles(ctx).All(); return err }},
+		{"CreateRole", func() error {
+			_, err := c.CreateRole(ctx, types.Role{Role: "auditor", Access: map[string][]string{"/logs": {"GET"}}})
+			return err
+		}},
+		{"UpdateRole", func() error {
+			_, err := c.UpdateRole(ctx, types.Role{Role: "auditor", Inherits: []string{"customer"},
+				Access: map[string][]string{"/logs": {"GET"}}})
+			return err
+		}},
+		{"DeleteRole", func() error { return c.DeleteRole(ctx, "auditor", "") }},
+	}
+	for _, step := range steps {
+		if err := step.call(); err != nil {
+			t.Errorf("%s: %v", step.name, err)
+		}
+	}
+
+	functions := map[string]bool{}
+	for _, r := range api.Routes {
+		if rec.called[r.Method+" "+r.Path] {
+			functions[r.Function] = true
+		}
+	}
+	for _, r := range api.Routes {
+		if !functions[r.Function] {
+			t.Errorf("no client method calls %s %s", r.Method, r.Path)
+		}
+	}
+}
+
+func TestListUsersPages(t *testing.T) {
+	c := newClient(t, newServer(t, 250))
+
+	users, err := c.ListUsers(context.Background(), client.ListUsersOptions{}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, user := range users {
+		seen[user.User_ID] = true
+	}
+	if len(users) != 253 || len(seen) != 253 {
+		t.Errorf("listed %d users, %d distinct, want 253", len(users), len(seen))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, newServer(t, 0))
+
+	tests := []struct {
+		name       string
+		call       func() error
+		wantErr    *types.Error
+		wantFields bool
+	}{
+		{"missing user", func() error { _, err := c.GetUser(ctx, "nope", false); return err },
+			types.ErrorUserDoesNotExist, false},
+		{"taken email", func() error {
+			_, err := c.CreateUser(ctx, types.User{Email: "jane@example.com", FirstName: "Jane", LastName: "Doe",
+				Role: "customer"}, "")
+			return err
+		}, types.ErrorEmailAlreadyExists, false},
+		{"invalid body", func() error { _, err := c.CreateUser(ctx, types.User{}, ""); return err },
+			types.ErrorValidationFailed, true},
+		{"role in use", func() error { return c.DeleteRole(ctx, "customer", "") }, types.ErrorRoleInUse, false},
+		{"changed points", func() error {
+			_, err := c.UpdatePointsFrom(ctx, types.UserPoint{User_ID: janeID, Points_ID: pointsID, Points: 30}, 5)
+			return err
+		}, types.ErrorPointsChanged, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) || apiErr.Status != tt.wantErr.Status || apiErr.RequestID == "" {
+				t.Fatalf("err = %#v, want a client error with status %d and a request id", err, tt.wantErr.Status)
+			}
+			if got := len(apiErr.FieldErrors()) > 0; got != tt.wantFields {
+				t.Errorf("field errors = %v, want some %v", apiErr.FieldErrors(), tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	server := newServer(t, 0)
+	tests := []struct {
+		name    string
+		token   client.TokenSource
+		wantErr *types.Error
+	}{
+		{"valid", client.StaticToken(token), nil},
+		{"wrong", client.StaticToken("other"), types.ErrorNotAuthenticated},
+		{"none", nil, types.ErrorNotAuthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(t, server)
+			c.Token = tt.token
+			_, err := c.GetProfile(context.Background())
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// flaky fails the first failures requests with status before passing them
+// to the handler.
+type flaky struct {
+	http.Handler
+	status   int
+	failures int
+	mu       sync.Mutex
+	calls    int
+}
+
+func (f *flaky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.calls++
+	fail := f.calls <= f.failures
+	f.mu.Unlock()
+	if !fail {
+		f.Handler.ServeHTTP(w, r)
+		return
+	}
+	if f.status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "0")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(f.status)
+	json.NewEncoder(w).Encode(types.ErrorResponse{Code: "unavailable", Message: http.StatusText(f.status)})
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+	getUser := func(c *client.Client) error { _, err := c.GetUser(ctx, janeID, false); return err }
+	createUser := func(c *client.Client) error {
+		_, err := c.CreateUser(ctx, types.User{Email: "new@example.com", FirstName: "New", LastName: "User",
+			Role: "customer"}, "")
+		return err
+	}
+
+	tests := []struct {
+		name       string
+		status     int
+		failures   int
+		call       func(c *client.Client) error
+		wantStatus int
+		wantCalls  int
+	}{
+		{"get retried on 500", http.StatusInternalServerError, 2, getUser, 0, 3},
+		{"throttled post retried", http.StatusTooManyRequests, 1, createUser, 0, 2},
+		{"unavailable post retried", http.StatusServiceUnavailable, 1, createUser, 0, 2},
+		{"post not retried on 500", http.StatusInternalServerError, 1, createUser, http.StatusInternalServerError, 1},
+		{"retries run out", http.StatusServiceUnavailable, 10, getUser, http.StatusServiceUnavailable,
+			client.DefaultMaxRetries + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &flaky{Handler: newServer(t, 0), status: tt.status, failures: tt.failures}
+			err := tt.call(newClient(t, f))
+			var apiErr *client.Error
+			switch {
+			case tt.wantStatus == 0 && err != nil:
+				t.Errorf("err = %v, want nil", err)
+			case tt.wantStatus != 0 && (!errors.As(err, &apiErr) || apiErr.Status != tt.wantStatus):
+				t.Errorf("err = %v, want status %d", err, tt.wantStatus)
+			}
+			if f.calls != tt.wantCalls {
+				t.Errorf("sent %d times, want %d", f.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import "context"
+
+// Iterator walks a list the api returns in pages, fetching the next page when
+// the current one is used up:
+//
+//	it := c.ListUsers(ctx, client.ListUsersOptions{})
+//	for it.Next() {
+//		user := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	ctx context.Context
+	//fetch returns the page after the one it returned last, and whether
+	//there are more
+	fetch func(ctx context.Context) (items []T, more bool, err error)
+
+	page  []T
+	value T
+	more  bool
+	err   error
+}
+
+func newIterator[T any](ctx context.Context, fetch func(ctx context.Context) ([]T, bool, error)) *Iterator[T] {
+	return &Iterator[T]{ctx: ctx, fetch: fetch, more: true}
+}
+
+// Next advances to the next item, returning false at the end of the list or
+// when a page could not be fetched.
+func (it *Iterator[T]) Next() bool {
+	//pages may be empty but still have more after them
+	for len(it.page) == 0 {
+		if !it.more || it.err != nil {
+			return false
+		}
+		it.page, it.more, it.err = it.fetch(it.ctx)
+	}
+	it.value, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Value is the item Next advanced to.
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err is the error that stopped Next, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All collects the remaining items.
+func (it *Iterator[T]) All() ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	return items, it.Err()
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"ascenda/types"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// GetLog returns the log.
+func (c *Client) GetLog(ctx context.Context, id string) (*types.Log, error) {
+	log := new(types.Log)
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/logs", query: url.Values{"id": {id}}}, log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// ListLogs lists every log.
+func (c *Client) ListLogs(ctx context.Context) *Iterator[types.Log] {
+	key := ""
+	return newIterator(ctx, func(ctx context.Context) ([]types.Log, bool, error) {
+		var page types.ReturnLogData
+		err := c.call(ctx, request{method: http.MethodGet, path: "/logs", query: url.Values{"key": {key}}}, &page)
+		key = page.Key
+		return page.Data, key != "", err
+	})
+}
+
+// GetReconciliation returns the latest report of the drift between the users
+// table and the user pool.
+func (c *Client) GetReconciliation(ctx context.Context) (*types.DriftReport, error) {
+	report := new(types.DriftReport)
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/reconciliation"}, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// OpenAPI returns the OpenAPI document of the api.
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	return c.send(ctx, request{method: http.MethodGet, path: "/openapi.json"})
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"ascenda/types"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetMakerRequest returns the maker request, with one entry per checker role
+// merged.
+func (c *Client) GetMakerRequest(ctx context.Context, reqID string) ([]types.ReturnMakerRequest, error) {
+	var requests []types.ReturnMakerRequest
+	err := c.call(ctx, request{method: http.MethodGet, path: "/makers", query: url.Values{"req_id": {reqID}}}, &requests)
+	return requests, err
+}
+
+// ListMakerRequests lists every maker request.
+func (c *Client) ListMakerRequests(ctx context.Context) *Iterator[types.ReturnMakerRequest] {
+	keyReq, keyRole := "", ""
+	return newIterator(ctx, func(ctx context.Context) ([]types.ReturnMakerRequest, bool, error) {
+		var page types.ReturnMakerData
+		err := c.call(ctx, request{method: http.MethodGet, path: "/makers",
+			query: url.Values{"keyReq": {keyReq}, "keyRole": {keyRole}}}, &page)
+		keyReq, keyRole = page.KeyReq, page.KeyRole
+		return page.Data, keyReq != "", err
+	})
+}
+
+// MakerRequestsOf returns the requests the maker made with the status,
+// pending, approved or rejected.
+func (c *Client) MakerRequestsOf(ctx context.Context, makerID, status string) ([]types.ReturnMakerRequest, error) {
+	var requests []types.ReturnMakerRequest
+	err := c.call(ctx, request{method: http.MethodGet, path: "/makers",
+		query: url.Values{"maker_id": {makerID}, "status": {status}}}, &requests)
+	return requests, err
+}
+
+// CheckerRequests returns the requests awaiting the checker role with the
+// status.
+func (c *Client) CheckerRequests(ctx context.Context, role, status string) ([]types.MakerRequest, error) {
+	var requests []types.MakerRequest
+	err := c.call(ctx, request{method: http.MethodGet, path: "/checkers",
+		query: url.Values{"role": {role}, "status": {status}}}, &requests)
+	return requests, err
+}
+
+// CreateMakerRequest requests a change for checkers of the request's roles to
+// approve.
+func (c *Client) CreateMakerRequest(ctx context.Context, maker types.NewMakerRequest) ([]types.ReturnMakerRequest, error) {
+	var requests []types.ReturnMakerRequest
+	err := c.call(ctx, request{method: http.MethodPost, path: "/makers", body: maker}, &requests)
+	return requests, err
+}
+
+// DecideMakerRequest approves or rejects a maker request, applying the change
+// when approved.
+func (c *Client) DecideMakerRequest(ctx context.Context, decision types.DecisionBody) ([]types.ReturnMakerRequest, error) {
+	var requests []types.ReturnMakerRequest
+	err := c.call(ctx, request{method: http.MethodPut, path: "/checkers", body: decision}, &requests)
+	return requests, err
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"ascenda/types"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetPoints returns the points accounts of the user.
+func (c *Client) GetPoints(ctx context.Context, userID string) ([]types.UserPoint, error) {
+	var accounts []types.UserPoint
+	err := c.call(ctx, request{method: http.MethodGet, path: "/points", query: url.Values{"id": {userID}}}, &accounts)
+	return accounts, err
+}
+
+// ListPoints lists every points account.
+func (c *Client) ListPoints(ctx context.Context) *Iterator[types.UserPoint] {
+	keyUser, keyPoint := "", ""
+	return newIterator(ctx, func(ctx context.Context) ([]types.UserPoint, bool, error) {
+		var page types.ReturnUserPointData
+		err := c.call(ctx, request{method: http.MethodGet, path: "/points",
+			query: url.Values{"keyUser": {keyUser}, "keyPoint": {keyPoint}}}, &page)
+		keyUser, keyPoint = page.KeyUser, page.KeyPoint
+		return page.Data, keyUser != "", err
+	})
+}
+
+// CreatePoints opens a points account for the user with a balance of 0.
+func (c *Client) CreatePoints(ctx context.Context, userID string) (*types.UserPoint, error) {
+	account := new(types.UserPoint)
+	err := c.call(ctx, request{method: http.MethodPost, path: "/points",
+		body: map[string]string{"user_id": userID}}, account)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// pointsUpdate is the body of update-points.
+type pointsUpdate struct {
+	Points_ID      string `json:"points_id"`
+	Points         int    `json:"points"`
+	ExpectedPoints *int   `json:"expected_points,omitempty"`
+}
+
+// UpdatePoints sets the balance of the points account of account.User_ID and
+// account.Points_ID to account.Points.
+func (c *Client) UpdatePoints(ctx context.Context, account types.UserPoint) (*types.UserPoint, error) {
+	return c.updatePoints(ctx, account, nil)
+}
+
+// UpdatePointsFrom is UpdatePoints for an account that holds expected points,
+// failing with types.ErrorPointsChanged when it no longer does.
+func (c *Client) UpdatePointsFrom(ctx context.Context, account types.UserPoint, expected int) (*types.UserPoint, error) {
+	return c.updatePoints(ctx, account, &expected)
+}
+
+func (c *Client) updatePoints(ctx context.Context, account types.UserPoint, expected *int) (*types.UserPoint, error) {
+	updated := new(types.UserPoint)
+	err := c.call(ctx, request{method: http.MethodPut, path: "/points", query: url.Values{"id": {account.User_ID}},
+		body:      pointsUpdate{Points_ID: account.Points_ID, Points: account.Points, ExpectedPoints: expected},
+		requester: true}, updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"ascenda/types"
+	"context"
+	"net/http"
+)
+
+// GetProfile returns the caller's own user.
+func (c *Client) GetProfile(ctx context.Context) (*types.User, error) {
+	user := new(types.User)
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/me"}, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateProfile changes the caller's own names set in patch.
+func (c *Client) UpdateProfile(ctx context.Context, patch types.ProfilePatch) (*types.User, error) {
+	user := new(types.User)
+	if err := c.call(ctx, request{method: http.MethodPatch, path: "/me", body: patch}, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetProfilePoints returns the caller's own points accounts.
+func (c *Client) GetProfilePoints(ctx context.Context) ([]types.UserPoint, error) {
+	var accounts []types.UserPoint
+	err := c.call(ctx, request{method: http.MethodGet, path: "/me/points"}, &accounts)
+	return accounts, err
+}
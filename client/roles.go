@@ -0,0 +1,61 @@
+package client
+
+import (
+	"ascenda/types"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetRole returns the role as stored, or with the access and policy it
+// inherits merged in if effective.
+func (c *Client) GetRole(ctx context.Context, role string, effective bool) (*types.Role, error) {
+	got := new(types.Role)
+	err := c.call(ctx, request{method: http.MethodGet, path: "/roles",
+		query: url.Values{"role": {role}, "effective": {flag(effective)}}}, got)
+	if err != nil {
+		return nil, err
+	}
+	return got, nil
+}
+
+// ListRoles lists every role.
+func (c *Client) ListRoles(ctx context.Context) *Iterator[types.Role] {
+	key := ""
+	return newIterator(ctx, func(ctx context.Context) ([]types.Role, bool, error) {
+		var page types.ReturnRoleData
+		err := c.call(ctx, request{method: http.MethodGet, path: "/roles", query: url.Values{"key": {key}}}, &page)
+		key = page.Key
+		return page.Data, key != "", err
+	})
+}
+
+// CreateRole creates or replaces the role.
+func (c *Client) CreateRole(ctx context.Context, role types.Role) (*types.Role, error) {
+	created := new(types.Role)
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/roles", body: role}, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateRole replaces the access, inheritance and policy of the existing role
+// named role.Role.
+func (c *Client) UpdateRole(ctx context.Context, role types.Role) (*types.Role, error) {
+	updated := new(types.Role)
+	err := c.call(ctx, request{method: http.MethodPut, path: "/roles", query: url.Values{"role": {role.Role}},
+		body: role}, updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteRole deletes the role, first moving its users to reassignTo unless it
+// is empty. Roles still assigned or inherited from fail with ErrorRoleInUse,
+// whose details list the users and roles as a types.RoleInUseData.
+func (c *Client) DeleteRole(ctx context.Context, role, reassignTo string) error {
+	_, err := c.send(ctx, request{method: http.MethodDelete, path: "/roles",
+		query: url.Values{"role": {role}, "reassign_to": {reassignTo}}})
+	return err
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"ascenda/types"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetUser returns the user, or ErrorUserDoesNotExist for deleted users unless
+// includeDeleted.
+func (c *Client) GetUser(ctx context.Context, id string, includeDeleted bool) (*types.User, error) {
+	user := new(types.User)
+	err := c.call(ctx, request{method: http.MethodGet, path: "/users",
+		query: url.Values{"id": {id}, "include_deleted": {flag(includeDeleted)}}}, user)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListUsersOptions narrows ListUsers.
+type ListUsersOptions struct {
+	// Role only lists the users of the role.
+	Role string
+	// IncludeDeleted lists soft deleted users too.
+	IncludeDeleted bool
+}
+
+// ListUsers lists the users the caller's policy lets them see.
+func (c *Client) ListUsers(ctx context.Context, options ListUsersOptions) *Iterator[types.User] {
+	key := ""
+	return newIterator(ctx, func(ctx context.Context) ([]types.User, bool, error) {
+		var page types.ReturnUserData
+		err := c.call(ctx, request{method: http.MethodGet, path: "/users", query: url.Values{"role": {options.Role},
+			"include_deleted": {flag(options.IncludeDeleted)}, "key": {key}}}, &page)
+		key = page.Key
+		return page.Data, key != "", err
+	})
+}
+
+// newUser is the body of create-users.
+type newUser struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Role      string `json:"role"`
+	Password  string `json:"password,omitempty"`
+}
+
+// CreateUser creates the user with the email, names and role of user, in the
+// user pool and the users table. Without a password Cognito emails the user a
+// temporary one.
+func (c *Client) CreateUser(ctx context.Context, user types.User, password string) (*types.User, error) {
+	created := new(types.User)
+	body := newUser{Email: user.Email, FirstName: user.FirstName, LastName: user.LastName, Role: user.Role,
+		Password: password}
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/users", body: body, requester: true}, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateUser changes the fields of the user set in patch. It is sent as a PUT,
+// which the api treats as a PATCH, so it can be retried.
+func (c *Client) UpdateUser(ctx context.Context, id string, patch types.UserPatch) (*types.User, error) {
+	user := new(types.User)
+	err := c.call(ctx, request{method: http.MethodPut, path: "/users", query: url.Values{"id": {id}}, body: patch,
+		requester: true}, user)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser soft deletes the user, who can be restored until they are
+// purged.
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.userAction(ctx, http.MethodDelete, "/users", id, nil)
+}
+
+// HardDeleteUser removes the user and their points accounts at once. It cannot
+// be undone.
+func (c *Client) HardDeleteUser(ctx context.Context, id string) error {
+	_, err := c.send(ctx, request{method: http.MethodDelete, path: "/users", query: url.Values{"id": {id}, "hard": {"true"}},
+		requester: true})
+	return err
+}
+
+// DisableUser stops the user signing in until they are restored.
+func (c *Client) DisableUser(ctx context.Context, id string) error {
+	return c.userAction(ctx, http.MethodPut, "/users/disable", id, nil)
+}
+
+// RestoreUser restores a disabled or deleted user.
+func (c *Client) RestoreUser(ctx context.Context, id string) error {
+	return c.userAction(ctx, http.MethodPut, "/users/restore", id, nil)
+}
+
+// ResetPassword sends the user a code to reset their password.
+func (c *Client) ResetPassword(ctx context.Context, id string) error {
+	return c.userAction(ctx, http.MethodPost, "/users/password/reset", id, nil)
+}
+
+// SetTemporaryPassword sets a password the user must change at their next
+// sign in.
+func (c *Client) SetTemporaryPassword(ctx context.Context, id, password string) error {
+	return c.userAction(ctx, http.MethodPost, "/users/password/temporary", id, types.TemporaryPassword{Password: password})
+}
+
+// UpdateMFA changes the mfa factors of the user set in preference.
+func (c *Client) UpdateMFA(ctx context.Context, id string, preference types.MFAPreference) error {
+	return c.userAction(ctx, http.MethodPut, "/users/mfa", id, preference)
+}
+
+// SignOutUser signs the user out of every device.
+func (c *Client) SignOutUser(ctx context.Context, id string) error {
+	return c.userAction(ctx, http.MethodPost, "/users/signout", id, nil)
+}
+
+// userAction calls a route acting on the user that answers in plain text.
+func (c *Client) userAction(ctx context.Context, method, path, id string, body interface{}) error {
+	_, err := c.send(ctx, request{method: method, path: path, query: url.Values{"id": {id}}, body: body, requester: true})
+	return err
+}
+
+// ListSessions lists the sign ins of the user, newest first, only the flagged
+// ones if flagged.
+func (c *Client) ListSessions(ctx context.Context, id string, flagged bool) *Iterator[types.SignInEvent] {
+	key := ""
+	return newIterator(ctx, func(ctx context.Context) ([]types.SignInEvent, bool, error) {
+		var page types.ReturnSignInData
+		err := c.call(ctx, request{method: http.MethodGet, path: "/users/sessions",
+			query: url.Values{"id": {id}, "flagged": {flag(flagged)}, "key": {key}}}, &page)
+		key = page.Key
+		return page.Data, key != "", err
+	})
+}
+
+// ImportUsers uploads a csv of users with email, first_name, last_name and
+// role columns, returning the queued job.
+func (c *Client) ImportUsers(ctx context.Context, csv []byte) (*types.ImportJob, error) {
+	job := new(types.ImportJob)
+	err := c.call(ctx, request{method: http.MethodPost, path: "/users/import", body: csv, contentType: "text/csv",
+		requester: true}, job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetImport returns the import job with the outcome of each row.
+func (c *Client) GetImport(ctx context.Context, id string) (*types.ImportJob, error) {
+	job := new(types.ImportJob)
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/users/import", query: url.Values{"id": {id}}}, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetImportCSV returns the outcome of each row of the import job as a csv.
+func (c *Client) GetImportCSV(ctx context.Context, id string) ([]byte, error) {
+	return c.send(ctx, request{method: http.MethodGet, path: "/users/import",
+		query: url.Values{"id": {id}, "format": {"csv"}}})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profile is where and as whom the api is called.
+type profile struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+	// Requester is the first and last name of the caller joined by a hyphen,
+	// recorded in the logs of the changes made.
+	Requester string `json:"requester"`
+}
+
+// loadProfile reads the named profile of the yaml file at path, a mapping of
+// profile names to their url, token and requester. ASCENDA_URL, ASCENDA_TOKEN
+// and ASCENDA_REQUESTER override the fields of the profile, and without the
+// file are enough by themselves.
+func loadProfile(path, name string) (profile, error) {
+	var p profile
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return p, err
+	default:
+		var profiles map[string]profile
+		if err := decodeYAML(data, &profiles); err != nil {
+			return p, fmt.Errorf("%s: %w", path, err)
+		}
+		found, ok := profiles[name]
+		if !ok && os.Getenv("ASCENDA_URL") == "" {
+			return p, fmt.Errorf("%s has no profile %q", path, name)
+		}
+		p = found
+	}
+
+	p.URL = envOr("ASCENDA_URL", p.URL)
+	p.Token = envOr("ASCENDA_TOKEN", p.Token)
+	p.Requester = envOr("ASCENDA_REQUESTER", p.Requester)
+	if p.URL == "" {
+		return p, fmt.Errorf("no url for profile %q, set it in %s or ASCENDA_URL", name, path)
+	}
+	return p, nil
+}
+
+// decodeYAML unmarshals the yaml data into out through its json form, so out
+// uses json tags. Unknown fields are refused, to catch misspelt ones.
+func decodeYAML(data []byte, out interface{}) error {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"ascenda/types"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+func tailLogs(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("logs tail")
+	count := fs.Int("n", 20, "number of the latest logs to print")
+	follow := fs.Bool("follow", false, "keep printing new logs until interrupted")
+	interval := fs.Duration("interval", 5*time.Second, "how often to check for new logs with -follow")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	logs, err := a.logs(ctx)
+	if err != nil {
+		return err
+	}
+	latest := logs[max(len(logs)-*count, 0):]
+	if err := a.print(latest, logsTable(latest)); err != nil || !*follow {
+		return err
+	}
+
+	//the logs are listed by a scan, so new ones are found by their ids
+	seen := map[string]bool{}
+	for _, log := range logs {
+		seen[log.Log_ID] = true
+	}
+	for {
+		if err := sleep(ctx, *interval); err != nil {
+			return nil
+		}
+		logs, err := a.logs(ctx)
+		if err != nil {
+			return err
+		}
+		var added []types.Log
+		for _, log := range logs {
+			if !seen[log.Log_ID] {
+				seen[log.Log_ID] = true
+				added = append(added, log)
+			}
+		}
+		if len(added) > 0 {
+			t := logsTable(added)
+			//the header was printed with the first logs
+			t.header = nil
+			if err := a.print(added, t); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func exportLogs(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("logs export")
+	since := fs.String("since", "", "only export logs from this time, as 2006-01-02 or RFC 3339")
+	until := fs.String("until", "", "only export logs before this time, as 2006-01-02 or RFC 3339")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	from, err := parseTime(*since)
+	if err != nil {
+		return fmt.Errorf("-since: %w", err)
+	}
+	to, err := parseTime(*until)
+	if err != nil {
+		return fmt.Errorf("-until: %w", err)
+	}
+
+	logs, err := a.logs(ctx)
+	if err != nil {
+		return err
+	}
+	var exported []types.Log
+	for _, log := range logs {
+		at := time.Unix(log.Timestamp, 0)
+		if (from.IsZero() || !at.Before(from)) && (to.IsZero() || at.Before(to)) {
+			exported = append(exported, log)
+		}
+	}
+	if a.format == "" {
+		a.format = "csv"
+	}
+	return a.print(exported, logsTable(exported))
+}
+
+// logs returns the change logs oldest first, without the reconciliation
+// report stored among them.
+func (a *app) logs(ctx context.Context) ([]types.Log, error) {
+	all, err := a.client.ListLogs(ctx).All()
+	if err != nil {
+		return nil, err
+	}
+	var logs []types.Log
+	for _, log := range all {
+		if log.Report == nil {
+			logs = append(logs, log)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].Timestamp < logs[j].Timestamp })
+	return logs, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, value, time.Local)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func logsTable(logs []types.Log) table {
+	t := table{header: []string{"TIME", "LOG_ID", "DESCRIPTION", "IP", "USER_AGENT"}}
+	for _, log := range logs {
+		t.add(time.Unix(log.Timestamp, 0).Format(time.RFC3339), log.Log_ID, log.Description, log.IP, log.UserAgent)
+	}
+	return t
+}
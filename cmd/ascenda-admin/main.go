@@ -0,0 +1,181 @@
+// Command ascenda-admin runs the routine operations of the admin api from a
+// terminal, through the client package, with the credentials of a profile:
+//
+//	ascenda-admin [-profile <name>] [-o table|json|csv] [-dry-run] <resource> <command> [flags] [args]
+//
+//	users   list [-role <role>] [-include-deleted] | get <id> | disable <id>
+//	        create -email <email> -first-name <name> -last-name <name> -role <role> [-password <password>]
+//	points  get <user_id> | adjust <user_id> -by <n> [-account <points_id>]
+//	        transfer <from user_id> <to user_id> -amount <n> [-from-account <points_id>] [-to-account <points_id>]
+//	makers  list [-maker <user_id> | -role <role>] [-status <status>] | approve <req_id> | reject <req_id> [-role <role>]
+//	roles   list | diff -f <file> | apply -f <file>
+//	logs    tail [-n <count>] [-follow] [-interval <duration>] | export [-since <time>] [-until <time>]
+//
+// Every mutation prints what it would do instead of doing it with -dry-run.
+package main
+
+import (
+	"ascenda/client"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// errUsage is returned for commands that are called wrongly, after printing
+// their usage.
+var errUsage = errors.New("usage")
+
+// app is the state shared by the commands of a run.
+type app struct {
+	client *client.Client
+	out    io.Writer
+	errOut io.Writer
+	format string
+	dryRun bool
+}
+
+// command runs a subcommand with the arguments after its name.
+type command func(ctx context.Context, a *app, args []string) error
+
+// commands are the subcommands of each resource.
+var commands = map[string]map[string]command{
+	"users":  {"list": listUsers, "get": getUser, "create": createUser, "disable": disableUser},
+	"points": {"get": getPoints, "adjust": adjustPoints, "transfer": transferPoints},
+	"makers": {"list": listMakers, "approve": approveMaker, "reject": rejectMaker},
+	"roles":  {"list": listRoles, "diff": diffRoles, "apply": applyRoles},
+	"logs":   {"tail": tailLogs, "export": exportLogs},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command of args, returning the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	a := &app{out: stdout, errOut: stderr}
+	global := a.flags("ascenda-admin")
+	configPath := global.String("config", defaultConfigPath(), "profiles file")
+	profileName := global.String("profile", envOr("ASCENDA_PROFILE", "default"), "profile to call the api with")
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+
+	args = global.Args()
+	if len(args) < 2 || commands[args[0]][args[1]] == nil {
+		fmt.Fprintln(stderr, "usage: ascenda-admin [flags] <resource> <command> [flags] [args]")
+		for _, resource := range sortedKeys(commands) {
+			fmt.Fprintf(stderr, "  %s %s\n", resource, strings.Join(sortedKeys(commands[resource]), "|"))
+		}
+		return 2
+	}
+	switch a.format {
+	case "", "table", "json", "csv":
+	default:
+		fmt.Fprintf(stderr, "ascenda-admin: unknown output %q, want table, json or csv\n", a.format)
+		return 2
+	}
+
+	profile, err := loadProfile(*configPath, *profileName)
+	if err != nil {
+		fmt.Fprintln(stderr, "ascenda-admin:", err)
+		return 1
+	}
+	a.client = client.New(profile.URL, client.StaticToken(profile.Token))
+	a.client.Requester = profile.Requester
+
+	if err := commands[args[0]][args[1]](ctx, a, args[2:]); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintln(stderr, "ascenda-admin:", err)
+		return 1
+	}
+	return 0
+}
+
+// flags returns a flag set with the output and dry run flags, which every
+// command accepts as well as the top level.
+func (a *app) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.errOut)
+	fs.StringVar(&a.format, "o", a.format, "output format: table, json or csv")
+	fs.BoolVar(&a.dryRun, "dry-run", a.dryRun, "print the changes instead of making them")
+	return fs
+}
+
+// parse parses the flags of args wherever they are, returning the other
+// arguments, of which there must be want.
+func parse(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != want {
+		fmt.Fprintf(fs.Output(), "%s takes %d argument(s), got %d\n", fs.Name(), want, len(positional))
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// required fails with the usage of fs unless every named flag is set.
+func required(fs *flag.FlagSet, names ...string) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, name := range names {
+		if !set[name] {
+			fmt.Fprintf(fs.Output(), "%s requires -%s\n", fs.Name(), name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+// plan prints a change a dry run would make.
+func (a *app) plan(format string, args ...interface{}) {
+	fmt.Fprintf(a.out, "would "+format+"\n", args...)
+}
+
+func defaultConfigPath() string {
+	if path := os.Getenv("ASCENDA_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ascenda-admin", "config.yaml")
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
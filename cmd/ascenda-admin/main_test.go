@@ -0,0 +1,371 @@
+package main
+
+import (
+	"ascenda/awstest"
+	"ascenda/dynamotest"
+	"ascenda/localserver"
+	"ascenda/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	adminID = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e01"
+	janeID  = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e02"
+	bobID   = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e03"
+	janePts = "0b9e3c2a-5f4d-4e6b-8a7c-1d2e3f4a5b01"
+	bobPts  = "0b9e3c2a-5f4d-4e6b-8a7c-1d2e3f4a5b02"
+	reqID   = "1f2e3d4c-5b6a-4978-8a6b-5c4d3e2f1a01"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	type role struct {
+		Role   string              `json:"role"`
+		Access map[string][]string `json:"access"`
+	}
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"block", "role: a # comment\naccess:\n  \"*\": [\"*\"]\n", `{"role":"a","access":{"*":["*"]}}`, false},
+		{"flow", "{role: 'it''s', access: {/me: [GET, PATCH]}}", `{"role":"it's","access":{"/me":["GET","PATCH"]}}`, false},
+		{"empty", "# nothing\n", `{"role":"","access":null}`, false},
+		{"unknown field", "role: a\nacess: {}\n", "", true},
+		{"duplicate key", "role: a\nrole: b\n", "", true},
+		{"tab", "access:\n\t/me: [GET]\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got role
+			err := decodeYAML([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			encoded, _ := json.Marshal(got)
+			if string(encoded) != tt.want {
+				t.Errorf("decoded %s, want %s", encoded, tt.want)
+			}
+		})
+	}
+}
+
+const rolesYAML = `roles:
+  - role: customer
+    access:
+      /me: [GET, PATCH]
+      /me/points: [GET]
+  - role: auditor       # inherits from a role created alongside it
+    inherits: [reader]
+    access: {}
+  - role: reader
+    access:
+      /logs: [GET]
+`
+
+// newDB returns tables with an admin, two customers with points accounts and
+// a pending maker request.
+func newDB(t *testing.T) *dynamotest.DB {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "roles",
+		types.Role{Role: "admin", Access: map[string][]string{"*": {"*"}}},
+		types.Role{Role: "customer", Access: map[string][]string{"/me": {"GET", "PATCH"}}})
+	db.Seed(t, "users",
+		types.User{User_ID: adminID, Email: "admin@example.com", FirstName: "Ada", LastName: "Admin", Role: "admin"},
+		types.User{User_ID: janeID, Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+		types.User{User_ID: bobID, Email: "bob@example.com", FirstName: "Bob", LastName: "Lee", Role: "customer"})
+	db.Seed(t, "emails",
+		map[string]string{"email": "admin@example.com", "user_id": adminID},
+		map[string]string{"email": "jane@example.com", "user_id": janeID},
+		map[string]string{"email": "bob@example.com", "user_id": bobID})
+	db.Seed(t, "points",
+		types.UserPoint{User_ID: janeID, Points_ID: janePts, Points: 100},
+		types.UserPoint{User_ID: bobID, Points_ID: bobPts, Points: 5})
+	db.Seed(t, "makers", types.MakerRequest{RequestUUID: reqID, CheckerRole: "admin", MakerUUID: adminID,
+		RequestStatus: "pending", ResourceType: "points",
+		RequestData: json.RawMessage(`{"user_id":"` + janeID + `","points_id":"` + janePts + `","points":15}`)})
+	db.Seed(t, "logs",
+		types.Log{Log_ID: "log-1", Description: "Ada Admin created user Jane Doe",
+			Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()},
+		types.Log{Log_ID: "log-2", Description: "Ada Admin created user Bob Lee",
+			Timestamp: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC).Unix()})
+	return db
+}
+
+// points returns the balance of each points account.
+func points(t *testing.T, db *dynamotest.DB) map[string]int {
+	var accounts []types.UserPoint
+	db.Load(t, "points", &accounts)
+	balances := map[string]int{}
+	for _, account := range accounts {
+		balances[account.Points_ID] = account.Points
+	}
+	return balances
+}
+
+func user(t *testing.T, db *dynamotest.DB, id string) types.User {
+	var users []types.User
+	db.Load(t, "users", &users)
+	for _, user := range users {
+		if user.User_ID == id {
+			return user
+		}
+	}
+	return types.User{}
+}
+
+func role(t *testing.T, db *dynamotest.DB, name string) types.Role {
+	var roles []types.Role
+	db.Load(t, "roles", &roles)
+	for _, role := range roles {
+		if role.Role == name {
+			return role
+		}
+	}
+	return types.Role{}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	rolesFile := filepath.Join(dir, "roles.yaml")
+	if err := os.WriteFile(rolesFile, []byte(rolesYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	create := []string{"users", "create", "-email", "new@example.com", "-first-name", "New", "-last-name", "User",
+		"-role", "customer"}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  []string
+		check    func(t *testing.T, db *dynamotest.DB)
+	}{
+		{name: "list users as json", args: []string{"-o", "json", "users", "list", "-role", "customer"},
+			wantOut: []string{`"email": "jane@example.com"`, `"email": "bob@example.com"`}},
+		{name: "get user as csv", args: []string{"users", "get", janeID, "-o", "csv"},
+			wantOut: []string{"USER_ID,EMAIL,FIRST_NAME,LAST_NAME,ROLE,STATUS\n" + janeID + ",jane@example.com,Jane,Doe"}},
+		{name: "missing user", args: []string{"users", "get", "nope"}, wantCode: 1},
+		{name: "create user", args: create, wantOut: []string{"new@example.com"},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if n := len(db.Items("users")); n != 4 {
+					t.Errorf("%d users, want 4", n)
+				}
+			}},
+		{name: "create user dry run", args: append([]string{"-dry-run"}, create...),
+			wantOut: []string{"would create user New User <new@example.com> with role customer"},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if n := len(db.Items("users")); n != 3 {
+					t.Errorf("%d users, want 3", n)
+				}
+			}},
+		{name: "create user without role", args: create[:len(create)-2], wantCode: 2},
+		{name: "disable user", args: []string{"users", "disable", bobID}, wantOut: []string{types.UserStatusDisabled},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if status := user(t, db, bobID).Status; status != types.UserStatusDisabled {
+					t.Errorf("bob is %q, want disabled", status)
+				}
+			}},
+		{name: "disable user dry run", args: []string{"users", "disable", bobID, "-dry-run"},
+			wantOut: []string{"would disable user " + bobID},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if status := user(t, db, bobID).Status; status == types.UserStatusDisabled {
+					t.Error("bob was disabled")
+				}
+			}},
+		{name: "get points", args: []string{"points", "get", janeID}, wantOut: []string{janePts + "  100"}},
+		{name: "adjust points", args: []string{"points", "adjust", janeID, "-by", "-10"},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if balance := points(t, db)[janePts]; balance != 90 {
+					t.Errorf("jane has %d points, want 90", balance)
+				}
+			}},
+		{name: "adjust points below zero", args: []string{"points", "adjust", janeID, "-by", "-101"}, wantCode: 1},
+		{name: "transfer points", args: []string{"points", "transfer", janeID, bobID, "-amount", "30"},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if balances := points(t, db); balances[janePts] != 70 || balances[bobPts] != 35 {
+					t.Errorf("balances = %v, want 70 and 35", balances)
+				}
+			}},
+		{name: "transfer points dry run", args: []string{"-dry-run", "points", "transfer", janeID, bobID, "-amount", "30"},
+			wantOut: []string{"would set account " + janePts + " of user " + janeID + " from 100 to 70 points",
+				"would set account " + bobPts + " of user " + bobID + " from 5 to 35 points"},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if balances := points(t, db); balances[janePts] != 100 || balances[bobPts] != 5 {
+					t.Errorf("balances = %v, want them unchanged", balances)
+				}
+			}},
+		{name: "transfer more than the balance", args: []string{"points", "transfer", bobID, janeID, "-amount", "6"},
+			wantCode: 1},
+		{name: "list checker requests", args: []string{"makers", "list", "-role", "admin"},
+			wantOut: []string{reqID + "  pending"}},
+		{name: "approve maker request", args: []string{"makers", "approve", reqID}, wantOut: []string{"approved"},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if balance := points(t, db)[janePts]; balance != 15 {
+					t.Errorf("jane has %d points, want the approved 15", balance)
+				}
+			}},
+		{name: "reject maker request dry run", args: []string{"makers", "reject", reqID, "-dry-run"},
+			wantOut: []string{"would reject maker request " + reqID},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				var requests []types.MakerRequest
+				db.Load(t, "makers", &requests)
+				if requests[0].RequestStatus != "pending" {
+					t.Errorf("request is %s, want pending", requests[0].RequestStatus)
+				}
+			}},
+		{name: "diff roles", args: []string{"roles", "diff", "-f", rolesFile, "-o", "csv"},
+			wantOut: []string{"customer,update,access,\"{\"\"/me\"\":[\"\"GET\"\",\"\"PATCH\"\"]}\"",
+				"auditor,create,inherits,,\"[\"\"reader\"\"]\"", "reader,create,access", "admin,unmanaged"}},
+		{name: "apply roles", args: []string{"roles", "apply", "-f", rolesFile},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if role(t, db, "auditor").Inherits[0] != "reader" || len(role(t, db, "customer").Access) != 2 {
+					t.Errorf("roles = %+v", db.Items("roles"))
+				}
+				if len(role(t, db, "admin").Access) != 1 {
+					t.Error("unmanaged admin role changed")
+				}
+			}},
+		{name: "apply roles dry run", args: []string{"roles", "apply", "-f", rolesFile, "-dry-run"},
+			wantOut: []string{"would update role customer: access", "would create role reader: access\n" +
+				"would create role auditor: inherits"},
+			check: func(t *testing.T, db *dynamotest.DB) {
+				if role(t, db, "reader").Role != "" {
+					t.Error("reader was created")
+				}
+			}},
+		{name: "tail logs", args: []string{"logs", "tail", "-n", "1"}, wantOut: []string{"log-2"}},
+		{name: "export logs", args: []string{"logs", "export", "-since", "2024-01-15"},
+			wantOut: []string{"TIME,LOG_ID,DESCRIPTION,IP,USER_AGENT\n", ",log-2,Ada Admin created user Bob Lee"}},
+		{name: "unknown command", args: []string{"users", "purge"}, wantCode: 2},
+		{name: "unknown output", args: []string{"-o", "xml", "users", "list"}, wantCode: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+			routes, err := localserver.ReadRoutes("../../template.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			server, err := localserver.New(awstest.Deps(db), routes, localserver.Handlers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			server.UserID = adminID
+			api := httptest.NewServer(server)
+			defer api.Close()
+
+			config := filepath.Join(t.TempDir(), "config.yaml")
+			profiles := "test:\n  url: " + api.URL + "\n  token: unused\n  requester: Ada-Admin\n"
+			if err := os.WriteFile(config, []byte(profiles), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-config", config, "-profile", "test"}, tt.args...)
+			if code := run(context.Background(), args, &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("exit code %d, want %d\nstdout: %s\nstderr: %s", code, tt.wantCode, stdout.String(),
+					stderr.String())
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, stdout.String())
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, db)
+			}
+		})
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	profiles := "default:\n  url: https://prod.example.com\n  requester: Ada-Admin\nstaging:\n  url: https://staging.example.com\n"
+	if err := os.WriteFile(config, []byte(profiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		profile string
+		env     map[string]string
+		want    profile
+		wantErr bool
+	}{
+		{"profile", config, "default", nil, profile{URL: "https://prod.example.com", Requester: "Ada-Admin"}, false},
+		{"token from env", config, "staging", map[string]string{"ASCENDA_TOKEN": "t"},
+			profile{URL: "https://staging.example.com", Token: "t"}, false},
+		{"unknown profile", config, "dev", nil, profile{}, true},
+		{"env without file", filepath.Join(t.TempDir(), "none.yaml"), "default",
+			map[string]string{"ASCENDA_URL": "http://localhost:3000"}, profile{URL: "http://localhost:3000"}, false},
+		{"no url", filepath.Join(t.TempDir(), "none.yaml"), "default", nil, profile{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"ASCENDA_URL", "ASCENDA_TOKEN", "ASCENDA_REQUESTER"} {
+				t.Setenv(name, tt.env[name])
+			}
+			got, err := loadProfile(tt.path, tt.profile)
+			if (err != nil) != tt.wantErr || !tt.wantErr && got != tt.want {
+				t.Errorf("loadProfile = %+v, %v, want %+v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTransferKeepsChangedDebit checks a failed transfer does not revert the
+// debit over a change made to the account after it.
+func TestTransferKeepsChangedDebit(t *testing.T) {
+	const frozenPts = "0b9e3c2a-5f4d-4e6b-8a7c-1d2e3f4a5b03"
+	db := newDB(t)
+	db.Seed(t, "points", types.UserPoint{User_ID: bobID, Points_ID: frozenPts, Status: types.PointsStatusFrozen})
+	routes, err := localserver.ReadRoutes("../../template.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := localserver.New(awstest.Deps(db), routes, localserver.Handlers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.UserID = adminID
+	//jane spends points between the debit and the failed credit
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Query().Get("id") == bobID {
+			db.Seed(t, "points", types.UserPoint{User_ID: janeID, Points_ID: janePts, Points: 50})
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer api.Close()
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	profiles := "test:\n  url: " + api.URL + "\n  token: unused\n  requester: Ada-Admin\n"
+	if err := os.WriteFile(config, []byte(profiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-config", config, "-profile", "test", "points", "transfer", janeID, bobID, "-amount", "30",
+		"-to-account", frozenPts}
+	if code := run(context.Background(), args, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit code %d, want 1\nstderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "also failed") {
+		t.Errorf("stderr = %q, want the revert reported as failed", stderr.String())
+	}
+	if balances := points(t, db); balances[janePts] != 50 || balances[frozenPts] != 0 {
+		t.Errorf("balances = %v, want jane's 50 kept", balances)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"ascenda/types"
+	"context"
+	"fmt"
+	"strings"
+)
+
+func listMakers(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("makers list")
+	maker := fs.String("maker", "", "only list the requests the user made")
+	role := fs.String("role", "", "only list the requests awaiting the checker role")
+	status := fs.String("status", "", "pending, approved or rejected; pending with -maker or -role, else every status")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *maker != "" && *role != "" {
+		return fmt.Errorf("-maker and -role cannot be combined")
+	}
+
+	var requests []types.ReturnMakerRequest
+	var err error
+	switch {
+	case *maker != "":
+		requests, err = a.client.MakerRequestsOf(ctx, *maker, orPending(*status))
+	case *role != "":
+		var checks []types.MakerRequest
+		checks, err = a.client.CheckerRequests(ctx, *role, orPending(*status))
+		for _, check := range checks {
+			requests = append(requests, types.ReturnMakerRequest{RequestUUID: check.RequestUUID,
+				CheckerRole: []string{check.CheckerRole}, MakerUUID: check.MakerUUID, CheckerUUID: check.CheckerUUID,
+				RequestStatus: check.RequestStatus, ResourceType: check.ResourceType, RequestData: check.RequestData})
+		}
+	default:
+		var all []types.ReturnMakerRequest
+		all, err = a.client.ListMakerRequests(ctx).All()
+		for _, request := range all {
+			if *status == "" || request.RequestStatus == *status {
+				requests = append(requests, request)
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(requests, makersTable(requests...))
+}
+
+func approveMaker(ctx context.Context, a *app, args []string) error {
+	return decideMaker(ctx, a, "approve", args)
+}
+
+func rejectMaker(ctx context.Context, a *app, args []string) error {
+	return decideMaker(ctx, a, "reject", args)
+}
+
+// decideMaker decides the maker request as the caller, checking as the role
+// given or else their own.
+func decideMaker(ctx context.Context, a *app, decision string, args []string) error {
+	fs := a.flags("makers " + decision)
+	role := fs.String("role", "", "checker role to decide as, the caller's own role by default")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	caller, err := a.client.GetProfile(ctx)
+	if err != nil {
+		return err
+	}
+	if *role == "" {
+		*role = caller.Role
+	}
+	requests, err := a.client.GetMakerRequest(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if len(requests) == 0 {
+		return types.ErrorMakerReqDoesNotExist
+	}
+	request := requests[0]
+	if request.RequestStatus != "pending" {
+		return fmt.Errorf("maker request %s is already %s", request.RequestUUID, request.RequestStatus)
+	}
+
+	if a.dryRun {
+		a.plan("%s maker request %s to change %s %s as %s %s", decision, request.RequestUUID, request.ResourceType,
+			request.RequestData, *role, caller.User_ID)
+		return nil
+	}
+	decided, err := a.client.DecideMakerRequest(ctx, types.DecisionBody{RequestId: request.RequestUUID,
+		CheckerRole: *role, CheckerId: caller.User_ID, Decision: decision})
+	if err != nil {
+		return err
+	}
+	return a.print(decided, makersTable(decided...))
+}
+
+func orPending(status string) string {
+	if status == "" {
+		return "pending"
+	}
+	return status
+}
+
+func makersTable(requests ...types.ReturnMakerRequest) table {
+	t := table{header: []string{"REQ_ID", "STATUS", "RESOURCE_TYPE", "MAKER_ID", "CHECKER_ROLES", "CHECKER_ID",
+		"REQUEST_DATA"}}
+	for _, request := range requests {
+		t.add(request.RequestUUID, request.RequestStatus, request.ResourceType, request.MakerUUID,
+			strings.Join(request.CheckerRole, ","), request.CheckerUUID, string(request.RequestData))
+	}
+	return t
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// table is the rows of a result printed as a table or csv.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(row ...string) {
+	t.rows = append(t.rows, row)
+}
+
+// print prints value, the result as the api returned it, as json, or t as a
+// csv or an aligned table, without a header line if t has none.
+func (a *app) print(value interface{}, t table) error {
+	switch a.format {
+	case "json":
+		//empty lists are printed as [] rather than null
+		if v := reflect.ValueOf(value); v.Kind() == reflect.Slice && v.IsNil() {
+			value = []struct{}{}
+		}
+		encoder := json.NewEncoder(a.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case "csv":
+		w := csv.NewWriter(a.out)
+		if t.header != nil {
+			w.Write(t.header)
+		}
+		w.WriteAll(t.rows)
+		return w.Error()
+	}
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	if t.header != nil {
+		w.Write([]byte(strings.Join(t.header, "\t") + "\n"))
+	}
+	for _, row := range t.rows {
+		w.Write([]byte(strings.Join(row, "\t") + "\n"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"ascenda/types"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+func getPoints(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("points get")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := a.client.GetPoints(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return a.print(accounts, pointsTable(accounts...))
+}
+
+func adjustPoints(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("points adjust")
+	by := fs.Int("by", 0, "points to add, or take away when negative")
+	accountID := fs.String("account", "", "points_id of the account, needed when the user has more than one")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if err := required(fs, "by"); err != nil {
+		return err
+	}
+
+	account, err := a.account(ctx, positional[0], *accountID)
+	if err != nil {
+		return err
+	}
+	if account.Points+*by < 0 {
+		return fmt.Errorf("account %s has %d points, cannot take away %d", account.Points_ID, account.Points, -*by)
+	}
+	if a.dryRun {
+		a.plan("set account %s of user %s from %d to %d points", account.Points_ID, account.User_ID, account.Points,
+			account.Points+*by)
+		return nil
+	}
+	balance := account.Points
+	account.Points += *by
+	updated, err := a.client.UpdatePointsFrom(ctx, account, balance)
+	if err != nil {
+		return err
+	}
+	return a.print(updated, pointsTable(*updated))
+}
+
+// transferPoints moves points between the accounts of two users with two
+// updates, each applied only while the account holds the balance read. When
+// the credit fails the debit is reverted, the api has no transaction spanning
+// both, unless the debited account changed since.
+func transferPoints(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("points transfer")
+	amount := fs.Int("amount", 0, "points to move")
+	fromID := fs.String("from-account", "", "points_id to take from, needed when the user has more than one")
+	toID := fs.String("to-account", "", "points_id to add to, needed when the user has more than one")
+	positional, err := parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if *amount <= 0 {
+		return fmt.Errorf("-amount must be positive, got %d", *amount)
+	}
+
+	from, err := a.account(ctx, positional[0], *fromID)
+	if err != nil {
+		return err
+	}
+	to, err := a.account(ctx, positional[1], *toID)
+	if err != nil {
+		return err
+	}
+	if from.Points_ID == to.Points_ID {
+		return fmt.Errorf("cannot transfer from account %s to itself", from.Points_ID)
+	}
+	if from.Points < *amount {
+		return fmt.Errorf("account %s has %d points, cannot transfer %d", from.Points_ID, from.Points, *amount)
+	}
+	if a.dryRun {
+		a.plan("set account %s of user %s from %d to %d points", from.Points_ID, from.User_ID, from.Points,
+			from.Points-*amount)
+		a.plan("set account %s of user %s from %d to %d points", to.Points_ID, to.User_ID, to.Points, to.Points+*amount)
+		return nil
+	}
+
+	debit, credit := from, to
+	debit.Points -= *amount
+	credit.Points += *amount
+	debited, err := a.client.UpdatePointsFrom(ctx, debit, from.Points)
+	if err != nil {
+		return err
+	}
+	credited, err := a.client.UpdatePointsFrom(ctx, credit, to.Points)
+	if err != nil {
+		if _, revertErr := a.client.UpdatePointsFrom(ctx, from, debited.Points); revertErr != nil {
+			return fmt.Errorf("crediting %s: %w; reverting the debit of %s to %d points also failed: %v",
+				to.Points_ID, err, from.Points_ID, from.Points, revertErr)
+		}
+		return fmt.Errorf("crediting %s: %w; the debit of %s was reverted", to.Points_ID, err, from.Points_ID)
+	}
+	return a.print([]types.UserPoint{*debited, *credited}, pointsTable(*debited, *credited))
+}
+
+// account returns the points account of the user with the points_id, or
+// their only open account when id is empty. Closed and frozen accounts are
+// not open.
+func (a *app) account(ctx context.Context, userID, id string) (types.UserPoint, error) {
+	accounts, err := a.client.GetPoints(ctx, userID)
+	if err != nil {
+		return types.UserPoint{}, err
+	}
+	var open []types.UserPoint
+	for _, account := range accounts {
+		switch {
+		case id != "" && account.Points_ID == id:
+			return account, nil
+		case id == "" && account.Status != types.PointsStatusClosed && account.Status != types.PointsStatusFrozen:
+			open = append(open, account)
+		}
+	}
+	switch {
+	case id != "":
+		return types.UserPoint{}, fmt.Errorf("user %s has no points account %s", userID, id)
+	case len(open) == 0:
+		return types.UserPoint{}, fmt.Errorf("user %s has no open points account", userID)
+	case len(open) > 1:
+		return types.UserPoint{}, fmt.Errorf("user %s has %d points accounts, choose one by its points_id", userID, len(open))
+	}
+	return open[0], nil
+}
+
+func pointsTable(accounts ...types.UserPoint) table {
+	t := table{header: []string{"USER_ID", "POINTS_ID", "POINTS", "STATUS"}}
+	for _, account := range accounts {
+		t.add(account.User_ID, account.Points_ID, strconv.Itoa(account.Points), account.Status)
+	}
+	return t
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"ascenda/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	roleCreate    = "create"
+	roleUpdate    = "update"
+	roleUnchanged = "unchanged"
+	//roleUnmanaged is a stored role missing from the file, which apply leaves
+	//as it is
+	roleUnmanaged = "unmanaged"
+)
+
+// rolesFile is the yaml file roles are applied from:
+//
+//	roles:
+//	  - role: auditor
+//	    inherits: [customer]
+//	    access:
+//	      /logs: [GET]
+//	    policy:
+//	      target_roles: [customer]
+type rolesFile struct {
+	Roles []types.Role `json:"roles"`
+}
+
+// roleChange is the difference between a role of the file and the stored
+// role.
+type roleChange struct {
+	Role   string        `json:"role"`
+	Change string        `json:"change"`
+	Fields []fieldChange `json:"fields,omitempty"`
+
+	desired types.Role
+}
+
+type fieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+func listRoles(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("roles list")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	roles, err := a.client.ListRoles(ctx).All()
+	if err != nil {
+		return err
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Role < roles[j].Role })
+	t := table{header: []string{"ROLE", "INHERITS", "ACCESS", "DENY", "POLICY"}}
+	for _, role := range roles {
+		t.add(role.Role, strings.Join(role.Inherits, ","), routes(role.Access), routes(role.Deny), canonical(role.Policy))
+	}
+	return a.print(roles, t)
+}
+
+func diffRoles(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("roles diff")
+	file := fs.String("f", "", "yaml file of the roles")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := required(fs, "f"); err != nil {
+		return err
+	}
+
+	changes, err := a.diff(ctx, *file)
+	if err != nil {
+		return err
+	}
+	return a.print(changes, changesTable(changes))
+}
+
+// applyRoles creates and updates the roles of the file to match it, roles
+// before those inheriting from them.
+func applyRoles(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("roles apply")
+	file := fs.String("f", "", "yaml file of the roles")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := required(fs, "f"); err != nil {
+		return err
+	}
+
+	changes, err := a.diff(ctx, *file)
+	if err != nil {
+		return err
+	}
+	var applied []roleChange
+	for _, change := range inheritanceOrder(changes) {
+		if change.Change != roleCreate && change.Change != roleUpdate {
+			continue
+		}
+		if a.dryRun {
+			var fields []string
+			for _, field := range change.Fields {
+				fields = append(fields, field.Field)
+			}
+			a.plan("%s role %s: %s", change.Change, change.Role, strings.Join(fields, ", "))
+			continue
+		}
+		if change.Change == roleCreate {
+			_, err = a.client.CreateRole(ctx, change.desired)
+		} else {
+			_, err = a.client.UpdateRole(ctx, change.desired)
+		}
+		if err != nil {
+			return fmt.Errorf("%s role %s: %w", change.Change, change.Role, err)
+		}
+		applied = append(applied, change)
+	}
+	if a.dryRun {
+		return nil
+	}
+	return a.print(applied, changesTable(applied))
+}
+
+// diff compares the roles of the file with the stored ones.
+func (a *app) diff(ctx context.Context, path string) ([]roleChange, error) {
+	desired, err := readRoles(path)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := a.client.ListRoles(ctx).All()
+	if err != nil {
+		return nil, err
+	}
+	stored := map[string]types.Role{}
+	for _, role := range roles {
+		stored[role.Role] = role
+	}
+
+	var changes []roleChange
+	for _, role := range desired {
+		current, exists := stored[role.Role]
+		delete(stored, role.Role)
+		change := roleChange{Role: role.Role, Change: roleUnchanged, desired: role}
+		for _, field := range []struct {
+			name          string
+			before, after interface{}
+		}{
+			{"inherits", current.Inherits, role.Inherits},
+			{"access", current.Access, role.Access},
+			{"deny", current.Deny, role.Deny},
+			{"policy", current.Policy, role.Policy},
+		} {
+			if before, after := canonical(field.before), canonical(field.after); before != after {
+				change.Fields = append(change.Fields, fieldChange{Field: field.name, Before: before, After: after})
+			}
+		}
+		switch {
+		case !exists:
+			change.Change = roleCreate
+		case len(change.Fields) > 0:
+			change.Change = roleUpdate
+		}
+		changes = append(changes, change)
+	}
+	for _, name := range sortedKeys(stored) {
+		changes = append(changes, roleChange{Role: name, Change: roleUnmanaged})
+	}
+	return changes, nil
+}
+
+func readRoles(path string) ([]types.Role, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file rolesFile
+	if err := decodeYAML(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	seen := map[string]bool{}
+	for i, role := range file.Roles {
+		if role.Role == "" {
+			return nil, fmt.Errorf("%s: role %d has no name", path, i+1)
+		}
+		if seen[role.Role] {
+			return nil, fmt.Errorf("%s: role %s is listed twice", path, role.Role)
+		}
+		seen[role.Role] = true
+		if role.Access == nil {
+			file.Roles[i].Access = map[string][]string{}
+		}
+	}
+	return file.Roles, nil
+}
+
+// inheritanceOrder orders the changes so the roles of the file come after the
+// roles of the file they inherit from, which must exist when they are
+// stored. Cycles are left in file order for the api to refuse.
+func inheritanceOrder(changes []roleChange) []roleChange {
+	inFile := map[string]bool{}
+	for _, change := range changes {
+		inFile[change.Role] = change.desired.Role != ""
+	}
+	var ordered []roleChange
+	placed := map[string]bool{}
+	for len(ordered) < len(changes) {
+		progressed := false
+		for _, change := range changes {
+			if placed[change.Role] {
+				continue
+			}
+			ready := true
+			for _, parent := range change.desired.Inherits {
+				if inFile[parent] && !placed[parent] && parent != change.Role {
+					ready = false
+				}
+			}
+			if ready {
+				ordered, placed[change.Role], progressed = append(ordered, change), true, true
+			}
+		}
+		if !progressed {
+			for _, change := range changes {
+				if !placed[change.Role] {
+					ordered, placed[change.Role] = append(ordered, change), true
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// canonical is the json of a role field, or empty if it is unset, so nil and
+// empty values compare equal.
+func canonical(v interface{}) string {
+	data, _ := json.Marshal(v)
+	switch s := string(data); s {
+	case "null", "{}", "[]":
+		return ""
+	default:
+		return s
+	}
+}
+
+// routes lists the methods of each route of access, such as
+// /logs:GET /users:GET,PUT.
+func routes(access map[string][]string) string {
+	var entries []string
+	for _, route := range sortedKeys(access) {
+		entries = append(entries, route+":"+strings.Join(access[route], ","))
+	}
+	return strings.Join(entries, " ")
+}
+
+func changesTable(changes []roleChange) table {
+	t := table{header: []string{"ROLE", "CHANGE", "FIELD", "BEFORE", "AFTER"}}
+	for _, change := range changes {
+		if len(change.Fields) == 0 {
+			t.add(change.Role, change.Change, "", "", "")
+		}
+		for _, field := range change.Fields {
+			t.add(change.Role, change.Change, field.Field, field.Before, field.After)
+		}
+	}
+	return t
+}
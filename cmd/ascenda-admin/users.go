@@ -0,0 +1,95 @@
+package main
+
+import (
+	"ascenda/client"
+	"ascenda/types"
+	"context"
+)
+
+func listUsers(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("users list")
+	role := fs.String("role", "", "only list the users of the role")
+	includeDeleted := fs.Bool("include-deleted", false, "list soft deleted users too")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	users, err := a.client.ListUsers(ctx, client.ListUsersOptions{Role: *role, IncludeDeleted: *includeDeleted}).All()
+	if err != nil {
+		return err
+	}
+	return a.print(users, usersTable(users...))
+}
+
+func getUser(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("users get")
+	includeDeleted := fs.Bool("include-deleted", false, "return the user even if soft deleted")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.client.GetUser(ctx, positional[0], *includeDeleted)
+	if err != nil {
+		return err
+	}
+	return a.print(user, usersTable(*user))
+}
+
+func createUser(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("users create")
+	var user types.User
+	fs.StringVar(&user.Email, "email", "", "email the user signs in with")
+	fs.StringVar(&user.FirstName, "first-name", "", "first name")
+	fs.StringVar(&user.LastName, "last-name", "", "last name")
+	fs.StringVar(&user.Role, "role", "", "role")
+	password := fs.String("password", "", "password, instead of the temporary one Cognito emails")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := required(fs, "email", "first-name", "last-name", "role"); err != nil {
+		return err
+	}
+
+	if a.dryRun {
+		a.plan("create user %s %s <%s> with role %s", user.FirstName, user.LastName, user.Email, user.Role)
+		return nil
+	}
+	created, err := a.client.CreateUser(ctx, user, *password)
+	if err != nil {
+		return err
+	}
+	return a.print(created, usersTable(*created))
+}
+
+func disableUser(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("users disable")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.client.GetUser(ctx, positional[0], false)
+	if err != nil {
+		return err
+	}
+	if a.dryRun {
+		a.plan("disable user %s <%s>", user.User_ID, user.Email)
+		return nil
+	}
+	if err := a.client.DisableUser(ctx, user.User_ID); err != nil {
+		return err
+	}
+	if user, err = a.client.GetUser(ctx, user.User_ID, false); err != nil {
+		return err
+	}
+	return a.print(user, usersTable(*user))
+}
+
+func usersTable(users ...types.User) table {
+	t := table{header: []string{"USER_ID", "EMAIL", "FIRST_NAME", "LAST_NAME", "ROLE", "STATUS"}}
+	for _, user := range users {
+		t.add(user.User_ID, user.Email, user.FirstName, user.LastName, user.Role, user.Status)
+	}
+	return t
+}
@@ -0,0 +1,128 @@
+// Command duplicate-emails reports users sharing an email, compared case
+// insensitively, and can reserve the emails of the remaining users in the
+// emails table so create-users and update-users reject new duplicates.
+//
+// Usage:
+//
+//	AWS_REGION=ap-southeast-1 go run ./cmd/duplicate-emails [-backfill]
+package main
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Duplicate is an email held by more than one user.
+type Duplicate struct {
+	Email string       `json:"email"`
+	Users []types.User `json:"users"`
+}
+
+// Report is the output of a run.
+type Report struct {
+	Users      int         `json:"users"`
+	Duplicates []Duplicate `json:"duplicates"`
+	Reserved   int         `json:"reserved"`
+}
+
+func main() {
+	backfill := flag.Bool("backfill", false, "reserve the emails of users without duplicates in the emails table")
+	flag.Parse()
+
+	deps, err := utility.NewDeps()
+	if err != nil {
+		log.Fatal(err)
+	}
+	dynaClient := deps.Dynamo
+
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamEmailsTable)
+	if err != nil {
+		log.Fatal(err)
+	}
+	USER_TABLE := cfg.UserTable
+	EMAILS_TABLE := cfg.EmailsTable
+
+	users, err := utility.ScanAllUsers(USER_TABLE, dynaClient)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := Report{Users: len(users), Duplicates: FindDuplicateEmails(users)}
+	if *backfill {
+		report.Reserved, err = ReserveEmails(users, report.Duplicates, EMAILS_TABLE, dynaClient)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// FindDuplicateEmails groups the users sharing an email, ordered by email.
+func FindDuplicateEmails(users []types.User) []Duplicate {
+	byEmail := map[string][]types.User{}
+	for _, user := range users {
+		key := utility.EmailKey(user.Email)
+		byEmail[key] = append(byEmail[key], user)
+	}
+
+	duplicates := []Duplicate{}
+	for email, holders := range byEmail {
+		if len(holders) > 1 {
+			duplicates = append(duplicates, Duplicate{Email: email, Users: holders})
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Email < duplicates[j].Email
+	})
+	return duplicates
+}
+
+// ReserveEmails writes a guard item for every user whose email is not
+// duplicated, leaving existing guards untouched. Duplicates must be resolved
+// by hand before their emails can be reserved.
+func ReserveEmails(users []types.User, duplicates []Duplicate, emailsTable string, dynaClient dynamodbiface.DynamoDBAPI) (int, error) {
+	skip := map[string]bool{}
+	for _, duplicate := range duplicates {
+		skip[duplicate.Email] = true
+	}
+
+	reserved := 0
+	for _, user := range users {
+		key := utility.EmailKey(user.Email)
+		if skip[key] {
+			continue
+		}
+
+		_, err := dynaClient.PutItem(&dynamodb.PutItemInput{
+			Item: map[string]*dynamodb.AttributeValue{
+				"email":   {S: aws.String(key)},
+				"user_id": {S: aws.String(user.User_ID)},
+			},
+			TableName:           aws.String(emailsTable),
+			ConditionExpression: aws.String("attribute_not_exists(email)"),
+		})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			continue
+		}
+		if err != nil {
+			return reserved, err
+		}
+		reserved++
+	}
+	return reserved, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"ascenda/types"
+	"testing"
+)
+
+func TestFindDuplicateEmails(t *testing.T) {
+	users := []types.User{
+		{User_ID: "1", Email: "jane@example.com"},
+		{User_ID: "2", Email: "John@example.com"},
+		{User_ID: "3", Email: " JANE@example.com"},
+		{User_ID: "4", Email: "john@example.com"},
+		{User_ID: "5", Email: "solo@example.com"},
+	}
+
+	got := FindDuplicateEmails(users)
+
+	want := map[string][]string{
+		"jane@example.com": {"1", "3"},
+		"john@example.com": {"2", "4"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("FindDuplicateEmails() = %+v, want %v", got, want)
+	}
+	for i, email := range []string{"jane@example.com", "john@example.com"} {
+		if got[i].Email != email {
+			t.Fatalf("duplicate %d = %s, want %s", i, got[i].Email, email)
+		}
+		for j, user := range got[i].Users {
+			if user.User_ID != want[email][j] {
+				t.Errorf("%s holder %d = %s, want %s", email, j, user.User_ID, want[email][j])
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Command localserver serves the api routes of template.yaml on a local port
+// by calling the function handlers in process. Parameters are read from
+// environment variables named like them, so no SSM parameter is needed, and
+// DYNAMODB_ENDPOINT points the handlers at DynamoDB Local.
+//
+// Usage:
+//
+//	AWS_REGION=ap-southeast-1 DYNAMODB_ENDPOINT=http://localhost:8000 USER_TABLE=users ... \
+//		go run ./cmd/localserver [-addr :3000] [-authorize] [-user-id <id>]
+package main
+
+import (
+	lambdaauthorizer "ascenda/functions/administrative/lambda-authorizer"
+	"ascenda/localserver"
+	"ascenda/utility"
+	"flag"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":3000", "address to listen on")
+	template := flag.String("template", "template.yaml", "SAM template to read the routes from")
+	authorize := flag.Bool("authorize", false, "run the lambda authorizer in front of every route")
+	userID := flag.String("user-id", "", "user_id passed to the handlers as the caller when not authorizing")
+	flag.Parse()
+
+	routes, err := localserver.ReadRoutes(*template)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deps, err := utility.NewDeps()
+	if err != nil {
+		log.Fatal(err)
+	}
+	server, err := localserver.New(deps, routes, localserver.Handlers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *authorize {
+		server.Authorizer = lambdaauthorizer.Handler
+	}
+	server.UserID = *userID
+
+	log.Printf("serving %d routes on %s", len(routes), *addr)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
@@ -0,0 +1,44 @@
+// Command migrate applies the pending migrations of migrations.Migrations from
+// a workstation, as the run-migrations function does in the deployment, and
+// prints the report. A failed run leaves its migration running and resumes it
+// on the next run.
+//
+// Usage:
+//
+//	AWS_REGION=ap-southeast-1 go run ./cmd/migrate [-dry-run] [-target <version>] [-segments <n>]
+package main
+
+import (
+	runmigrations "ascenda/functions/administrative/run-migrations"
+	"ascenda/migrations"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "count the items that would change without writing them")
+	target := flag.Int("target", 0, "last version to apply, every pending one when zero")
+	segments := flag.Int("segments", migrations.DefaultSegments, "parallel segments each table is scanned in")
+	flag.Parse()
+
+	deps, err := utility.NewDeps()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, runErr := runmigrations.Handler(deps, types.MigrationRequest{DryRun: *dryRun, Target: *target, Segments: *segments})
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if runErr != nil {
+		log.Fatal(runErr)
+	}
+}
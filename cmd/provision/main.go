@@ -0,0 +1,338 @@
+// Command provision creates the DynamoDB tables of schema.Tables with their
+// indexes and TTL, and with -seed stores a full access admin role and sample
+// users and points for development. Tables, indexes and items that already
+// exist are left as they are, so it can be run again at any time. Table names
+// are read like the handlers read them, from environment variables named like
+// their parameter or else from SSM, and DYNAMODB_ENDPOINT points it at
+// DynamoDB Local.
+//
+// Usage:
+//
+//	AWS_REGION=ap-southeast-1 DYNAMODB_ENDPOINT=http://localhost:8000 USER_TABLE=users ... \
+//		go run ./cmd/provision [-seed]
+package main
+
+import (
+	"ascenda/schema"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// pollInterval is how often a table is described while its indexes are built.
+var pollInterval = 5 * time.Second
+
+// maxPolls bounds the wait for a table to become active.
+const maxPolls = 120
+
+// TableReport is what a run changed on one table.
+type TableReport struct {
+	Table      string   `json:"table"`
+	Created    bool     `json:"created"`
+	Indexes    []string `json:"indexes_created,omitempty"`
+	TTLEnabled bool     `json:"ttl_enabled,omitempty"`
+}
+
+// Report is the output of a run.
+type Report struct {
+	Tables []TableReport  `json:"tables"`
+	Seeded map[string]int `json:"seeded,omitempty"`
+}
+
+// SeedRoles are the roles of a development environment.
+var SeedRoles = []types.Role{
+	{Role: "admin", Access: map[string][]string{"*": {"*"}}},
+	{Role: "customer", Access: map[string][]string{}},
+}
+
+// SeedUsers are an admin and two customers.
+var SeedUsers = []types.User{
+	{User_ID: "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e01", Email: "admin@example.com", FirstName: "Ada", LastName: "Admin", Role: "admin"},
+	{User_ID: "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e02", Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+	{User_ID: "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e03", Email: "john@example.com", FirstName: "John", LastName: "Tan", Role: "customer"},
+}
+
+// SeedPoints are a points account for each customer.
+var SeedPoints = []types.UserPoint{
+	{User_ID: "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e02", Points_ID: "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c01", Points: 1200},
+	{User_ID: "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e03", Points_ID: "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c02", Points: 350},
+}
+
+func main() {
+	seed := flag.Bool("seed", false, "store the admin role and sample users and points")
+	flag.Parse()
+
+	deps, err := utility.NewDeps()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tables, err := NamedTables(deps.Config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := Report{}
+	for _, table := range tables {
+		tableReport, err := EnsureTable(table, deps.Dynamo)
+		if err != nil {
+			log.Fatalf("table %s: %v", table.Name, err)
+		}
+		report.Tables = append(report.Tables, tableReport)
+	}
+
+	if *seed {
+		report.Seeded, err = Seed(tables, deps.Dynamo)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// NamedTables returns schema.Tables named as configured in the environment.
+func NamedTables(config utility.ConfigSource) ([]schema.Table, error) {
+	params := make([]string, len(schema.Tables))
+	for i, table := range schema.Tables {
+		params[i] = table.Param
+	}
+	cfg, err := config.Load(params...)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]schema.Table, len(schema.Tables))
+	for i, table := range schema.Tables {
+		table.Name = cfg.Value(table.Param)
+		if table.Name == "" {
+			return nil, fmt.Errorf("%w: %s is empty", types.ErrorLoadingConfig, table.Param)
+		}
+		tables[i] = table
+	}
+	return tables, nil
+}
+
+// EnsureTable creates the table if it does not exist, then adds the indexes
+// and enables the TTL it is missing.
+func EnsureTable(table schema.Table, dynaClient dynamodbiface.DynamoDBAPI) (TableReport, error) {
+	report := TableReport{Table: table.Name}
+
+	existing := map[string]bool{}
+	result, err := dynaClient.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(table.Name)})
+	var notFound *dynamodb.ResourceNotFoundException
+	switch {
+	case errors.As(err, &notFound):
+		if _, err := dynaClient.CreateTable(CreateTableInput(table)); err != nil {
+			return report, err
+		}
+		report.Created = true
+		for _, name := range table.IndexNames() {
+			existing[name] = true
+		}
+	case err != nil:
+		return report, err
+	default:
+		for _, index := range result.Table.GlobalSecondaryIndexes {
+			existing[aws.StringValue(index.IndexName)] = true
+		}
+	}
+	if err := waitUntilActive(table.Name, dynaClient); err != nil {
+		return report, err
+	}
+
+	//dynamo builds one new index at a time
+	for _, name := range table.IndexNames() {
+		if existing[name] {
+			continue
+		}
+		_, err := dynaClient.UpdateTable(&dynamodb.UpdateTableInput{
+			TableName:            aws.String(table.Name),
+			AttributeDefinitions: attributeDefinitions(table),
+			GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+				{Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+					IndexName:  aws.String(name),
+					KeySchema:  keySchema(table.Indexes[name]),
+					Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+				}},
+			},
+		})
+		if err != nil {
+			return report, err
+		}
+		report.Indexes = append(report.Indexes, name)
+		if err := waitUntilActive(table.Name, dynaClient); err != nil {
+			return report, err
+		}
+	}
+
+	if table.TTL == "" {
+		return report, nil
+	}
+	ttl, err := dynaClient.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: aws.String(table.Name)})
+	if err != nil {
+		return report, err
+	}
+	switch aws.StringValue(ttl.TimeToLiveDescription.TimeToLiveStatus) {
+	case dynamodb.TimeToLiveStatusEnabled, dynamodb.TimeToLiveStatusEnabling:
+		return report, nil
+	}
+	_, err = dynaClient.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(table.Name),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String(table.TTL),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return report, err
+	}
+	report.TTLEnabled = true
+	return report, nil
+}
+
+// CreateTableInput describes the table and its indexes, billed on demand.
+func CreateTableInput(table schema.Table) *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(table.Name),
+		AttributeDefinitions: attributeDefinitions(table),
+		KeySchema:            keySchema(table.Key),
+		BillingMode:          aws.String(dynamodb.BillingModePayPerRequest),
+	}
+	for _, name := range table.IndexNames() {
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName:  aws.String(name),
+			KeySchema:  keySchema(table.Indexes[name]),
+			Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+		})
+	}
+	return input
+}
+
+func attributeDefinitions(table schema.Table) []*dynamodb.AttributeDefinition {
+	var definitions []*dynamodb.AttributeDefinition
+	for _, attribute := range table.KeyAttributes() {
+		definitions = append(definitions, &dynamodb.AttributeDefinition{
+			AttributeName: aws.String(attribute),
+			AttributeType: aws.String(table.AttributeType(attribute)),
+		})
+	}
+	return definitions
+}
+
+func keySchema(key schema.Key) []*dynamodb.KeySchemaElement {
+	elements := []*dynamodb.KeySchemaElement{
+		{AttributeName: aws.String(key.Hash), KeyType: aws.String(dynamodb.KeyTypeHash)},
+	}
+	if key.Range != "" {
+		elements = append(elements, &dynamodb.KeySchemaElement{AttributeName: aws.String(key.Range), KeyType: aws.String(dynamodb.KeyTypeRange)})
+	}
+	return elements
+}
+
+// waitUntilActive polls the table until it and all of its indexes are active.
+func waitUntilActive(tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	for i := 0; i < maxPolls; i++ {
+		result, err := dynaClient.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err != nil {
+			return err
+		}
+		active := aws.StringValue(result.Table.TableStatus) == dynamodb.TableStatusActive
+		for _, index := range result.Table.GlobalSecondaryIndexes {
+			active = active && aws.StringValue(index.IndexStatus) == dynamodb.IndexStatusActive
+		}
+		if active {
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+	return fmt.Errorf("table %s did not become active", tableName)
+}
+
+// Seed stores SeedRoles, SeedUsers with their email guards and SeedPoints,
+// skipping those already stored. It returns how many items it wrote to each
+// table.
+func Seed(tables []schema.Table, dynaClient dynamodbiface.DynamoDBAPI) (map[string]int, error) {
+	names := map[string]string{}
+	for _, table := range tables {
+		names[table.Param] = table.Name
+	}
+	userTable, emailsTable := names[utility.ParamUserTable], names[utility.ParamEmailsTable]
+	seeded := map[string]int{}
+
+	for _, role := range SeedRoles {
+		written, err := putIfAbsent(role, "role", names[utility.ParamRolesTable], dynaClient)
+		if err != nil {
+			return seeded, err
+		}
+		if written {
+			seeded[names[utility.ParamRolesTable]]++
+		}
+	}
+
+	for _, user := range SeedUsers {
+		_, err := utility.FetchUserByID(user.User_ID, userTable, dynaClient)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, types.ErrorUserDoesNotExist) {
+			return seeded, err
+		}
+		if err := utility.PutUserItem(user, nil, userTable, emailsTable, dynaClient); err != nil {
+			return seeded, fmt.Errorf("user %s: %w", user.Email, err)
+		}
+		seeded[userTable]++
+		seeded[emailsTable]++
+	}
+
+	for _, point := range SeedPoints {
+		written, err := putIfAbsent(point, "points_id", names[utility.ParamPointsTable], dynaClient)
+		if err != nil {
+			return seeded, err
+		}
+		if written {
+			seeded[names[utility.ParamPointsTable]]++
+		}
+	}
+
+	return seeded, nil
+}
+
+// putIfAbsent stores the item unless one with its key exists, reporting
+// whether it was written. keyAttribute is any attribute of the key.
+func putIfAbsent(item interface{}, keyAttribute string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (bool, error) {
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return false, types.ErrorCouldNotMarshalItem
+	}
+
+	_, err = dynaClient.PutItem(&dynamodb.PutItemInput{
+		Item:                     av,
+		TableName:                aws.String(tableName),
+		ConditionExpression:      aws.String("attribute_not_exists(#key)"),
+		ExpressionAttributeNames: map[string]*string{"#key": aws.String(keyAttribute)},
+	})
+	var exists *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &exists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
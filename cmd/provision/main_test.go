@@ -0,0 +1,136 @@
+package main
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/schema"
+	"ascenda/types"
+	"ascenda/utility"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestEnsureTable(t *testing.T) {
+	db := dynamotest.New()
+
+	for _, table := range schema.Tables {
+		report, err := EnsureTable(table, db)
+		if err != nil {
+			t.Fatalf("EnsureTable(%s) error = %v", table.Name, err)
+		}
+		if !report.Created || len(report.Indexes) != 0 || report.TTLEnabled != (table.TTL != "") {
+			t.Errorf("first report = %+v", report)
+		}
+
+		result, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(table.Name)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Table.GlobalSecondaryIndexes) != len(table.Indexes) {
+			t.Errorf("%s has %d indexes, want %d", table.Name, len(result.Table.GlobalSecondaryIndexes), len(table.Indexes))
+		}
+
+		report, err = EnsureTable(table, db)
+		if err != nil {
+			t.Fatalf("second EnsureTable(%s) error = %v", table.Name, err)
+		}
+		if report.Created || len(report.Indexes) != 0 || report.TTLEnabled {
+			t.Errorf("second report = %+v, want no changes", report)
+		}
+	}
+}
+
+func TestEnsureTableAddsMissingIndexes(t *testing.T) {
+	users := schema.Tables[0]
+	db := dynamotest.New(schema.Table{Name: users.Name, Key: users.Key})
+
+	report, err := EnsureTable(users, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created || len(report.Indexes) != 1 || report.Indexes[0] != "role-index" {
+		t.Fatalf("report = %+v, want role-index created", report)
+	}
+
+	//the new index must be queryable
+	db.Seed(t, users.Name, types.User{User_ID: "1", Role: "admin"})
+	result, err := db.Query(&dynamodb.QueryInput{
+		TableName:                 aws.String(users.Name),
+		IndexName:                 aws.String("role-index"),
+		KeyConditionExpression:    aws.String("#role = :role"),
+		ExpressionAttributeNames:  map[string]*string{"#role": aws.String("role")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":role": {S: aws.String("admin")}},
+	})
+	if err != nil || len(result.Items) != 1 {
+		t.Fatalf("Query() = %v, %v", result, err)
+	}
+}
+
+func TestEnsureTableFails(t *testing.T) {
+	failure := errors.New("throttled")
+	db := dynamotest.New()
+	db.Fail("CreateTable", failure)
+
+	if _, err := EnsureTable(schema.Tables[0], db); !errors.Is(err, failure) {
+		t.Fatalf("EnsureTable() error = %v, want %v", err, failure)
+	}
+}
+
+func TestNamedTables(t *testing.T) {
+	config := utility.StaticConfig{UserTable: "prod-users", EmailsTable: "prod-emails", PointsTable: "prod-points",
+		MakerTable: "prod-makers", RolesTable: "prod-roles", LogsTable: "prod-logs", SessionsTable: "prod-sessions",
+		ImportJobsTable: "prod-import-jobs", MigrationsTable: "prod-migrations",
+		DriftsTable: "prod-drifts"}
+
+	tables, err := NamedTables(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, table := range tables {
+		if table.Name != "prod-"+schema.Tables[i].Name {
+			t.Errorf("table %d = %s, want prod-%s", i, table.Name, schema.Tables[i].Name)
+		}
+	}
+
+	config.LogsTable = ""
+	if _, err := NamedTables(config); !errors.Is(err, types.ErrorLoadingConfig) {
+		t.Errorf("NamedTables() error = %v, want %v", err, types.ErrorLoadingConfig)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+
+	seeded, err := Seed(schema.Tables, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"roles": 2, "users": 3, "emails": 3, "points": 2}
+	for table, count := range want {
+		if seeded[table] != count {
+			t.Errorf("seeded %d items in %s, want %d", seeded[table], table, count)
+		}
+	}
+
+	user, err := utility.FetchUserByID(SeedUsers[0].User_ID, "users", db)
+	if err != nil || user.Role != "admin" {
+		t.Fatalf("FetchUserByID() = %+v, %v", user, err)
+	}
+	var roles []types.Role
+	db.Load(t, "roles", &roles)
+	for _, role := range roles {
+		if role.Role == "admin" && (len(role.Access["*"]) != 1 || role.Access["*"][0] != "*") {
+			t.Errorf("admin access = %v, want full access", role.Access)
+		}
+	}
+
+	seeded, err = Seed(schema.Tables, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seeded) != 0 {
+		t.Errorf("second seed wrote %v, want nothing", seeded)
+	}
+}
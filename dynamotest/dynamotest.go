@@ -0,0 +1,944 @@
+// Package dynamotest provides an in-memory DynamoDB implementing
+// dynamodbiface.DynamoDBAPI, so handlers and the utility functions can be
+// tested against stored items rather than canned responses.
+//
+// It supports GetItem, PutItem, UpdateItem, DeleteItem, Query, parallel Scan,
+// BatchWriteItem and TransactWriteItems with key, condition, filter, update
+// and projection expressions, and fails like dynamo does on conditional
+// checks, cancelled transactions and malformed requests. Tables can be
+// created, described, given new indexes and a TTL attribute. Other operations
+// panic.
+package dynamotest
+
+import (
+	"ascenda/schema"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Tables are the tables of the deployment, under their development names
+// such as users.
+var Tables = schema.Tables
+
+type table struct {
+	schema.Table
+	items map[string]Item
+}
+
+// DB is an in-memory DynamoDB. It is safe for concurrent use.
+type DB struct {
+	dynamodbiface.DynamoDBAPI
+
+	mu       sync.Mutex
+	tables   map[string]*table
+	failures map[string]error
+}
+
+// New returns a DB holding the tables, empty.
+func New(tables ...schema.Table) *DB {
+	db := &DB{
+		tables:   map[string]*table{},
+		failures: map[string]error{},
+	}
+	for _, t := range tables {
+		//indexes may be added, which must not change the shared definition
+		indexes := map[string]schema.Key{}
+		for name, key := range t.Indexes {
+			indexes[name] = key
+		}
+		t.Indexes = indexes
+		db.tables[t.Name] = &table{Table: t, items: map[string]Item{}}
+	}
+	return db
+}
+
+// Fail makes every call of the operation, such as "PutItem", return err
+// instead of running. A nil err clears the failure.
+func (db *DB) Fail(operation string, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err == nil {
+		delete(db.failures, operation)
+		return
+	}
+	db.failures[operation] = err
+}
+
+// Seed marshals the items with dynamodbattribute and stores them in the
+// table, failing the test if one cannot be stored.
+func (db *DB) Seed(t testing.TB, tableName string, items ...interface{}) {
+	t.Helper()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, item := range items {
+		av, ok := item.(Item)
+		if !ok {
+			var err error
+			if av, err = dynamodbattribute.MarshalMap(item); err != nil {
+				t.Fatalf("dynamotest: marshal %T: %v", item, err)
+			}
+		}
+		w, err := db.put(tableName, av)
+		if err == nil {
+			err = w.run()
+		}
+		if err != nil {
+			t.Fatalf("dynamotest: seed %s: %v", tableName, err)
+		}
+	}
+}
+
+// Items returns copies of the items of the table in key order.
+func (db *DB) Items(tableName string) []Item {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t, ok := db.tables[tableName]
+	if !ok {
+		return nil
+	}
+	items := t.sorted(t.Key)
+	for i, item := range items {
+		items[i] = cloneItem(item)
+	}
+	return items
+}
+
+// Load unmarshals the items of the table in key order into out, a pointer to
+// a slice, failing the test if they do not fit.
+func (db *DB) Load(t testing.TB, tableName string, out interface{}) {
+	t.Helper()
+	if err := dynamodbattribute.UnmarshalListOfMaps(db.Items(tableName), out); err != nil {
+		t.Fatalf("dynamotest: unmarshal %s: %v", tableName, err)
+	}
+}
+
+func validationError(format string, args ...interface{}) error {
+	return awserr.New("ValidationException", fmt.Sprintf(format, args...), nil)
+}
+
+func conditionalCheckFailed() error {
+	return &dynamodb.ConditionalCheckFailedException{Message_: aws.String("The conditional request failed")}
+}
+
+func (db *DB) table(name *string) (*table, error) {
+	t, ok := db.tables[aws.StringValue(name)]
+	if !ok {
+		return nil, &dynamodb.ResourceNotFoundException{Message_: aws.String("Requested resource not found")}
+	}
+	return t, nil
+}
+
+// encodeKey returns the identity of the item under schema, failing when a
+// key attribute is missing or not a string, number or binary.
+func encodeKey(schema schema.Key, item Item) (string, error) {
+	var parts []string
+	for _, attribute := range schema.Attributes() {
+		value := item[attribute]
+		switch typeOf(value) {
+		case "S":
+			if *value.S == "" {
+				return "", validationError("The AttributeValue for a key attribute cannot contain an empty string value. Key: %s", attribute)
+			}
+			parts = append(parts, "S"+*value.S)
+		case "N":
+			n, ok := number(*value.N)
+			if !ok {
+				return "", validationError("The parameter cannot be converted to a numeric value: %s", *value.N)
+			}
+			parts = append(parts, "N"+n.Text('g', -1))
+		case "B":
+			parts = append(parts, "B"+string(value.B))
+		case "":
+			return "", validationError("One of the required keys was not given a value: %s", attribute)
+		default:
+			return "", validationError("The key attribute %s must be of type S, N or B", attribute)
+		}
+	}
+	return strings.Join(parts, "\x00"), nil
+}
+
+// key returns the identity of the item in the table, failing unless key
+// holds exactly the key attributes.
+func (t *table) key(key Item) (string, error) {
+	if len(key) != len(t.Key.Attributes()) {
+		return "", validationError("The provided key element does not match the schema")
+	}
+	return encodeKey(t.Key, key)
+}
+
+func (t *table) keyOf(item Item, schemas ...schema.Key) Item {
+	key := Item{}
+	for _, schema := range append([]schema.Key{t.Key}, schemas...) {
+		for _, attribute := range schema.Attributes() {
+			key[attribute] = cloneValue(item[attribute])
+		}
+	}
+	return key
+}
+
+// order compares items by the schema, then by the table key so items of an
+// index sharing its key keep a stable order.
+func (t *table) order(schema schema.Key, a, b Item) int {
+	for _, attribute := range append(schema.Attributes(), t.Key.Attributes()...) {
+		if order, _ := compare(a[attribute], b[attribute]); order != 0 {
+			return order
+		}
+	}
+	return 0
+}
+
+// sorted returns the items holding the schema's key attributes in order.
+func (t *table) sorted(schema schema.Key) []Item {
+	var items []Item
+	for _, item := range t.items {
+		if _, err := encodeKey(schema, item); err == nil {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return t.order(schema, items[i], items[j]) < 0
+	})
+	return items
+}
+
+// write is a prepared change to one item, committed once every condition of
+// the request holds.
+type write struct {
+	table     *table
+	key       string
+	condition *condition
+	// change returns the item to store, nil to delete it. It is nil for
+	// condition checks.
+	change  func(current Item) (Item, error)
+	updated Item
+}
+
+func (w *write) current() Item {
+	return w.table.items[w.key]
+}
+
+// check evaluates the condition against the stored item and computes the
+// change, reporting false when the condition fails.
+func (w *write) check() (bool, error) {
+	if w.condition != nil {
+		current := w.current()
+		if current == nil {
+			current = Item{}
+		}
+		ok, err := w.condition.eval(current)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	if w.change != nil {
+		updated, err := w.change(cloneItem(w.current()))
+		if err != nil {
+			return false, err
+		}
+		w.updated = updated
+	}
+	return true, nil
+}
+
+func (w *write) commit() {
+	switch {
+	case w.change == nil:
+	case w.updated == nil:
+		delete(w.table.items, w.key)
+	default:
+		w.table.items[w.key] = w.updated
+	}
+}
+
+// run checks and commits a single write.
+func (w *write) run() error {
+	ok, err := w.check()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return conditionalCheckFailed()
+	}
+	w.commit()
+	return nil
+}
+
+func prepareCondition(expression *string, ctx *expressionContext) (*condition, error) {
+	if expression == nil {
+		return nil, nil
+	}
+	return parseCondition(*expression, ctx)
+}
+
+func (db *DB) put(tableName string, item Item) (*write, error) {
+	t, err := db.table(aws.String(tableName))
+	if err != nil {
+		return nil, err
+	}
+	key, err := encodeKey(t.Key, item)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range item {
+		if typeOf(value) == "" {
+			return nil, validationError("Supplied AttributeValue is empty, must contain exactly one of the supported datatypes: %s", name)
+		}
+	}
+	stored := cloneItem(item)
+	return &write{table: t, key: key, change: func(Item) (Item, error) {
+		return stored, nil
+	}}, nil
+}
+
+func (db *DB) preparePut(tableName *string, item Item, conditionExpression *string, names map[string]*string,
+	values map[string]*dynamodb.AttributeValue) (*write, error) {
+	w, err := db.put(aws.StringValue(tableName), item)
+	if err != nil {
+		return nil, err
+	}
+	ctx := newExpressionContext(names, values)
+	if w.condition, err = prepareCondition(conditionExpression, ctx); err != nil {
+		return nil, err
+	}
+	return w, ctx.unused()
+}
+
+func (db *DB) prepareDelete(tableName *string, key Item, conditionExpression *string, names map[string]*string,
+	values map[string]*dynamodb.AttributeValue) (*write, error) {
+	t, err := db.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+	id, err := t.key(key)
+	if err != nil {
+		return nil, err
+	}
+	ctx := newExpressionContext(names, values)
+	c, err := prepareCondition(conditionExpression, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &write{table: t, key: id, condition: c, change: func(Item) (Item, error) {
+		return nil, nil
+	}}, ctx.unused()
+}
+
+func (db *DB) prepareUpdate(tableName *string, key Item, updateExpression, conditionExpression *string,
+	names map[string]*string, values map[string]*dynamodb.AttributeValue) (*write, error) {
+	t, err := db.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+	id, err := t.key(key)
+	if err != nil {
+		return nil, err
+	}
+	if updateExpression == nil {
+		return nil, validationError("UpdateExpression is required")
+	}
+	ctx := newExpressionContext(names, values)
+	actions, err := parseUpdate(*updateExpression, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range actions {
+		for _, attribute := range t.Key.Attributes() {
+			if action.path[0].name == attribute {
+				return nil, validationError("Cannot update attribute %s. This attribute is part of the key", attribute)
+			}
+		}
+	}
+	c, err := prepareCondition(conditionExpression, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := cloneItem(key)
+	return &write{table: t, key: id, condition: c, change: func(current Item) (Item, error) {
+		if current == nil {
+			current = stored
+		}
+		for _, action := range actions {
+			if err := action.apply(current); err != nil {
+				return nil, err
+			}
+		}
+		return current, nil
+	}}, ctx.unused()
+}
+
+func (db *DB) prepareConditionCheck(tableName *string, key Item, conditionExpression *string, names map[string]*string,
+	values map[string]*dynamodb.AttributeValue) (*write, error) {
+	w, err := db.prepareDelete(tableName, key, conditionExpression, names, values)
+	if err != nil {
+		return nil, err
+	}
+	if w.condition == nil {
+		return nil, validationError("ConditionExpression is required for ConditionCheck")
+	}
+	w.change = nil
+	return w, nil
+}
+
+// oldValues returns the item as it was before a write when asked for.
+func oldValues(returnValues *string, current Item) Item {
+	if aws.StringValue(returnValues) == dynamodb.ReturnValueAllOld {
+		return cloneItem(current)
+	}
+	return nil
+}
+
+func project(item Item, projection *string, ctx *expressionContext) (Item, error) {
+	if projection == nil || item == nil {
+		return item, nil
+	}
+	paths, err := parseProjection(*projection, ctx)
+	if err != nil {
+		return nil, err
+	}
+	projected := Item{}
+	for _, p := range paths {
+		// only top level attributes are kept whole; nested paths keep the
+		// attribute they belong to
+		if value := item[p[0].name]; value != nil {
+			projected[p[0].name] = value
+		}
+	}
+	return projected, nil
+}
+
+func (db *DB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["GetItem"]; err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := t.key(input.Key)
+	if err != nil {
+		return nil, err
+	}
+	ctx := newExpressionContext(input.ExpressionAttributeNames, nil)
+	item, err := project(cloneItem(t.items[key]), input.ProjectionExpression, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.GetItemOutput{Item: item}, ctx.unused()
+}
+
+func (db *DB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["PutItem"]; err != nil {
+		return nil, err
+	}
+
+	w, err := db.preparePut(input.TableName, input.Item, input.ConditionExpression, input.ExpressionAttributeNames,
+		input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	old := oldValues(input.ReturnValues, w.current())
+	if err := w.run(); err != nil {
+		return nil, err
+	}
+	return &dynamodb.PutItemOutput{Attributes: old}, nil
+}
+
+func (db *DB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["UpdateItem"]; err != nil {
+		return nil, err
+	}
+
+	w, err := db.prepareUpdate(input.TableName, input.Key, input.UpdateExpression, input.ConditionExpression,
+		input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	old := oldValues(input.ReturnValues, w.current())
+	if err := w.run(); err != nil {
+		return nil, err
+	}
+	if aws.StringValue(input.ReturnValues) == dynamodb.ReturnValueAllNew {
+		return &dynamodb.UpdateItemOutput{Attributes: cloneItem(w.updated)}, nil
+	}
+	return &dynamodb.UpdateItemOutput{Attributes: old}, nil
+}
+
+func (db *DB) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["DeleteItem"]; err != nil {
+		return nil, err
+	}
+
+	w, err := db.prepareDelete(input.TableName, input.Key, input.ConditionExpression, input.ExpressionAttributeNames,
+		input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	old := oldValues(input.ReturnValues, w.current())
+	if err := w.run(); err != nil {
+		return nil, err
+	}
+	return &dynamodb.DeleteItemOutput{Attributes: old}, nil
+}
+
+// page is the part of a query or scan common to both.
+type page struct {
+	table             *table
+	schema            schema.Key
+	indexes           []schema.Key
+	items             []Item
+	reverse           bool
+	exclusiveStartKey Item
+	limit             *int64
+	filter            *condition
+	projection        *string
+	ctx               *expressionContext
+}
+
+func (db *DB) newPage(tableName, indexName *string, names map[string]*string,
+	values map[string]*dynamodb.AttributeValue) (*page, error) {
+	t, err := db.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+	p := &page{table: t, schema: t.Key, ctx: newExpressionContext(names, values)}
+	if indexName != nil {
+		index, ok := t.Indexes[*indexName]
+		if !ok {
+			return nil, validationError("The table does not have the specified index: %s", *indexName)
+		}
+		p.schema = index
+		p.indexes = []schema.Key{index}
+	}
+	return p, nil
+}
+
+// run evaluates up to limit items after the exclusive start key, returning
+// those passing the filter and the key to continue from.
+func (p *page) run() (items []Item, scanned int64, lastEvaluatedKey Item, err error) {
+	if p.limit != nil && *p.limit <= 0 {
+		return nil, 0, nil, validationError("Limit must be greater than or equal to 1")
+	}
+	if err := p.ctx.unused(); err != nil {
+		return nil, 0, nil, err
+	}
+
+	order := func(a, b Item) int {
+		if p.reverse {
+			return -p.table.order(p.schema, a, b)
+		}
+		return p.table.order(p.schema, a, b)
+	}
+	start := 0
+	if p.exclusiveStartKey != nil {
+		start = sort.Search(len(p.items), func(i int) bool {
+			return order(p.items[i], p.exclusiveStartKey) > 0
+		})
+	}
+
+	items = []Item{}
+	for _, item := range p.items[start:] {
+		scanned++
+		ok := true
+		if p.filter != nil {
+			if ok, err = p.filter.eval(item); err != nil {
+				return nil, 0, nil, err
+			}
+		}
+		if ok {
+			projected, err := project(cloneItem(item), p.projection, p.ctx)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			items = append(items, projected)
+		}
+		if p.limit != nil && scanned == *p.limit {
+			lastEvaluatedKey = p.table.keyOf(item, p.indexes...)
+			break
+		}
+	}
+	return items, scanned, lastEvaluatedKey, nil
+}
+
+func (db *DB) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["Query"]; err != nil {
+		return nil, err
+	}
+
+	p, err := db.newPage(input.TableName, input.IndexName, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	var keyCondition *condition
+	switch {
+	case input.KeyConditionExpression != nil:
+		keyCondition, err = parseCondition(*input.KeyConditionExpression, p.ctx)
+	case len(input.KeyConditions) > 0:
+		keyCondition, err = legacyKeyConditions(input.KeyConditions)
+	default:
+		err = validationError("Either the KeyConditions or KeyConditionExpression parameter must be specified in the request")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKeyCondition(keyCondition, p.schema); err != nil {
+		return nil, err
+	}
+	if p.filter, err = prepareCondition(input.FilterExpression, p.ctx); err != nil {
+		return nil, err
+	}
+	p.projection = input.ProjectionExpression
+	p.reverse = input.ScanIndexForward != nil && !*input.ScanIndexForward
+	p.exclusiveStartKey = input.ExclusiveStartKey
+	p.limit = input.Limit
+
+	for _, item := range p.table.sorted(p.schema) {
+		ok, err := keyCondition.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			p.items = append(p.items, item)
+		}
+	}
+	if p.reverse {
+		for i, j := 0, len(p.items)-1; i < j; i, j = i+1, j-1 {
+			p.items[i], p.items[j] = p.items[j], p.items[i]
+		}
+	}
+
+	items, scanned, lastEvaluatedKey, err := p.run()
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.QueryOutput{
+		Items:            items,
+		Count:            aws.Int64(int64(len(items))),
+		ScannedCount:     aws.Int64(scanned),
+		LastEvaluatedKey: lastEvaluatedKey,
+	}, nil
+}
+
+// legacyOperators maps the comparison operators of the legacy KeyConditions
+// parameter to their expression equivalents.
+var legacyOperators = map[string]string{
+	dynamodb.ComparisonOperatorEq:         "=",
+	dynamodb.ComparisonOperatorLt:         "<",
+	dynamodb.ComparisonOperatorLe:         "<=",
+	dynamodb.ComparisonOperatorGt:         ">",
+	dynamodb.ComparisonOperatorGe:         ">=",
+	dynamodb.ComparisonOperatorBetween:    "BETWEEN",
+	dynamodb.ComparisonOperatorBeginsWith: "begins_with",
+}
+
+// legacyKeyConditions converts KeyConditions to the condition a key condition
+// expression would parse to.
+func legacyKeyConditions(conditions map[string]*dynamodb.Condition) (*condition, error) {
+	attributes := make([]string, 0, len(conditions))
+	for attribute := range conditions {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+
+	var result *condition
+	for _, attribute := range attributes {
+		legacy := conditions[attribute]
+		op, ok := legacyOperators[aws.StringValue(legacy.ComparisonOperator)]
+		if !ok {
+			return nil, validationError("Unsupported operator on KeyConditions: %s", aws.StringValue(legacy.ComparisonOperator))
+		}
+		want := 1
+		if op == "BETWEEN" {
+			want = 2
+		}
+		if len(legacy.AttributeValueList) != want {
+			return nil, validationError("Invalid number of argument(s) for the %s ComparisonOperator", *legacy.ComparisonOperator)
+		}
+
+		c := &condition{op: op, operands: []operand{pathOperand{{name: attribute}}}}
+		for _, value := range legacy.AttributeValueList {
+			c.operands = append(c.operands, valueOperand{value})
+		}
+		if result == nil {
+			result = c
+		} else {
+			result = &condition{op: "AND", children: []*condition{result, c}}
+		}
+	}
+	return result, nil
+}
+
+// checkKeyCondition requires an equality on the hash key, optionally joined
+// with one condition on the range key, as dynamo does.
+func checkKeyCondition(c *condition, schema schema.Key) error {
+	conditions := []*condition{c}
+	if c.op == "AND" {
+		conditions = c.children
+	}
+	if len(conditions) > 2 {
+		return validationError("Conditions can be of length 1 or 2 only")
+	}
+
+	var hash bool
+	for _, c := range conditions {
+		if len(c.operands) == 0 {
+			return validationError("Invalid operator used in KeyConditionExpression: %s", c.op)
+		}
+		attribute, ok := c.operands[0].(pathOperand)
+		if !ok || len(attribute) != 1 {
+			return validationError("Invalid KeyConditionExpression: key conditions must name a key attribute")
+		}
+		switch name := attribute[0].name; {
+		case name == schema.Hash && c.op == "=":
+			hash = true
+		case name == schema.Range && schema.Range != "":
+			switch c.op {
+			case "=", "<", "<=", ">", ">=", "BETWEEN", "begins_with":
+			default:
+				return validationError("Invalid operator used in KeyConditionExpression: %s", c.op)
+			}
+		default:
+			return validationError("Query key condition not supported: %s is not a key of the table or index", name)
+		}
+	}
+	if !hash {
+		return validationError("Query condition missed key schema element: %s", schema.Hash)
+	}
+	return nil
+}
+
+func (db *DB) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["Scan"]; err != nil {
+		return nil, err
+	}
+
+	p, err := db.newPage(input.TableName, input.IndexName, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	if p.filter, err = prepareCondition(input.FilterExpression, p.ctx); err != nil {
+		return nil, err
+	}
+	p.projection = input.ProjectionExpression
+	p.exclusiveStartKey = input.ExclusiveStartKey
+	p.limit = input.Limit
+	if p.items, err = segment(p.table.sorted(p.schema), p.schema, input.Segment, input.TotalSegments); err != nil {
+		return nil, err
+	}
+
+	items, scanned, lastEvaluatedKey, err := p.run()
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.ScanOutput{
+		Items:            items,
+		Count:            aws.Int64(int64(len(items))),
+		ScannedCount:     aws.Int64(scanned),
+		LastEvaluatedKey: lastEvaluatedKey,
+	}, nil
+}
+
+// segment keeps the items of one segment of a parallel scan, dividing them by
+// the hash of their partition key as dynamo does. Without segments every item
+// is kept.
+func segment(items []Item, key schema.Key, segment, totalSegments *int64) ([]Item, error) {
+	if segment == nil && totalSegments == nil {
+		return items, nil
+	}
+	if segment == nil || totalSegments == nil {
+		return nil, validationError("The TotalSegments parameter is required when Segment is present, and the reverse")
+	}
+	if *totalSegments < 1 || *totalSegments > 1000000 {
+		return nil, validationError("Value at 'totalSegments' failed to satisfy constraint: Member must be between 1 and 1000000")
+	}
+	if *segment < 0 || *segment >= *totalSegments {
+		return nil, validationError("The Segment parameter is zero-based and must be less than parameter TotalSegments: Segment: %d is not less than TotalSegments: %d",
+			*segment, *totalSegments)
+	}
+
+	kept := []Item{}
+	for _, item := range items {
+		partition, _ := encodeKey(schema.Key{Hash: key.Hash}, item)
+		hash := fnv.New32a()
+		hash.Write([]byte(partition))
+		if int64(hash.Sum32())%*totalSegments == *segment {
+			kept = append(kept, item)
+		}
+	}
+	return kept, nil
+}
+
+// maxBatchWrites and maxTransactWrites are the most items dynamo accepts in
+// one request.
+const (
+	maxBatchWrites    = 25
+	maxTransactWrites = 100
+)
+
+func (db *DB) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["BatchWriteItem"]; err != nil {
+		return nil, err
+	}
+
+	var writes []*write
+	for tableName, requests := range input.RequestItems {
+		for _, request := range requests {
+			var w *write
+			var err error
+			switch {
+			case request == nil:
+				err = validationError("Write request must contain a PutRequest or DeleteRequest")
+			case request.PutRequest != nil:
+				w, err = db.preparePut(aws.String(tableName), request.PutRequest.Item, nil, nil, nil)
+			case request.DeleteRequest != nil:
+				w, err = db.prepareDelete(aws.String(tableName), request.DeleteRequest.Key, nil, nil, nil)
+			default:
+				err = validationError("Write request must contain a PutRequest or DeleteRequest")
+			}
+			if err != nil {
+				return nil, err
+			}
+			writes = append(writes, w)
+		}
+	}
+	if len(writes) == 0 || len(writes) > maxBatchWrites {
+		return nil, validationError("Too many items requested for the BatchWriteItem call")
+	}
+	if err := distinct(writes, "Provided list of item keys contains duplicates"); err != nil {
+		return nil, err
+	}
+
+	for _, w := range writes {
+		if err := w.run(); err != nil {
+			return nil, err
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}, nil
+}
+
+func distinct(writes []*write, message string) error {
+	seen := map[string]bool{}
+	for _, w := range writes {
+		id := w.table.Name + "\x00" + w.key
+		if seen[id] {
+			return validationError(message)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func (db *DB) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["TransactWriteItems"]; err != nil {
+		return nil, err
+	}
+	if len(input.TransactItems) == 0 || len(input.TransactItems) > maxTransactWrites {
+		return nil, validationError("Member must have length between 1 and %d", maxTransactWrites)
+	}
+
+	writes := make([]*write, len(input.TransactItems))
+	for i, item := range input.TransactItems {
+		var err error
+		switch {
+		case item.Put != nil:
+			writes[i], err = db.preparePut(item.Put.TableName, item.Put.Item, item.Put.ConditionExpression,
+				item.Put.ExpressionAttributeNames, item.Put.ExpressionAttributeValues)
+		case item.Update != nil:
+			writes[i], err = db.prepareUpdate(item.Update.TableName, item.Update.Key, item.Update.UpdateExpression,
+				item.Update.ConditionExpression, item.Update.ExpressionAttributeNames, item.Update.ExpressionAttributeValues)
+		case item.Delete != nil:
+			writes[i], err = db.prepareDelete(item.Delete.TableName, item.Delete.Key, item.Delete.ConditionExpression,
+				item.Delete.ExpressionAttributeNames, item.Delete.ExpressionAttributeValues)
+		case item.ConditionCheck != nil:
+			writes[i], err = db.prepareConditionCheck(item.ConditionCheck.TableName, item.ConditionCheck.Key,
+				item.ConditionCheck.ConditionExpression, item.ConditionCheck.ExpressionAttributeNames,
+				item.ConditionCheck.ExpressionAttributeValues)
+		default:
+			err = validationError("TransactItems can only contain one of Check, Put, Update or Delete")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := distinct(writes, "Transaction request cannot include multiple operations on one item"); err != nil {
+		return nil, err
+	}
+
+	reasons := make([]*dynamodb.CancellationReason, len(writes))
+	var cancelled []string
+	for i, w := range writes {
+		ok, err := w.check()
+		if err != nil {
+			return nil, err
+		}
+		code := "None"
+		if !ok {
+			code = "ConditionalCheckFailed"
+			reasons[i] = &dynamodb.CancellationReason{Code: aws.String(code), Message: aws.String("The conditional request failed")}
+		} else {
+			reasons[i] = &dynamodb.CancellationReason{Code: aws.String(code)}
+		}
+		cancelled = append(cancelled, code)
+	}
+	for _, reason := range reasons {
+		if aws.StringValue(reason.Code) != "None" {
+			return nil, &dynamodb.TransactionCanceledException{
+				Message_: aws.String(fmt.Sprintf("Transaction cancelled, please refer cancellation reasons for specific reasons [%s]",
+					strings.Join(cancelled, ", "))),
+				CancellationReasons: reasons,
+			}
+		}
+	}
+
+	for _, w := range writes {
+		w.commit()
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// String describes the stored items as json, for test failure messages.
+func (db *DB) String() string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tables := map[string][]map[string]interface{}{}
+	for name, t := range db.tables {
+		var items []map[string]interface{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(t.sorted(t.Key), &items); err != nil {
+			return err.Error()
+		}
+		tables[name] = items
+	}
+	b, _ := json.MarshalIndent(tables, "", "  ")
+	return string(b)
+}
@@ -0,0 +1,296 @@
+package dynamotest
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type user struct {
+	User_ID string `json:"user_id"`
+	Role    string `json:"role"`
+	Status  string `json:"status,omitempty"`
+}
+
+type event struct {
+	User_ID   string `json:"user_id"`
+	Timestamp int64  `json:"timestamp"`
+	Flagged   bool   `json:"flags,omitempty"`
+}
+
+func s(value string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{S: aws.String(value)}
+}
+
+func code(err error) string {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code()
+	}
+	return ""
+}
+
+func TestConditionalWrites(t *testing.T) {
+	db := New(Tables...)
+	db.Seed(t, "users", user{User_ID: "1", Role: "admin"})
+
+	tests := []struct {
+		name     string
+		run      func() error
+		wantCode string
+	}{
+		{"put new item", func() error {
+			_, err := db.PutItem(&dynamodb.PutItemInput{
+				TableName:           aws.String("users"),
+				Item:                Item{"user_id": s("2"), "role": s("customer")},
+				ConditionExpression: aws.String("attribute_not_exists(user_id)"),
+			})
+			return err
+		}, ""},
+		{"put existing item", func() error {
+			_, err := db.PutItem(&dynamodb.PutItemInput{
+				TableName:           aws.String("users"),
+				Item:                Item{"user_id": s("1"), "role": s("customer")},
+				ConditionExpression: aws.String("attribute_not_exists(user_id)"),
+			})
+			return err
+		}, dynamodb.ErrCodeConditionalCheckFailedException},
+		{"update with status", func() error {
+			_, err := db.UpdateItem(&dynamodb.UpdateItemInput{
+				TableName:                 aws.String("users"),
+				Key:                       Item{"user_id": s("1")},
+				UpdateExpression:          aws.String("SET #status = :status"),
+				ConditionExpression:       aws.String("attribute_not_exists(#status) OR #status <> :status"),
+				ExpressionAttributeNames:  map[string]*string{"#status": aws.String("status")},
+				ExpressionAttributeValues: Item{":status": s("deleted")},
+			})
+			return err
+		}, ""},
+		{"update already deleted", func() error {
+			_, err := db.UpdateItem(&dynamodb.UpdateItemInput{
+				TableName:                 aws.String("users"),
+				Key:                       Item{"user_id": s("1")},
+				UpdateExpression:          aws.String("SET #status = :status"),
+				ConditionExpression:       aws.String("attribute_not_exists(#status) OR #status <> :status"),
+				ExpressionAttributeNames:  map[string]*string{"#status": aws.String("status")},
+				ExpressionAttributeValues: Item{":status": s("deleted")},
+			})
+			return err
+		}, dynamodb.ErrCodeConditionalCheckFailedException},
+		{"update key attribute", func() error {
+			_, err := db.UpdateItem(&dynamodb.UpdateItemInput{
+				TableName:                 aws.String("users"),
+				Key:                       Item{"user_id": s("1")},
+				UpdateExpression:          aws.String("SET user_id = :id"),
+				ExpressionAttributeValues: Item{":id": s("3")},
+			})
+			return err
+		}, "ValidationException"},
+		{"unused placeholder", func() error {
+			_, err := db.DeleteItem(&dynamodb.DeleteItemInput{
+				TableName:                 aws.String("users"),
+				Key:                       Item{"user_id": s("2")},
+				ExpressionAttributeValues: Item{":id": s("2")},
+			})
+			return err
+		}, "ValidationException"},
+		{"key of the wrong schema", func() error {
+			_, err := db.GetItem(&dynamodb.GetItemInput{
+				TableName: aws.String("users"),
+				Key:       Item{"email": s("jane@example.com")},
+			})
+			return err
+		}, "ValidationException"},
+		{"missing table", func() error {
+			_, err := db.GetItem(&dynamodb.GetItemInput{
+				TableName: aws.String("nope"),
+				Key:       Item{"user_id": s("1")},
+			})
+			return err
+		}, dynamodb.ErrCodeResourceNotFoundException},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := code(tt.run()); got != tt.wantCode {
+				t.Errorf("error code = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+
+	var users []user
+	db.Load(t, "users", &users)
+	want := []user{{User_ID: "1", Role: "admin", Status: "deleted"}, {User_ID: "2", Role: "customer"}}
+	if !reflect.DeepEqual(users, want) {
+		t.Errorf("users = %+v, want %+v", users, want)
+	}
+}
+
+func TestTransactWriteItemsIsAtomic(t *testing.T) {
+	db := New(Tables...)
+	db.Seed(t, "emails", Item{"email": s("jane@example.com"), "user_id": s("1")})
+
+	_, err := db.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: []*dynamodb.TransactWriteItem{
+		{Put: &dynamodb.Put{
+			TableName:           aws.String("users"),
+			Item:                Item{"user_id": s("2"), "email": s("jane@example.com")},
+			ConditionExpression: aws.String("attribute_not_exists(user_id)"),
+		}},
+		{Put: &dynamodb.Put{
+			TableName:                 aws.String("emails"),
+			Item:                      Item{"email": s("jane@example.com"), "user_id": s("2")},
+			ConditionExpression:       aws.String("attribute_not_exists(email) OR user_id = :user_id"),
+			ExpressionAttributeValues: Item{":user_id": s("2")},
+		}},
+	}})
+
+	var cancelled *dynamodb.TransactionCanceledException
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("TransactWriteItems() error = %v, want cancelled", err)
+	}
+	var reasons []string
+	for _, reason := range cancelled.CancellationReasons {
+		reasons = append(reasons, aws.StringValue(reason.Code))
+	}
+	if want := []string{"None", "ConditionalCheckFailed"}; !reflect.DeepEqual(reasons, want) {
+		t.Errorf("reasons = %v, want %v", reasons, want)
+	}
+	if items := db.Items("users"); len(items) != 0 {
+		t.Errorf("cancelled transaction wrote %v", items)
+	}
+
+	_, err = db.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: []*dynamodb.TransactWriteItem{
+		{Delete: &dynamodb.Delete{TableName: aws.String("emails"), Key: Item{"email": s("jane@example.com")}}},
+		{Put: &dynamodb.Put{TableName: aws.String("emails"), Item: Item{"email": s("jane@example.com")}}},
+	}})
+	if code(err) != "ValidationException" {
+		t.Errorf("two writes to one item error = %v, want ValidationException", err)
+	}
+}
+
+func TestQueryPages(t *testing.T) {
+	db := New(Tables...)
+	for i := 1; i <= 5; i++ {
+		db.Seed(t, "sessions", event{User_ID: "1", Timestamp: int64(i), Flagged: i%2 == 1})
+	}
+	db.Seed(t, "sessions", event{User_ID: "2", Timestamp: 9})
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String("sessions"),
+		KeyConditionExpression:    aws.String("user_id = :user_id"),
+		FilterExpression:          aws.String("attribute_exists(flags)"),
+		ExpressionAttributeValues: Item{":user_id": s("1")},
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int64(2),
+	}
+
+	var pages [][]int64
+	for {
+		result, err := db.Query(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var page []int64
+		for _, item := range result.Items {
+			n, _ := strconv.ParseInt(*item["timestamp"].N, 10, 64)
+			page = append(page, n)
+		}
+		pages = append(pages, page)
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	// limit counts the items read before the filter, newest first
+	want := [][]int64{{5}, {3}, {1}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+}
+
+func TestQueryIndex(t *testing.T) {
+	db := New(Tables...)
+	db.Seed(t, "users", user{User_ID: "1", Role: "admin"}, user{User_ID: "2", Role: "customer"}, user{User_ID: "3", Role: "admin"})
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String("users"),
+		IndexName:                 aws.String("role-index"),
+		KeyConditionExpression:    aws.String("#role = :role"),
+		ExpressionAttributeNames:  map[string]*string{"#role": aws.String("role")},
+		ExpressionAttributeValues: Item{":role": s("admin")},
+		Limit:                     aws.Int64(1),
+	}
+	result, err := db.Query(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Item{"user_id": s("1"), "role": s("admin")}); !reflect.DeepEqual(result.LastEvaluatedKey, want) {
+		t.Errorf("LastEvaluatedKey = %v, want %v", result.LastEvaluatedKey, want)
+	}
+	input.ExclusiveStartKey = result.LastEvaluatedKey
+	if result, err = db.Query(input); err != nil || len(result.Items) != 1 || *result.Items[0]["user_id"].S != "3" {
+		t.Errorf("second page = %v, %v, want user 3", result, err)
+	}
+
+	input.KeyConditionExpression = aws.String("user_id = :role")
+	input.ExpressionAttributeNames = nil
+	if _, err := db.Query(input); code(err) != "ValidationException" {
+		t.Errorf("Query() on a non key attribute error = %v, want ValidationException", err)
+	}
+}
+
+func TestScanSegments(t *testing.T) {
+	db := New(Tables...)
+	for i := 0; i < 20; i++ {
+		db.Seed(t, "users", user{User_ID: strconv.Itoa(i), Role: "customer"})
+	}
+
+	seen := map[string]int{}
+	for segment := int64(0); segment < 3; segment++ {
+		result, err := db.Scan(&dynamodb.ScanInput{
+			TableName:     aws.String("users"),
+			Segment:       aws.Int64(segment),
+			TotalSegments: aws.Int64(3),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range result.Items {
+			seen[*item["user_id"].S]++
+		}
+	}
+	// every item is in exactly one segment
+	if len(seen) != 20 {
+		t.Errorf("segments held %d users, want 20", len(seen))
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Errorf("user %s was in %d segments", id, count)
+		}
+	}
+
+	_, err := db.Scan(&dynamodb.ScanInput{TableName: aws.String("users"), Segment: aws.Int64(3), TotalSegments: aws.Int64(3)})
+	if code(err) != "ValidationException" {
+		t.Errorf("Scan() of segment 3 of 3 error = %v, want ValidationException", err)
+	}
+}
+
+func TestFail(t *testing.T) {
+	db := New(Tables...)
+	throttled := awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil)
+	db.Fail("Scan", throttled)
+
+	if _, err := db.Scan(&dynamodb.ScanInput{TableName: aws.String("users")}); err != throttled {
+		t.Errorf("Scan() error = %v, want %v", err, throttled)
+	}
+	db.Fail("Scan", nil)
+	if _, err := db.Scan(&dynamodb.ScanInput{TableName: aws.String("users")}); err != nil {
+		t.Errorf("Scan() error = %v after clearing the failure", err)
+	}
+}
@@ -0,0 +1,780 @@
+package dynamotest
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// expressionContext resolves the #name and :value placeholders of the
+// expressions of one request, recording which were used since dynamo rejects
+// requests with unused placeholders.
+type expressionContext struct {
+	names  map[string]*string
+	values map[string]*dynamodb.AttributeValue
+	used   map[string]bool
+}
+
+func newExpressionContext(names map[string]*string, values map[string]*dynamodb.AttributeValue) *expressionContext {
+	return &expressionContext{names: names, values: values, used: map[string]bool{}}
+}
+
+// unused fails when a placeholder was given but not used by any expression.
+func (c *expressionContext) unused() error {
+	var unused []string
+	for name := range c.names {
+		if !c.used[name] {
+			unused = append(unused, name)
+		}
+	}
+	for value := range c.values {
+		if !c.used[value] {
+			unused = append(unused, value)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return validationError("Value provided in ExpressionAttributeNames or ExpressionAttributeValues unused in expressions: %s",
+			strings.Join(unused, ", "))
+	}
+	return nil
+}
+
+// pathElement is a map key, or a list index when name is empty.
+type pathElement struct {
+	name  string
+	index int
+}
+
+type path []pathElement
+
+func (p path) get(item Item) *dynamodb.AttributeValue {
+	value := item[p[0].name]
+	for _, element := range p[1:] {
+		if value = child(value, element); value == nil {
+			return nil
+		}
+	}
+	return value
+}
+
+// child returns the element of a map or list value, nil when there is none.
+func child(value *dynamodb.AttributeValue, element pathElement) *dynamodb.AttributeValue {
+	switch {
+	case value == nil:
+		return nil
+	case element.name != "":
+		return value.M[element.name]
+	case element.index < len(value.L):
+		return value.L[element.index]
+	}
+	return nil
+}
+
+// parent returns the map or list holding the last element of the path.
+func (p path) parent(item Item) (*dynamodb.AttributeValue, error) {
+	parent := &dynamodb.AttributeValue{M: item}
+	for _, element := range p[:len(p)-1] {
+		if parent = child(parent, element); parent == nil {
+			return nil, validationError("The document path provided in the update expression is invalid for update")
+		}
+	}
+	return parent, nil
+}
+
+func (p path) set(item Item, value *dynamodb.AttributeValue) error {
+	if len(p) == 1 {
+		item[p[0].name] = value
+		return nil
+	}
+	parent, err := p.parent(item)
+	if err != nil {
+		return err
+	}
+	last := p[len(p)-1]
+	switch {
+	case last.name != "" && parent.M != nil:
+		parent.M[last.name] = value
+	case last.name == "" && parent.L != nil:
+		if last.index >= len(parent.L) {
+			parent.L = append(parent.L, value)
+		} else {
+			parent.L[last.index] = value
+		}
+	default:
+		return validationError("The document path provided in the update expression is invalid for update")
+	}
+	return nil
+}
+
+func (p path) remove(item Item) {
+	if len(p) == 1 {
+		delete(item, p[0].name)
+		return
+	}
+	parent, err := p.parent(item)
+	if err != nil {
+		return
+	}
+	last := p[len(p)-1]
+	switch {
+	case last.name != "" && parent.M != nil:
+		delete(parent.M, last.name)
+	case last.name == "" && last.index < len(parent.L):
+		parent.L = append(parent.L[:last.index], parent.L[last.index+1:]...)
+	}
+}
+
+// operand is a value of an expression, evaluated against an item.
+type operand interface {
+	eval(item Item) (*dynamodb.AttributeValue, error)
+}
+
+type pathOperand path
+
+func (o pathOperand) eval(item Item) (*dynamodb.AttributeValue, error) {
+	return path(o).get(item), nil
+}
+
+type valueOperand struct {
+	value *dynamodb.AttributeValue
+}
+
+func (o valueOperand) eval(Item) (*dynamodb.AttributeValue, error) {
+	return o.value, nil
+}
+
+type sizeOperand path
+
+func (o sizeOperand) eval(item Item) (*dynamodb.AttributeValue, error) {
+	value := path(o).get(item)
+	var size int
+	switch typeOf(value) {
+	case "S":
+		size = len(*value.S)
+	case "B":
+		size = len(value.B)
+	case "SS":
+		size = len(value.SS)
+	case "NS":
+		size = len(value.NS)
+	case "BS":
+		size = len(value.BS)
+	case "L":
+		size = len(value.L)
+	case "M":
+		size = len(value.M)
+	default:
+		return nil, nil
+	}
+	return &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(size))}, nil
+}
+
+type ifNotExistsOperand struct {
+	path     path
+	fallback operand
+}
+
+func (o ifNotExistsOperand) eval(item Item) (*dynamodb.AttributeValue, error) {
+	if value := o.path.get(item); value != nil {
+		return value, nil
+	}
+	return o.fallback.eval(item)
+}
+
+type listAppendOperand struct {
+	a, b operand
+}
+
+func (o listAppendOperand) eval(item Item) (*dynamodb.AttributeValue, error) {
+	a, err := o.a.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	b, err := o.b.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	if typeOf(a) != "L" || typeOf(b) != "L" {
+		return nil, validationError("Incorrect operand type for operator or function; operator or function: list_append")
+	}
+	list := append(append([]*dynamodb.AttributeValue{}, a.L...), b.L...)
+	return &dynamodb.AttributeValue{L: list}, nil
+}
+
+type arithmeticOperand struct {
+	op   string
+	a, b operand
+}
+
+func (o arithmeticOperand) eval(item Item) (*dynamodb.AttributeValue, error) {
+	a, err := o.a.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	b, err := o.b.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil || b == nil {
+		return nil, validationError("The provided expression refers to an attribute that does not exist in the item")
+	}
+	return addNumbers(a, b, o.op == "-")
+}
+
+func addNumbers(a, b *dynamodb.AttributeValue, subtract bool) (*dynamodb.AttributeValue, error) {
+	if typeOf(a) != "N" || typeOf(b) != "N" {
+		return nil, validationError("An operand in the update expression has an incorrect data type")
+	}
+	x, okX := number(*a.N)
+	y, okY := number(*b.N)
+	if !okX || !okY {
+		return nil, validationError("An operand in the update expression has an incorrect data type")
+	}
+	if subtract {
+		y.Neg(y)
+	}
+	return &dynamodb.AttributeValue{N: aws.String(new(big.Float).Add(x, y).Text('f', -1))}, nil
+}
+
+// condition is a node of a condition, filter or key condition expression.
+// op is a logical operator, comparator or function name.
+type condition struct {
+	op       string
+	children []*condition
+	operands []operand
+}
+
+func (c *condition) eval(item Item) (bool, error) {
+	switch c.op {
+	case "AND", "OR":
+		left, err := c.children[0].eval(item)
+		if err != nil {
+			return false, err
+		}
+		if c.op == "AND" && !left || c.op == "OR" && left {
+			return left, nil
+		}
+		return c.children[1].eval(item)
+	case "NOT":
+		ok, err := c.children[0].eval(item)
+		return !ok, err
+	}
+
+	values := make([]*dynamodb.AttributeValue, len(c.operands))
+	for i, operand := range c.operands {
+		value, err := operand.eval(item)
+		if err != nil {
+			return false, err
+		}
+		values[i] = value
+	}
+
+	switch c.op {
+	case "attribute_exists":
+		return values[0] != nil, nil
+	case "attribute_not_exists":
+		return values[0] == nil, nil
+	case "attribute_type":
+		return values[1] != nil && values[1].S != nil && typeOf(values[0]) == *values[1].S, nil
+	}
+	for _, value := range values {
+		if value == nil {
+			return false, nil
+		}
+	}
+
+	switch c.op {
+	case "=":
+		return equal(values[0], values[1]), nil
+	case "<>":
+		return !equal(values[0], values[1]), nil
+	case "<", "<=", ">", ">=":
+		order, ok := compare(values[0], values[1])
+		return ok && (c.op == "<" && order < 0 || c.op == "<=" && order <= 0 ||
+			c.op == ">" && order > 0 || c.op == ">=" && order >= 0), nil
+	case "BETWEEN":
+		low, okLow := compare(values[0], values[1])
+		high, okHigh := compare(values[0], values[2])
+		return okLow && okHigh && low >= 0 && high <= 0, nil
+	case "IN":
+		for _, value := range values[1:] {
+			if equal(values[0], value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "begins_with":
+		switch {
+		case typeOf(values[0]) == "S" && typeOf(values[1]) == "S":
+			return strings.HasPrefix(*values[0].S, *values[1].S), nil
+		case typeOf(values[0]) == "B" && typeOf(values[1]) == "B":
+			return strings.HasPrefix(string(values[0].B), string(values[1].B)), nil
+		}
+		return false, nil
+	case "contains":
+		return contains(values[0], values[1]), nil
+	}
+	return false, validationError("Invalid operator used in expression: %s", c.op)
+}
+
+func contains(container, value *dynamodb.AttributeValue) bool {
+	switch typeOf(container) {
+	case "S":
+		return value.S != nil && strings.Contains(*container.S, *value.S)
+	case "SS":
+		return value.S != nil && containsString(container.SS, *value.S)
+	case "NS":
+		for _, n := range container.NS {
+			if equal(&dynamodb.AttributeValue{N: n}, value) {
+				return true
+			}
+		}
+	case "L":
+		for _, element := range container.L {
+			if equal(element, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsString(values []*string, value string) bool {
+	for _, v := range values {
+		if aws.StringValue(v) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// updateAction is one action of an update expression.
+type updateAction struct {
+	clause string
+	path   path
+	value  operand
+}
+
+// apply runs the action on item in place.
+func (a updateAction) apply(item Item) error {
+	var value *dynamodb.AttributeValue
+	if a.value != nil {
+		v, err := a.value.eval(item)
+		if err != nil {
+			return err
+		}
+		value = cloneValue(v)
+	}
+
+	switch a.clause {
+	case "SET":
+		if value == nil {
+			return validationError("The provided expression refers to an attribute that does not exist in the item")
+		}
+		return a.path.set(item, value)
+	case "REMOVE":
+		a.path.remove(item)
+		return nil
+	case "ADD":
+		current := a.path.get(item)
+		switch {
+		case current == nil:
+			return a.path.set(item, value)
+		case typeOf(current) == "N":
+			sum, err := addNumbers(current, value, false)
+			if err != nil {
+				return err
+			}
+			return a.path.set(item, sum)
+		case typeOf(current) == "SS" && typeOf(value) == "SS":
+			for _, s := range value.SS {
+				if !containsString(current.SS, *s) {
+					current.SS = append(current.SS, s)
+				}
+			}
+			return nil
+		}
+		return validationError("An operand in the update expression has an incorrect data type")
+	case "DELETE":
+		current := a.path.get(item)
+		if typeOf(current) != "SS" || typeOf(value) != "SS" {
+			return validationError("An operand in the update expression has an incorrect data type")
+		}
+		var kept []*string
+		for _, s := range current.SS {
+			if !containsString(value.SS, *s) {
+				kept = append(kept, s)
+			}
+		}
+		if len(kept) == 0 {
+			a.path.remove(item)
+		} else {
+			current.SS = kept
+		}
+		return nil
+	}
+	return validationError("Invalid UpdateExpression: unknown clause %s", a.clause)
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdent
+	tokenName
+	tokenValue
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '#' || r == ':' || r == '_' || unicode.IsLetter(r):
+			start := i
+			i++
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			kind := tokenIdent
+			if r == '#' {
+				kind = tokenName
+			} else if r == ':' {
+				kind = tokenValue
+			}
+			tokens = append(tokens, token{kind, string(runes[start:i])})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i])})
+		case strings.ContainsRune("<>", r) && i+1 < len(runes) && (runes[i+1] == '=' || r == '<' && runes[i+1] == '>'):
+			tokens = append(tokens, token{tokenSymbol, string(runes[i : i+2])})
+			i += 2
+		case strings.ContainsRune("()[],.=<>+-", r):
+			tokens = append(tokens, token{tokenSymbol, string(r)})
+			i++
+		default:
+			return nil, validationError("Invalid expression: syntax error; token: %q", string(r))
+		}
+	}
+	return append(tokens, token{kind: tokenEnd}), nil
+}
+
+// parseError aborts parsing, recovered by the parse functions.
+type parseError struct {
+	err error
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	ctx    *expressionContext
+}
+
+func newParser(expression string, ctx *expressionContext) (*parser, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{tokens: tokens, ctx: ctx}, nil
+}
+
+func (p *parser) fail(format string, args ...interface{}) {
+	panic(parseError{validationError(format, args...)})
+}
+
+func (p *parser) recover(err *error) {
+	if r := recover(); r != nil {
+		parsed, ok := r.(parseError)
+		if !ok {
+			panic(r)
+		}
+		*err = parsed.err
+	}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it is the case insensitive word.
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokenIdent && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) symbol(s string) bool {
+	if t := p.peek(); t.kind == tokenSymbol && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) {
+	if !p.symbol(s) {
+		p.fail("Invalid expression: syntax error; expected %q near %q", s, p.peek().text)
+	}
+}
+
+func (p *parser) end() {
+	if t := p.peek(); t.kind != tokenEnd {
+		p.fail("Invalid expression: syntax error; unexpected token %q", t.text)
+	}
+}
+
+// name resolves an attribute name token.
+func (p *parser) name() string {
+	t := p.next()
+	switch t.kind {
+	case tokenIdent:
+		return t.text
+	case tokenName:
+		name, ok := p.ctx.names[t.text]
+		if !ok {
+			p.fail("An expression attribute name used in the document path is not defined; attribute name: %s", t.text)
+		}
+		p.ctx.used[t.text] = true
+		return aws.StringValue(name)
+	}
+	p.fail("Invalid expression: expected an attribute name near %q", t.text)
+	return ""
+}
+
+func (p *parser) path() path {
+	result := path{{name: p.name()}}
+	for {
+		switch {
+		case p.symbol("."):
+			result = append(result, pathElement{name: p.name()})
+		case p.symbol("["):
+			t := p.next()
+			index, err := strconv.Atoi(t.text)
+			if t.kind != tokenNumber || err != nil {
+				p.fail("Invalid expression: list index must be a number near %q", t.text)
+			}
+			p.expect("]")
+			result = append(result, pathElement{index: index})
+		default:
+			return result
+		}
+	}
+}
+
+func (p *parser) value() *dynamodb.AttributeValue {
+	t := p.next()
+	value, ok := p.ctx.values[t.text]
+	if !ok {
+		p.fail("An expression attribute value used in expression is not defined; attribute value: %s", t.text)
+	}
+	p.ctx.used[t.text] = true
+	return value
+}
+
+// isFunction reports whether the next tokens call the named function.
+func (p *parser) isFunction(names ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenIdent || p.tokens[p.pos+1].text != "(" {
+		return "", false
+	}
+	for _, name := range names {
+		if t.text == name {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) operand() operand {
+	if p.peek().kind == tokenValue {
+		return valueOperand{p.value()}
+	}
+	if name, ok := p.isFunction("size", "if_not_exists", "list_append"); ok {
+		p.pos += 2
+		var result operand
+		switch name {
+		case "size":
+			result = sizeOperand(p.path())
+		case "if_not_exists":
+			target := p.path()
+			p.expect(",")
+			result = ifNotExistsOperand{path: target, fallback: p.operand()}
+		case "list_append":
+			a := p.operand()
+			p.expect(",")
+			result = listAppendOperand{a: a, b: p.operand()}
+		}
+		p.expect(")")
+		return result
+	}
+	return pathOperand(p.path())
+}
+
+func (p *parser) or() *condition {
+	left := p.and()
+	for p.keyword("OR") {
+		left = &condition{op: "OR", children: []*condition{left, p.and()}}
+	}
+	return left
+}
+
+func (p *parser) and() *condition {
+	left := p.not()
+	for p.keyword("AND") {
+		left = &condition{op: "AND", children: []*condition{left, p.not()}}
+	}
+	return left
+}
+
+func (p *parser) not() *condition {
+	if p.keyword("NOT") {
+		return &condition{op: "NOT", children: []*condition{p.not()}}
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() *condition {
+	if p.symbol("(") {
+		c := p.or()
+		p.expect(")")
+		return c
+	}
+
+	if name, ok := p.isFunction("attribute_exists", "attribute_not_exists", "attribute_type", "begins_with", "contains"); ok {
+		p.pos += 2
+		c := &condition{op: name, operands: []operand{pathOperand(p.path())}}
+		if name != "attribute_exists" && name != "attribute_not_exists" {
+			p.expect(",")
+			c.operands = append(c.operands, p.operand())
+		}
+		p.expect(")")
+		return c
+	}
+
+	left := p.operand()
+	if p.keyword("BETWEEN") {
+		low := p.operand()
+		if !p.keyword("AND") {
+			p.fail("Invalid expression: BETWEEN requires AND")
+		}
+		return &condition{op: "BETWEEN", operands: []operand{left, low, p.operand()}}
+	}
+	if p.keyword("IN") {
+		p.expect("(")
+		c := &condition{op: "IN", operands: []operand{left, p.operand()}}
+		for p.symbol(",") {
+			c.operands = append(c.operands, p.operand())
+		}
+		p.expect(")")
+		return c
+	}
+
+	t := p.next()
+	switch t.text {
+	case "=", "<>", "<", "<=", ">", ">=":
+		return &condition{op: t.text, operands: []operand{left, p.operand()}}
+	}
+	p.fail("Invalid expression: expected a comparator near %q", t.text)
+	return nil
+}
+
+// parseCondition parses a condition, filter or key condition expression.
+func parseCondition(expression string, ctx *expressionContext) (c *condition, err error) {
+	p, err := newParser(expression, ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.recover(&err)
+	c = p.or()
+	p.end()
+	return c, nil
+}
+
+// parseUpdate parses an update expression into its actions.
+func parseUpdate(expression string, ctx *expressionContext) (actions []updateAction, err error) {
+	p, err := newParser(expression, ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.recover(&err)
+
+	for p.peek().kind != tokenEnd {
+		var clause string
+		for _, word := range []string{"SET", "REMOVE", "ADD", "DELETE"} {
+			if p.keyword(word) {
+				clause = word
+			}
+		}
+		if clause == "" {
+			p.fail("Invalid UpdateExpression: syntax error; token: %q", p.peek().text)
+		}
+
+		for {
+			action := updateAction{clause: clause, path: p.path()}
+			switch clause {
+			case "SET":
+				p.expect("=")
+				action.value = p.operand()
+				if op := p.peek().text; op == "+" || op == "-" {
+					p.next()
+					action.value = arithmeticOperand{op: op, a: action.value, b: p.operand()}
+				}
+			case "ADD", "DELETE":
+				action.value = valueOperand{p.value()}
+			}
+			actions = append(actions, action)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+	if len(actions) == 0 {
+		p.fail("Invalid UpdateExpression: The expression can not be empty")
+	}
+	return actions, nil
+}
+
+// parseProjection parses a projection expression into the paths it keeps.
+func parseProjection(expression string, ctx *expressionContext) (paths []path, err error) {
+	p, err := newParser(expression, ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.recover(&err)
+
+	paths = append(paths, p.path())
+	for p.symbol(",") {
+		paths = append(paths, p.path())
+	}
+	p.end()
+	return paths, nil
+}
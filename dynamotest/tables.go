@@ -0,0 +1,206 @@
+package dynamotest
+
+import (
+	"ascenda/schema"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// keySchema reads the hash and range attributes of a key schema.
+func keySchema(elements []*dynamodb.KeySchemaElement) (schema.Key, error) {
+	var key schema.Key
+	for _, element := range elements {
+		switch aws.StringValue(element.KeyType) {
+		case dynamodb.KeyTypeHash:
+			key.Hash = aws.StringValue(element.AttributeName)
+		case dynamodb.KeyTypeRange:
+			key.Range = aws.StringValue(element.AttributeName)
+		}
+	}
+	if key.Hash == "" {
+		return key, validationError("No Hash Key specified in schema")
+	}
+	return key, nil
+}
+
+func keySchemaElements(key schema.Key) []*dynamodb.KeySchemaElement {
+	elements := []*dynamodb.KeySchemaElement{
+		{AttributeName: aws.String(key.Hash), KeyType: aws.String(dynamodb.KeyTypeHash)},
+	}
+	if key.Range != "" {
+		elements = append(elements, &dynamodb.KeySchemaElement{AttributeName: aws.String(key.Range), KeyType: aws.String(dynamodb.KeyTypeRange)})
+	}
+	return elements
+}
+
+// defined checks that every attribute of the key has a definition.
+func defined(key schema.Key, definitions []*dynamodb.AttributeDefinition) error {
+	for _, attribute := range key.Attributes() {
+		found := false
+		for _, definition := range definitions {
+			found = found || aws.StringValue(definition.AttributeName) == attribute
+		}
+		if !found {
+			return validationError("One or more parameter values were invalid: Some index key attributes are not defined in AttributeDefinitions")
+		}
+	}
+	return nil
+}
+
+// CreateTable adds an empty table with the key and indexes of the input,
+// failing with ResourceInUseException when it already exists. Tables are
+// active as soon as they are created.
+func (db *DB) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["CreateTable"]; err != nil {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.TableName)
+	if _, ok := db.tables[name]; ok {
+		return nil, &dynamodb.ResourceInUseException{Message_: aws.String("Table already exists: " + name)}
+	}
+	key, err := keySchema(input.KeySchema)
+	if err != nil {
+		return nil, err
+	}
+	if err := defined(key, input.AttributeDefinitions); err != nil {
+		return nil, err
+	}
+	t := schema.Table{Name: name, Key: key, Indexes: map[string]schema.Key{}}
+	for _, index := range input.GlobalSecondaryIndexes {
+		indexKey, err := keySchema(index.KeySchema)
+		if err != nil {
+			return nil, err
+		}
+		if err := defined(indexKey, input.AttributeDefinitions); err != nil {
+			return nil, err
+		}
+		t.Indexes[aws.StringValue(index.IndexName)] = indexKey
+	}
+
+	db.tables[name] = &table{Table: t, items: map[string]Item{}}
+	return &dynamodb.CreateTableOutput{TableDescription: db.tables[name].description()}, nil
+}
+
+// UpdateTable supports creating global secondary indexes, which are active
+// at once.
+func (db *DB) UpdateTable(input *dynamodb.UpdateTableInput) (*dynamodb.UpdateTableOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["UpdateTable"]; err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, update := range input.GlobalSecondaryIndexUpdates {
+		if update.Create == nil {
+			panic("dynamotest: UpdateTable only supports creating indexes")
+		}
+		name := aws.StringValue(update.Create.IndexName)
+		if _, ok := t.Indexes[name]; ok {
+			return nil, validationError("Attempting to create an index which already exists")
+		}
+		key, err := keySchema(update.Create.KeySchema)
+		if err != nil {
+			return nil, err
+		}
+		if err := defined(key, input.AttributeDefinitions); err != nil {
+			return nil, err
+		}
+		if t.Indexes == nil {
+			t.Indexes = map[string]schema.Key{}
+		}
+		t.Indexes[name] = key
+	}
+	return &dynamodb.UpdateTableOutput{TableDescription: t.description()}, nil
+}
+
+func (db *DB) DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["DescribeTable"]; err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.DescribeTableOutput{Table: t.description()}, nil
+}
+
+// WaitUntilTableExists returns once the table exists, which is immediately
+// or never.
+func (db *DB) WaitUntilTableExists(input *dynamodb.DescribeTableInput) error {
+	_, err := db.DescribeTable(input)
+	return err
+}
+
+func (t *table) description() *dynamodb.TableDescription {
+	description := &dynamodb.TableDescription{
+		TableName:   aws.String(t.Name),
+		TableStatus: aws.String(dynamodb.TableStatusActive),
+		KeySchema:   keySchemaElements(t.Key),
+		ItemCount:   aws.Int64(int64(len(t.items))),
+	}
+	for _, name := range t.IndexNames() {
+		description.GlobalSecondaryIndexes = append(description.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndexDescription{
+			IndexName:   aws.String(name),
+			IndexStatus: aws.String(dynamodb.IndexStatusActive),
+			KeySchema:   keySchemaElements(t.Indexes[name]),
+		})
+	}
+	return description
+}
+
+// UpdateTimeToLive records the TTL attribute. Items are not expired.
+func (db *DB) UpdateTimeToLive(input *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["UpdateTimeToLive"]; err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	specification := input.TimeToLiveSpecification
+	enabled := aws.BoolValue(specification.Enabled)
+	if enabled == (t.TTL != "") {
+		if enabled {
+			return nil, validationError("TimeToLive is already enabled")
+		}
+		return nil, validationError("TimeToLive is already disabled")
+	}
+	t.TTL = ""
+	if enabled {
+		t.TTL = aws.StringValue(specification.AttributeName)
+	}
+	return &dynamodb.UpdateTimeToLiveOutput{TimeToLiveSpecification: specification}, nil
+}
+
+func (db *DB) DescribeTimeToLive(input *dynamodb.DescribeTimeToLiveInput) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.failures["DescribeTimeToLive"]; err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	description := &dynamodb.TimeToLiveDescription{TimeToLiveStatus: aws.String(dynamodb.TimeToLiveStatusDisabled)}
+	if t.TTL != "" {
+		description.TimeToLiveStatus = aws.String(dynamodb.TimeToLiveStatusEnabled)
+		description.AttributeName = aws.String(t.TTL)
+	}
+	return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: description}, nil
+}
@@ -0,0 +1,141 @@
+package dynamotest
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Item is a stored item.
+type Item = map[string]*dynamodb.AttributeValue
+
+// cloneItem deep copies item so callers never share values with the store.
+func cloneItem(item Item) Item {
+	if item == nil {
+		return nil
+	}
+	cloned := make(Item, len(item))
+	for name, value := range item {
+		cloned[name] = cloneValue(value)
+	}
+	return cloned
+}
+
+func cloneValue(value *dynamodb.AttributeValue) *dynamodb.AttributeValue {
+	if value == nil {
+		return nil
+	}
+	cloned := &dynamodb.AttributeValue{}
+	if value.S != nil {
+		cloned.S = aws.String(*value.S)
+	}
+	if value.N != nil {
+		cloned.N = aws.String(*value.N)
+	}
+	if value.B != nil {
+		cloned.B = append([]byte{}, value.B...)
+	}
+	if value.BOOL != nil {
+		cloned.BOOL = aws.Bool(*value.BOOL)
+	}
+	if value.NULL != nil {
+		cloned.NULL = aws.Bool(*value.NULL)
+	}
+	if value.SS != nil {
+		cloned.SS = aws.StringSlice(aws.StringValueSlice(value.SS))
+	}
+	if value.NS != nil {
+		cloned.NS = aws.StringSlice(aws.StringValueSlice(value.NS))
+	}
+	if value.BS != nil {
+		for _, b := range value.BS {
+			cloned.BS = append(cloned.BS, append([]byte{}, b...))
+		}
+	}
+	if value.L != nil {
+		cloned.L = make([]*dynamodb.AttributeValue, len(value.L))
+		for i, element := range value.L {
+			cloned.L[i] = cloneValue(element)
+		}
+	}
+	if value.M != nil {
+		cloned.M = cloneItem(value.M)
+	}
+	return cloned
+}
+
+// typeOf returns the dynamo type descriptor of value, such as S or N.
+func typeOf(value *dynamodb.AttributeValue) string {
+	switch {
+	case value == nil:
+		return ""
+	case value.S != nil:
+		return "S"
+	case value.N != nil:
+		return "N"
+	case value.B != nil:
+		return "B"
+	case value.BOOL != nil:
+		return "BOOL"
+	case value.NULL != nil:
+		return "NULL"
+	case value.SS != nil:
+		return "SS"
+	case value.NS != nil:
+		return "NS"
+	case value.BS != nil:
+		return "BS"
+	case value.L != nil:
+		return "L"
+	case value.M != nil:
+		return "M"
+	}
+	return ""
+}
+
+func number(s string) (*big.Float, bool) {
+	n, _, err := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+	return n, err == nil
+}
+
+// compare orders two scalar values of the same type. ok is false when the
+// values are of different or unordered types.
+func compare(a, b *dynamodb.AttributeValue) (order int, ok bool) {
+	if typeOf(a) != typeOf(b) {
+		return 0, false
+	}
+	switch typeOf(a) {
+	case "S":
+		switch {
+		case *a.S < *b.S:
+			return -1, true
+		case *a.S > *b.S:
+			return 1, true
+		}
+		return 0, true
+	case "N":
+		x, okX := number(*a.N)
+		y, okY := number(*b.N)
+		if !okX || !okY {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	case "B":
+		return bytes.Compare(a.B, b.B), true
+	}
+	return 0, false
+}
+
+// equal reports whether two values are the same, comparing numbers by value.
+func equal(a, b *dynamodb.AttributeValue) bool {
+	if order, ok := compare(a, b); ok {
+		return order == 0
+	}
+	if typeOf(a) != typeOf(b) || a == nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
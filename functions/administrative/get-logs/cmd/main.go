@@ -0,0 +1,12 @@
+package main
+
+import (
+	getlogs "ascenda/functions/administrative/get-logs"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getlogs.Handler))
+}
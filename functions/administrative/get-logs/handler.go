@@ -1,86 +1,47 @@
-package main
+package getlogs
 
 import (
 	"ascenda/types"
 	"ascenda/utility"
-	"encoding/json"
-	"errors"
-	"os"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// Handler serves GET /logs.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	//get variables
 	id := request.QueryStringParameters["id"]
-	region := os.Getenv("AWS_REGION")
-
-	//setting up dynamo session
-	awsSession, err := session.NewSession(&aws.Config{
-		Region: aws.String(region)})
-
-	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error setting up aws session"),
-		}, nil
-	}
-	dynaClient := dynamodb.New(awsSession)
 
 	// Get the parameter value
-	paramLog := "LOGS_TABLE"
-	outputLogs, err := utility.GetParameterValue(awsSession, paramLog)
+	cfg, err := deps.Config.Load(utility.ParamLogsTable)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting logs table parameter store"),
-		}, nil
+		return utility.Error(request, err), nil
 	}
-	LOGS_TABLE := *outputLogs.Parameter.Value
+	LOGS_TABLE := cfg.LogsTable
 
 	//check if id specified, if yes get single log from dynamo
 	if len(id) > 0 {
-		res, err := FetchLogByID(id, request, LOGS_TABLE, dynaClient)
+		res, err := FetchLogByID(id, LOGS_TABLE, deps.Dynamo)
 		if err != nil {
-			return events.APIGatewayProxyResponse{
-				StatusCode: 404,
-				Body:       string("Error getting log by ID"),
-			}, nil
+			return utility.Error(request, err), nil
 		}
-		body, _ := json.Marshal(res)
-		stringBody := string(body)
-		return events.APIGatewayProxyResponse{
-			Body:       stringBody,
-			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "application/json"},
-		}, nil
+		return utility.JSON(200, res), nil
 	}
 
 	//check if id specified, if no get all logs from dynamo
-	res, err := FetchLogs(request, LOGS_TABLE, dynaClient)
+	res, err := FetchLogs(request, LOGS_TABLE, deps.Dynamo)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting logs"),
-		}, nil
+		return utility.Error(request, err), nil
 	}
 
-	body, _ := json.Marshal(res)
-	stringBody := string(body)
-	return events.APIGatewayProxyResponse{
-		Body:       string(stringBody),
-		StatusCode: 200,
-		Headers:    map[string]string{"Content-Type": "application/json"},
-	}, nil
+	return utility.JSON(200, res), nil
 }
 
-func FetchLogByID(id string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.Log, error) {
+func FetchLogByID(id string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.Log, error) {
 	//get single log from dynamo
 	input := &dynamodb.GetItemInput{
 		Key: map[string]*dynamodb.AttributeValue{
@@ -93,17 +54,17 @@ func FetchLogByID(id string, req events.APIGatewayProxyRequest, tableName string
 
 	result, err := dynaClient.GetItem(input)
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecordID)
+		return nil, types.ErrorFailedToFetchRecordID
 	}
 
 	if result.Item == nil {
-		return nil, errors.New("log does not exist")
+		return nil, types.ErrorLogDoesNotExist
 	}
 
 	item := new(types.Log)
 	err = dynamodbattribute.UnmarshalMap(result.Item, item)
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToUnmarshalRecord)
+		return nil, types.ErrorFailedToUnmarshalRecord
 	}
 
 	return item, nil
@@ -131,7 +92,7 @@ func FetchLogs(req events.APIGatewayProxyRequest, tableName string, dynaClient d
 
 	result, err := dynaClient.Scan(input)
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecord)
+		return nil, types.ErrorFailedToFetchRecord
 	}
 
 	for _, i := range result.Items {
@@ -153,7 +114,3 @@ func FetchLogs(req events.APIGatewayProxyRequest, tableName string, dynaClient d
 
 	return itemWithKey, nil
 }
-
-func main() {
-	lambda.Start(handler)
-}
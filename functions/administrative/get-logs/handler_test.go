@@ -0,0 +1,45 @@
+package getlogs
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestFetchLogByID(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "logs", types.Log{Log_ID: "1", Description: "Ada Admin deleted user Jane Doe"})
+
+	if log, err := FetchLogByID("1", "logs", db); err != nil || log.Description != "Ada Admin deleted user Jane Doe" {
+		t.Errorf("FetchLogByID() = %+v, %v", log, err)
+	}
+	if _, err := FetchLogByID("2", "logs", db); !errors.Is(err, types.ErrorLogDoesNotExist) {
+		t.Errorf("FetchLogByID() error = %v, want %v", err, types.ErrorLogDoesNotExist)
+	}
+}
+
+func TestFetchLogsPages(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	for i := 0; i < 101; i++ {
+		db.Seed(t, "logs", types.Log{Log_ID: fmt.Sprintf("%03d", i)})
+	}
+
+	first, err := FetchLogs(events.APIGatewayProxyRequest{}, "logs", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Data) != 100 || first.Key != "099" {
+		t.Fatalf("first page has %d logs and key %q, want 100 and 099", len(first.Data), first.Key)
+	}
+	second, err := FetchLogs(events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"key": first.Key}}, "logs", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Data) != 1 || second.Data[0].Log_ID != "100" || second.Key != "" {
+		t.Errorf("second page = %+v, key %q, want log 100 only", second.Data, second.Key)
+	}
+}
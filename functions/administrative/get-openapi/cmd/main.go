@@ -0,0 +1,12 @@
+package main
+
+import (
+	getopenapi "ascenda/functions/administrative/get-openapi"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getopenapi.Handler))
+}
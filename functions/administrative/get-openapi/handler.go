@@ -0,0 +1,13 @@
+package getopenapi
+
+import (
+	"ascenda/api"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Handler serves GET /openapi.json, the OpenAPI document of the api.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return utility.JSON(200, api.Document()), nil
+}
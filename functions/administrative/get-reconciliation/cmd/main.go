@@ -0,0 +1,12 @@
+package main
+
+import (
+	getreconciliation "ascenda/functions/administrative/get-reconciliation"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getreconciliation.Handler))
+}
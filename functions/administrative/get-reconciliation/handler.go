@@ -0,0 +1,73 @@
+package getreconciliation
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves GET /reconciliation.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamLogsTable, utility.ParamDriftsTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	LOGS_TABLE := cfg.LogsTable
+	DRIFTS_TABLE := cfg.DriftsTable
+
+	res, err := FetchLatestReport(LOGS_TABLE, DRIFTS_TABLE, deps.Dynamo)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	return utility.JSON(200, res), nil
+}
+
+// FetchLatestReport returns the latest reconciliation report with its drifts
+// read back from the pages of the run. Reports logged before the drifts were
+// paged still hold them inline.
+func FetchLatestReport(tableName string, driftsTable string, dynaClient dynamodbiface.DynamoDBAPI) (*types.DriftReport, error) {
+	//get the latest reconciliation log from dynamo
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"log_id": {
+				S: aws.String(utility.ReconciliationReportID),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(input)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+
+	if result.Item == nil {
+		return nil, types.ErrorReportDoesNotExist
+	}
+
+	item := new(types.Log)
+	err = dynamodbattribute.UnmarshalMap(result.Item, item)
+	if err != nil {
+		return nil, types.ErrorFailedToUnmarshalRecord
+	}
+
+	if item.Report == nil {
+		return nil, types.ErrorReportDoesNotExist
+	}
+
+	if item.Report.Run_ID != "" {
+		item.Report.Drifts, err = utility.FetchDrifts(item.Report.Run_ID, driftsTable, dynaClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return item.Report, nil
+}
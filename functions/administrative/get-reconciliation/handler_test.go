@@ -0,0 +1,63 @@
+package getreconciliation
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestFetchLatestReport(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	if _, err := FetchLatestReport("logs", "drifts", db); !errors.Is(err, types.ErrorReportDoesNotExist) {
+		t.Errorf("FetchLatestReport() error = %v before any report, want %v", err, types.ErrorReportDoesNotExist)
+	}
+
+	db.Seed(t, "logs", types.Log{Log_ID: utility.ReconciliationReportID, Report: &types.DriftReport{DynamoUsers: 3, CognitoUsers: 2}})
+	report, err := FetchLatestReport("logs", "drifts", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.DynamoUsers != 3 || report.CognitoUsers != 2 {
+		t.Errorf("report = %+v, want the stored report", report)
+	}
+}
+
+func TestFetchLatestReportReadsDriftPages(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	report := &types.DriftReport{Run_ID: "run-1", Counts: map[string]int{types.DriftRoleMismatch: utility.DriftPageSize + 1,
+		types.DriftMissingInDynamo: 1}, Drifts: map[string][]types.Drift{}}
+	for i := 0; i <= utility.DriftPageSize; i++ {
+		report.Drifts[types.DriftRoleMismatch] = append(report.Drifts[types.DriftRoleMismatch],
+			types.Drift{UserID: strconv.Itoa(i), Category: types.DriftRoleMismatch})
+	}
+	report.Drifts[types.DriftMissingInDynamo] = []types.Drift{{UserID: "x", Category: types.DriftMissingInDynamo}}
+	if err := utility.SendReconciliationLogs(db, "logs", "drifts", "30", report); err != nil {
+		t.Fatal(err)
+	}
+
+	if pages := db.Items("drifts"); len(pages) != 2 {
+		t.Errorf("stored %d drift pages, want 2", len(pages))
+	}
+	var logs []types.Log
+	db.Load(t, "logs", &logs)
+	if len(logs) != 2 {
+		t.Fatalf("logged %d items, want the run and the latest report", len(logs))
+	}
+	for _, log := range logs {
+		if log.Report == nil || log.Report.Drifts != nil || log.Report.Counts[types.DriftRoleMismatch] != utility.DriftPageSize+1 {
+			t.Errorf("log %s report = %+v, want only the counts", log.Log_ID, log.Report)
+		}
+	}
+
+	got, err := FetchLatestReport("logs", "drifts", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Drifts[types.DriftRoleMismatch]) != utility.DriftPageSize+1 || len(got.Drifts[types.DriftMissingInDynamo]) != 1 {
+		t.Errorf("report has %d role and %d dynamo drifts, want %d and 1", len(got.Drifts[types.DriftRoleMismatch]),
+			len(got.Drifts[types.DriftMissingInDynamo]), utility.DriftPageSize+1)
+	}
+}
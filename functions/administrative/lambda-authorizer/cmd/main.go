@@ -0,0 +1,12 @@
+package main
+
+import (
+	lambdaauthorizer "ascenda/functions/administrative/lambda-authorizer"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.Invoke(utility.MustDeps(), lambdaauthorizer.Handler))
+}
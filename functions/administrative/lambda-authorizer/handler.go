@@ -0,0 +1,161 @@
+package lambdaauthorizer
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+// errUnauthorized is the error API Gateway answers with 401 rather than 500.
+var errUnauthorized = errors.New("Unauthorized")
+
+// Handler authorizes api requests against the effective access of the
+// caller's role, passing the role, user id and role policy on to the handlers.
+// Callers without a valid token are answered 401 and callers whose role does
+// not allow the route 403.
+func Handler(deps *utility.Deps, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	accessToken := header(request.Headers, "Authorization")
+	route := RouteFromRequest(request)
+	method := request.HTTPMethod
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamRolesTable)
+	if err != nil {
+		log.Println(err)
+		return deny("", request.MethodArn), nil
+	}
+	ROLES_TABLE := cfg.RolesTable
+
+	//Check for user's role with cognito
+	role, userID, err := FetchUserAttributes(accessToken, deps.Cognito)
+	if err != nil {
+		log.Println(err)
+		return events.APIGatewayCustomAuthorizerResponse{}, errUnauthorized
+	}
+
+	//any signed in user may use the self service routes, which act on their own user_id only
+	if IsSelfServiceRoute(route, method) {
+		return allow(userID, request.MethodArn, map[string]interface{}{
+			"role":                   role,
+			utility.UserIDContextKey: userID,
+		}), nil
+	}
+
+	// Get list of access of Role, including inherited access
+	access, err2 := utility.EffectiveRole(role, ROLES_TABLE, deps.Dynamo)
+	if err2 != nil {
+		log.Println(err2)
+		return deny(userID, request.MethodArn), nil
+	}
+
+	//Check Roles Item if Role provides permission
+	if !utility.IsAccessAllowed(access, route, method) {
+		return deny(userID, request.MethodArn), nil
+	}
+
+	//pass role policy on to the handler
+	policy, err := json.Marshal(access.Policy)
+	if err != nil {
+		log.Println(err)
+		return deny(userID, request.MethodArn), nil
+	}
+
+	return allow(userID, request.MethodArn, map[string]interface{}{
+		"role":                   role,
+		utility.UserIDContextKey: userID,
+		utility.PolicyContextKey: string(policy),
+	}), nil
+}
+
+// allow returns the response letting principalID invoke methodArn, with the
+// context API Gateway passes on to the handler.
+func allow(principalID, methodArn string, context map[string]interface{}) events.APIGatewayCustomAuthorizerResponse {
+	response := policyResponse(principalID, "Allow", methodArn)
+	response.Context = context
+	return response
+}
+
+// deny returns the response refusing principalID methodArn.
+func deny(principalID, methodArn string) events.APIGatewayCustomAuthorizerResponse {
+	return policyResponse(principalID, "Deny", methodArn)
+}
+
+func policyResponse(principalID, effect, methodArn string) events.APIGatewayCustomAuthorizerResponse {
+	return events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: principalID,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version: "2012-10-17",
+			Statement: []events.IAMPolicyStatement{{
+				Action:   []string{"execute-api:Invoke"},
+				Effect:   effect,
+				Resource: []string{methodArn},
+			}},
+		},
+	}
+}
+
+// header returns the value of the named header, which API Gateway passes on in
+// the case the client sent it.
+func header(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// RouteFromRequest returns the resource the request was matched against,
+// falling back to the path of the request.
+func RouteFromRequest(request events.APIGatewayCustomAuthorizerRequestTypeRequest) string {
+	if request.Resource != "" {
+		return request.Resource
+	}
+	if request.Path == "" {
+		return "/"
+	}
+	return request.Path
+}
+
+// SelfServiceRoutes are the routes and methods open to every signed in user.
+var SelfServiceRoutes = map[string][]string{
+	"/me":        {"GET", "PATCH"},
+	"/me/points": {"GET"},
+}
+
+// IsSelfServiceRoute reports whether the route and method are self service.
+func IsSelfServiceRoute(route, method string) bool {
+	return slices.Contains(SelfServiceRoutes[route], method)
+}
+
+// FetchUserAttributes returns the role and user_id of the token's user, whose
+// cognito username is their user_id.
+func FetchUserAttributes(accessToken string, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI) (string, string, error) {
+	input := &cognitoidentityprovider.GetUserInput{
+		AccessToken: &accessToken,
+	}
+
+	result, err := cognitoClient.GetUser(input)
+	if err != nil {
+		log.Println(err)
+		return "", "", types.ErrorFailedToFetchRecordID
+	}
+
+	var role string
+	for i := 0; i < len(result.UserAttributes); i++ {
+		if *result.UserAttributes[i].Name == "custom:role" {
+			role = *result.UserAttributes[i].Value
+			break
+		}
+	}
+	return role, aws.StringValue(result.Username), nil
+}
@@ -0,0 +1,128 @@
+package lambdaauthorizer
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+func TestRouteFromRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		path     string
+		want     string
+	}{
+		{"resource template", "/users", "/users", "/users"},
+		{"resource without stage", "/me/points", "/Prod/me/points", "/me/points"},
+		{"path without resource", "", "/logs", "/logs"},
+		{"neither", "", "", "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayCustomAuthorizerRequestTypeRequest{Resource: tt.resource, Path: tt.path}
+			if got := RouteFromRequest(request); got != tt.want {
+				t.Errorf("RouteFromRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeCognito knows the tokens of an admin and a customer.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+func (fakeCognito) GetUser(input *cognitoidentityprovider.GetUserInput) (*cognitoidentityprovider.GetUserOutput, error) {
+	roles := map[string]string{"admin-token": "admin", "customer-token": "customer"}
+	role, ok := roles[aws.StringValue(input.AccessToken)]
+	if !ok {
+		return nil, &cognitoidentityprovider.NotAuthorizedException{}
+	}
+	return &cognitoidentityprovider.GetUserOutput{
+		Username:       aws.String(role + "-id"),
+		UserAttributes: []*cognitoidentityprovider.AttributeType{{Name: aws.String("custom:role"), Value: aws.String(role)}},
+	}, nil
+}
+
+func TestHandler(t *testing.T) {
+	const methodArn = "arn:aws:execute-api:ap-southeast-1:123456789012:api/Prod/GET/users"
+	tests := []struct {
+		name        string
+		token       string
+		resource    string
+		method      string
+		wantErr     error
+		wantEffect  string
+		wantContext map[string]interface{}
+	}{
+		{name: "no token", resource: "/users", method: "GET", wantErr: errUnauthorized},
+		{name: "invalid token", token: "stale", resource: "/users", method: "GET", wantErr: errUnauthorized},
+		{name: "allowed by the role", token: "admin-token", resource: "/users", method: "GET", wantEffect: "Allow",
+			wantContext: map[string]interface{}{"role": "admin", utility.UserIDContextKey: "admin-id",
+				utility.PolicyContextKey: `{"user_fields":null,"target_roles":["customer"]}`}},
+		{name: "not allowed by the role", token: "customer-token", resource: "/users", method: "GET", wantEffect: "Deny"},
+		{name: "self service", token: "customer-token", resource: "/me", method: "GET", wantEffect: "Allow",
+			wantContext: map[string]interface{}{"role": "customer", utility.UserIDContextKey: "customer-id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "roles",
+				types.Role{Role: "admin", Access: map[string][]string{"/users": {"GET"}},
+					Policy: types.Policy{TargetRoles: []string{"customer"}}},
+				types.Role{Role: "customer"})
+			deps := &utility.Deps{Dynamo: db, Cognito: fakeCognito{}, Config: utility.StaticConfig{RolesTable: "roles"}}
+			request := events.APIGatewayCustomAuthorizerRequestTypeRequest{Type: "REQUEST", MethodArn: methodArn,
+				Resource: tt.resource, Path: tt.resource, HTTPMethod: tt.method}
+			if tt.token != "" {
+				request.Headers = map[string]string{"authorization": tt.token}
+			}
+
+			response, err := Handler(deps, request)
+			if err != tt.wantErr {
+				t.Fatalf("Handler() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			statement := response.PolicyDocument.Statement
+			if len(statement) != 1 || statement[0].Effect != tt.wantEffect || statement[0].Resource[0] != methodArn {
+				t.Errorf("policy = %+v, want %s on %s", response.PolicyDocument, tt.wantEffect, methodArn)
+			}
+			if !reflect.DeepEqual(response.Context, tt.wantContext) {
+				t.Errorf("context = %v, want %v", response.Context, tt.wantContext)
+			}
+		})
+	}
+}
+
+func TestIsSelfServiceRoute(t *testing.T) {
+	tests := []struct {
+		route  string
+		method string
+		want   bool
+	}{
+		{"/me", "GET", true},
+		{"/me", "PATCH", true},
+		{"/me", "DELETE", false},
+		{"/me/points", "GET", true},
+		{"/me/points", "PUT", false},
+		{"/users", "GET", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSelfServiceRoute(tt.route, tt.method); got != tt.want {
+			t.Errorf("IsSelfServiceRoute(%q, %q) = %v, want %v", tt.route, tt.method, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	reconcileusers "ascenda/functions/administrative/reconcile-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.Invoke(utility.MustDeps(), reconcileusers.Handler))
+}
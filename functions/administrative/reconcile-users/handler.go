@@ -0,0 +1,260 @@
+package reconcileusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/google/uuid"
+)
+
+// Handler compares the users table with the user pool and reports, or
+// repairs, the drift between them.
+func Handler(deps *utility.Deps, request types.ReconciliationRequest) (*types.DriftReport, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamTTL, utility.ParamLogsTable, utility.ParamDriftsTable,
+		utility.ParamUserPoolID)
+	if err != nil {
+		return nil, err
+	}
+	USER_TABLE := cfg.UserTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	DRIFTS_TABLE := cfg.DriftsTable
+	USER_POOL_ID := cfg.UserPoolID
+
+	report, err := ReconcileUsers(request.AutoFix, USER_TABLE, USER_POOL_ID, deps.Dynamo, deps.Cognito)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	//logging
+	if logErr := utility.SendReconciliationLogs(deps.Dynamo, LOGS_TABLE, DRIFTS_TABLE, TTL, report); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return report, nil
+}
+
+// ReconcileUsers compares the users table with the Cognito user pool and, when
+// autoFix is set, brings Cognito in line with DynamoDB as the source of truth.
+func ReconcileUsers(autoFix bool, tableName string, userPoolID string, dynaClient dynamodbiface.DynamoDBAPI,
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI) (*types.DriftReport, error) {
+	dynamoUsers, err := utility.ScanAllUsers(tableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+
+	cognitoUsers, err := ListAllCognitoUsers(userPoolID, cognitoClient)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := FindDrift(dynamoUsers, cognitoUsers)
+	if autoFix {
+		byID := make(map[string]types.User, len(dynamoUsers))
+		for _, user := range dynamoUsers {
+			byID[user.User_ID] = user
+		}
+		for i := range drifts {
+			FixDrift(&drifts[i], byID[drifts[i].UserID], userPoolID, cognitoClient)
+		}
+	}
+
+	report := &types.DriftReport{
+		Run_ID:       uuid.NewString(),
+		GeneratedAt:  time.Now().Unix(),
+		AutoFix:      autoFix,
+		DynamoUsers:  len(dynamoUsers),
+		CognitoUsers: len(cognitoUsers),
+		Counts:       map[string]int{},
+		Drifts:       map[string][]types.Drift{},
+	}
+	for _, drift := range drifts {
+		report.Counts[drift.Category]++
+		report.Drifts[drift.Category] = append(report.Drifts[drift.Category], drift)
+	}
+
+	return report, nil
+}
+
+// CognitoUser is the state of a user in the user pool that reconciliation
+// compares with the users table.
+type CognitoUser struct {
+	Attributes map[string]string
+	Enabled    bool
+}
+
+// FindDrift returns the differences between the DynamoDB users and the Cognito
+// users, which are keyed by user_id as their username. Only active users
+// should be enabled in Cognito; disabled and soft deleted users are compared
+// like any other.
+func FindDrift(dynamoUsers []types.User, cognitoUsers map[string]CognitoUser) []types.Drift {
+	drifts := []types.Drift{}
+	seen := make(map[string]bool, len(dynamoUsers))
+
+	for _, user := range dynamoUsers {
+		seen[user.User_ID] = true
+		cognitoUser, ok := cognitoUsers[user.User_ID]
+		if !ok {
+			drifts = append(drifts, types.Drift{UserID: user.User_ID, Category: types.DriftMissingInCognito})
+			continue
+		}
+		attributes := cognitoUser.Attributes
+		active := user.Status == "" || user.Status == types.UserStatusActive
+		if active != cognitoUser.Enabled {
+			drifts = append(drifts, types.Drift{
+				UserID:   user.User_ID,
+				Category: types.DriftStatusMismatch,
+				Dynamo:   enabledStatus(active),
+				Cognito:  enabledStatus(cognitoUser.Enabled),
+			})
+		}
+		if attributes["custom:role"] != user.Role {
+			drifts = append(drifts, types.Drift{
+				UserID:   user.User_ID,
+				Category: types.DriftRoleMismatch,
+				Dynamo:   user.Role,
+				Cognito:  attributes["custom:role"],
+			})
+		}
+		if utility.EmailKey(attributes["email"]) != utility.EmailKey(user.Email) {
+			drifts = append(drifts, types.Drift{
+				UserID:   user.User_ID,
+				Category: types.DriftEmailMismatch,
+				Dynamo:   user.Email,
+				Cognito:  attributes["email"],
+			})
+		}
+	}
+
+	for username := range cognitoUsers {
+		if !seen[username] {
+			drifts = append(drifts, types.Drift{UserID: username, Category: types.DriftMissingInDynamo})
+		}
+	}
+
+	return drifts
+}
+
+// enabledStatus names whether a user can sign in, as reported in status drift.
+func enabledStatus(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// FixDrift applies the DynamoDB state of the user to Cognito. Cognito users
+// without a DynamoDB item are disabled rather than deleted so a wrong fix can
+// be undone. Deleted users missing in Cognito are left missing, and disabled
+// ones are recreated disabled without an invitation.
+func FixDrift(drift *types.Drift, user types.User, userPoolID string, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI) {
+	var err error
+
+	switch drift.Category {
+	case types.DriftMissingInCognito:
+		if utility.IsUserDeleted(user) {
+			log.Println("not recreating deleted user", drift.UserID)
+			return
+		}
+		disabled := user.Status == types.UserStatusDisabled
+		input := &cognitoidentityprovider.AdminCreateUserInput{
+			DesiredDeliveryMediums: []*string{
+				aws.String("EMAIL"),
+			},
+			UserAttributes: []*cognitoidentityprovider.AttributeType{
+				{Name: aws.String("name"), Value: aws.String(user.FirstName + user.LastName)},
+				{Name: aws.String("given_name"), Value: aws.String(user.User_ID)},
+				{Name: aws.String("email_verified"), Value: aws.String("True")},
+				{Name: aws.String("email"), Value: aws.String(user.Email)},
+				{Name: aws.String("custom:role"), Value: aws.String(user.Role)},
+			},
+			UserPoolId: aws.String(userPoolID),
+			Username:   aws.String(user.User_ID),
+		}
+		if disabled {
+			input.MessageAction = aws.String(cognitoidentityprovider.MessageActionTypeSuppress)
+		}
+		_, err = cognitoClient.AdminCreateUser(input)
+		if err == nil && disabled {
+			_, err = cognitoClient.AdminDisableUser(&cognitoidentityprovider.AdminDisableUserInput{
+				UserPoolId: aws.String(userPoolID),
+				Username:   aws.String(user.User_ID),
+			})
+		}
+	case types.DriftMissingInDynamo:
+		_, err = cognitoClient.AdminDisableUser(&cognitoidentityprovider.AdminDisableUserInput{
+			UserPoolId: aws.String(userPoolID),
+			Username:   aws.String(drift.UserID),
+		})
+	case types.DriftStatusMismatch:
+		if drift.Dynamo == enabledStatus(true) {
+			_, err = cognitoClient.AdminEnableUser(&cognitoidentityprovider.AdminEnableUserInput{
+				UserPoolId: aws.String(userPoolID),
+				Username:   aws.String(drift.UserID),
+			})
+		} else {
+			_, err = cognitoClient.AdminDisableUser(&cognitoidentityprovider.AdminDisableUserInput{
+				UserPoolId: aws.String(userPoolID),
+				Username:   aws.String(drift.UserID),
+			})
+		}
+	case types.DriftRoleMismatch, types.DriftEmailMismatch:
+		name := "custom:role"
+		if drift.Category == types.DriftEmailMismatch {
+			name = "email"
+		}
+		_, err = cognitoClient.AdminUpdateUserAttributes(&cognitoidentityprovider.AdminUpdateUserAttributesInput{
+			UserAttributes: []*cognitoidentityprovider.AttributeType{
+				{Name: aws.String(name), Value: aws.String(drift.Dynamo)},
+			},
+			UserPoolId: aws.String(userPoolID),
+			Username:   aws.String(drift.UserID),
+		})
+	}
+
+	if err != nil {
+		log.Println("failed to fix drift", drift.Category, drift.UserID, err)
+		drift.Error = err.Error()
+		return
+	}
+	drift.Fixed = true
+}
+
+// ListAllCognitoUsers pages through the user pool and returns each user's
+// attributes and whether it is enabled, keyed by username.
+func ListAllCognitoUsers(userPoolID string, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI) (map[string]CognitoUser, error) {
+	input := &cognitoidentityprovider.ListUsersInput{
+		UserPoolId: aws.String(userPoolID),
+		Limit:      aws.Int64(60),
+	}
+
+	users := map[string]CognitoUser{}
+	for {
+		result, err := cognitoClient.ListUsers(input)
+		if err != nil {
+			log.Println(err)
+			return nil, types.ErrorCognitoActionFailed
+		}
+
+		for _, user := range result.Users {
+			attributes := map[string]string{}
+			for _, attribute := range user.Attributes {
+				attributes[aws.StringValue(attribute.Name)] = aws.StringValue(attribute.Value)
+			}
+			users[aws.StringValue(user.Username)] = CognitoUser{Attributes: attributes, Enabled: aws.BoolValue(user.Enabled)}
+		}
+
+		if aws.StringValue(result.PaginationToken) == "" {
+			return users, nil
+		}
+		input.PaginationToken = result.PaginationToken
+	}
+}
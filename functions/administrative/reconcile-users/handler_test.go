@@ -0,0 +1,174 @@
+package reconcileusers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+func TestFindDrift(t *testing.T) {
+	dynamoUsers := []types.User{
+		{User_ID: "in-sync", Email: "a@example.com", Role: "customer"},
+		{User_ID: "role", Email: "b@example.com", Role: "admin"},
+		{User_ID: "email", Email: "new@example.com", Role: "customer"},
+		{User_ID: "dynamo-only", Email: "d@example.com", Role: "customer"},
+		{User_ID: "deleted", Email: "f@example.com", Role: "customer", Status: types.UserStatusDeleted},
+		{User_ID: "disabled", Email: "g@example.com", Role: "customer", Status: types.UserStatusDisabled},
+		{User_ID: "email-case", Email: "H@Example.com", Role: "customer"},
+	}
+	cognitoUsers := map[string]CognitoUser{
+		"in-sync":      {Attributes: map[string]string{"email": "a@example.com", "custom:role": "customer"}, Enabled: true},
+		"role":         {Attributes: map[string]string{"email": "b@example.com", "custom:role": "customer"}, Enabled: true},
+		"email":        {Attributes: map[string]string{"email": "old@example.com", "custom:role": "customer"}, Enabled: true},
+		"cognito-only": {Attributes: map[string]string{"email": "e@example.com", "custom:role": "customer"}, Enabled: true},
+		"deleted":      {Attributes: map[string]string{"email": "f@example.com", "custom:role": "customer"}, Enabled: true},
+		"disabled":     {Attributes: map[string]string{"email": "g@example.com", "custom:role": "customer"}},
+		"email-case":   {Attributes: map[string]string{"email": "h@example.com", "custom:role": "customer"}, Enabled: true},
+	}
+
+	got := map[string]types.Drift{}
+	for _, drift := range FindDrift(dynamoUsers, cognitoUsers) {
+		got[drift.Category+"/"+drift.UserID] = drift
+	}
+
+	want := map[string]types.Drift{
+		types.DriftRoleMismatch + "/role":            {UserID: "role", Category: types.DriftRoleMismatch, Dynamo: "admin", Cognito: "customer"},
+		types.DriftEmailMismatch + "/email":          {UserID: "email", Category: types.DriftEmailMismatch, Dynamo: "new@example.com", Cognito: "old@example.com"},
+		types.DriftMissingInCognito + "/dynamo-only": {UserID: "dynamo-only", Category: types.DriftMissingInCognito},
+		types.DriftMissingInDynamo + "/cognito-only": {UserID: "cognito-only", Category: types.DriftMissingInDynamo},
+		types.DriftStatusMismatch + "/deleted":       {UserID: "deleted", Category: types.DriftStatusMismatch, Dynamo: "disabled", Cognito: "enabled"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("FindDrift() = %+v, want %+v", got, want)
+	}
+	for key, drift := range want {
+		if got[key] != drift {
+			t.Errorf("drift %s = %+v, want %+v", key, got[key], drift)
+		}
+	}
+}
+
+// fakeCognito serves the pool's users one per page and records created
+// users, attribute updates and disabled users.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	users    []*cognitoidentityprovider.UserType
+	created  []string
+	updated  []string
+	disabled []string
+}
+
+func (f *fakeCognito) AdminCreateUser(input *cognitoidentityprovider.AdminCreateUserInput) (*cognitoidentityprovider.AdminCreateUserOutput, error) {
+	created := *input.Username
+	if aws.StringValue(input.MessageAction) == cognitoidentityprovider.MessageActionTypeSuppress {
+		created += " uninvited"
+	}
+	f.created = append(f.created, created)
+	return &cognitoidentityprovider.AdminCreateUserOutput{}, nil
+}
+
+func (f *fakeCognito) ListUsers(input *cognitoidentityprovider.ListUsersInput) (*cognitoidentityprovider.ListUsersOutput, error) {
+	page := 0
+	if input.PaginationToken != nil {
+		page, _ = strconv.Atoi(*input.PaginationToken)
+	}
+	output := &cognitoidentityprovider.ListUsersOutput{Users: f.users[page : page+1]}
+	if page+1 < len(f.users) {
+		output.PaginationToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+func (f *fakeCognito) AdminDisableUser(input *cognitoidentityprovider.AdminDisableUserInput) (*cognitoidentityprovider.AdminDisableUserOutput, error) {
+	f.disabled = append(f.disabled, *input.Username)
+	return &cognitoidentityprovider.AdminDisableUserOutput{}, nil
+}
+
+func (f *fakeCognito) AdminUpdateUserAttributes(input *cognitoidentityprovider.AdminUpdateUserAttributesInput) (*cognitoidentityprovider.AdminUpdateUserAttributesOutput, error) {
+	f.updated = append(f.updated, *input.Username+" "+*input.UserAttributes[0].Value)
+	return &cognitoidentityprovider.AdminUpdateUserAttributesOutput{}, nil
+}
+
+func cognitoUser(username, email, role string) *cognitoidentityprovider.UserType {
+	return &cognitoidentityprovider.UserType{
+		Username: aws.String(username),
+		Enabled:  aws.Bool(true),
+		Attributes: []*cognitoidentityprovider.AttributeType{
+			{Name: aws.String("email"), Value: aws.String(email)},
+			{Name: aws.String("custom:role"), Value: aws.String(role)},
+		},
+	}
+}
+
+func TestReconcileUsers(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "users",
+		types.User{User_ID: "1", Email: "a@example.com", Role: "customer"},
+		types.User{User_ID: "2", Email: "b@example.com", Role: "admin"},
+		types.User{User_ID: "3", Email: "c@example.com", Role: "customer", Status: types.UserStatusDeleted})
+	cognitoClient := &fakeCognito{users: []*cognitoidentityprovider.UserType{
+		cognitoUser("1", "a@example.com", "customer"),
+		cognitoUser("2", "b@example.com", "customer"),
+		cognitoUser("3", "c@example.com", "customer"),
+	}}
+
+	report, err := ReconcileUsers(true, "users", "pool", db, cognitoClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.DynamoUsers != 3 || report.CognitoUsers != 3 || len(report.Drifts) != 2 {
+		t.Fatalf("report = %+v, want two drifts across all pages", report)
+	}
+	want := map[string]int{types.DriftRoleMismatch: 1, types.DriftStatusMismatch: 1}
+	if !reflect.DeepEqual(report.Counts, want) {
+		t.Errorf("counts = %v, want %v", report.Counts, want)
+	}
+	drift := report.Drifts[types.DriftRoleMismatch]
+	if len(drift) != 1 || !drift[0].Fixed || !reflect.DeepEqual(cognitoClient.updated, []string{"2 admin"}) {
+		t.Errorf("drift = %+v, cognito updated %v, want the role fixed in cognito", drift, cognitoClient.updated)
+	}
+	drift = report.Drifts[types.DriftStatusMismatch]
+	if len(drift) != 1 || !drift[0].Fixed || !reflect.DeepEqual(cognitoClient.disabled, []string{"3"}) {
+		t.Errorf("drift = %+v, cognito disabled %v, want the deleted user disabled in cognito", drift, cognitoClient.disabled)
+	}
+}
+
+func TestFixDriftMissingInCognito(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		wantFixed    bool
+		wantCreated  []string
+		wantDisabled []string
+	}{
+		{name: "active user is invited", wantFixed: true, wantCreated: []string{"1"}},
+		{name: "disabled user is recreated disabled", status: types.UserStatusDisabled, wantFixed: true,
+			wantCreated: []string{"1 uninvited"}, wantDisabled: []string{"1"}},
+		{name: "deleted user is not recreated", status: types.UserStatusDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cognitoClient := &fakeCognito{}
+			drift := types.Drift{UserID: "1", Category: types.DriftMissingInCognito}
+			user := types.User{User_ID: "1", Email: "a@example.com", Role: "customer", Status: tt.status}
+
+			FixDrift(&drift, user, "pool", cognitoClient)
+			if drift.Fixed != tt.wantFixed || drift.Error != "" {
+				t.Errorf("drift = %+v, want fixed %v", drift, tt.wantFixed)
+			}
+			if !reflect.DeepEqual(cognitoClient.created, tt.wantCreated) ||
+				!reflect.DeepEqual(cognitoClient.disabled, tt.wantDisabled) {
+				t.Errorf("cognito created %v and disabled %v, want %v and %v", cognitoClient.created,
+					cognitoClient.disabled, tt.wantCreated, tt.wantDisabled)
+			}
+		})
+	}
+}
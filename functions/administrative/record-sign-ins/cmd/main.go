@@ -0,0 +1,12 @@
+package main
+
+import (
+	recordsignins "ascenda/functions/administrative/record-sign-ins"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.Invoke(utility.MustDeps(), recordsignins.Handler))
+}
@@ -0,0 +1,88 @@
+package recordsignins
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves the pre and post authentication triggers of the user pool.
+// The ip is taken from the user context data cognito collects when threat
+// protection is on. The ip_address, user_agent and country clients send in the
+// validation data of a sign in and in its client metadata are only stored as
+// reported by the client. Failing to record never blocks the sign in.
+func Handler(deps *utility.Deps, event json.RawMessage) (json.RawMessage, error) {
+	var header struct {
+		events.CognitoEventUserPoolsHeader
+		Request struct {
+			NewDeviceUsed   bool              `json:"newDeviceUsed"`
+			ValidationData  map[string]string `json:"validationData"`
+			ClientMetadata  map[string]string `json:"clientMetadata"`
+			UserContextData struct {
+				IPAddress string `json:"ipAddress"`
+			} `json:"userContextData"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(event, &header); err != nil {
+		log.Println(err)
+		return event, nil
+	}
+
+	signIn := types.SignInEvent{
+		User_ID:   header.UserName,
+		Timestamp: time.Now().UnixMilli(),
+		IP:        header.Request.UserContextData.IPAddress,
+	}
+	metadata := header.Request.ClientMetadata
+	switch {
+	case strings.HasPrefix(header.TriggerSource, "PreAuthentication_"):
+		signIn.Outcome = types.SignInAttempt
+		metadata = header.Request.ValidationData
+	case strings.HasPrefix(header.TriggerSource, "PostAuthentication_"):
+		signIn.Outcome = types.SignInSuccess
+	default:
+		return event, nil
+	}
+	client := types.ClientReport{IP: metadata["ip_address"], UserAgent: metadata["user_agent"], Country: metadata["country"]}
+	if client != (types.ClientReport{}) {
+		signIn.Client = &client
+	}
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamSessionsTable, utility.ParamSessionTTL, utility.ParamSignInFailureThreshold)
+	if err != nil {
+		log.Println(err)
+		return event, nil
+	}
+	SESSIONS_TABLE := cfg.SessionsTable
+	SESSION_TTL := cfg.SessionTTL
+	SIGN_IN_FAILURE_THRESHOLD := cfg.SignInFailureThreshold
+
+	if _, err := RecordSignIn(signIn, header.Request.NewDeviceUsed, SESSIONS_TABLE, SESSION_TTL, SIGN_IN_FAILURE_THRESHOLD, deps.Dynamo); err != nil {
+		log.Println("Recording sign in err :", err)
+	}
+	return event, nil
+}
+
+// RecordSignIn flags the event against the user's recent history and stores
+// it.
+func RecordSignIn(signIn types.SignInEvent, newDevice bool, tableName string, ttlDays int, failureThreshold int,
+	dynaClient dynamodbiface.DynamoDBAPI) (types.SignInEvent, error) {
+	history, err := utility.FetchSignInEvents(signIn.User_ID, "", utility.SignInHistorySize, false, tableName, dynaClient)
+	if err != nil {
+		return signIn, err
+	}
+
+	signIn.Flags = utility.FlagSignIn(signIn, history.Data, newDevice, failureThreshold)
+	if len(signIn.Flags) > 0 {
+		log.Printf("Unusual sign in for user %s: %s", signIn.User_ID, strings.Join(signIn.Flags, ", "))
+	}
+
+	return signIn, utility.SaveSignInEvent(signIn, ttlDays, tableName, dynaClient)
+}
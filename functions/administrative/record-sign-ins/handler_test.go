@@ -0,0 +1,68 @@
+package recordsignins
+
+import (
+	"ascenda/awstest"
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRecordSignIn(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+
+	//each sign in is flagged against the ones recorded before it
+	tests := []struct {
+		name      string
+		signIn    types.SignInEvent
+		newDevice bool
+		wantFlags []string
+	}{
+		{"first sign in", types.SignInEvent{Timestamp: 1000, Outcome: types.SignInSuccess, IP: "1.1.1.1"}, true, nil},
+		{"same ip", types.SignInEvent{Timestamp: 2000, Outcome: types.SignInSuccess, IP: "1.1.1.1"}, false, nil},
+		{"new ip", types.SignInEvent{Timestamp: 3000, Outcome: types.SignInSuccess, IP: "2.2.2.2"}, true,
+			[]string{types.SignInFlagNewIP, types.SignInFlagNewDevice}},
+		{"first failure", types.SignInEvent{Timestamp: 4000, Outcome: types.SignInAttempt}, false, nil},
+		{"second failure", types.SignInEvent{Timestamp: 5000, Outcome: types.SignInAttempt}, false, nil},
+		{"repeated failures", types.SignInEvent{Timestamp: 6000, Outcome: types.SignInAttempt}, false,
+			[]string{types.SignInFlagRepeatedFailures}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.signIn.User_ID = "1"
+			recorded, err := RecordSignIn(tt.signIn, tt.newDevice, "sessions", 30, 2, db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(recorded.Flags, tt.wantFlags) {
+				t.Errorf("flags = %v, want %v", recorded.Flags, tt.wantFlags)
+			}
+		})
+	}
+
+	var events []types.SignInEvent
+	db.Load(t, "sessions", &events)
+	if len(events) != len(tests) || events[2].Flags == nil || events[0].TTL == 0 {
+		t.Errorf("sessions = %+v, want every sign in stored with its flags and ttl", events)
+	}
+}
+
+func TestHandlerKeepsClientReportApart(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	event := json.RawMessage(`{"userName":"1","triggerSource":"PreAuthentication_Authentication","request":{
+		"validationData":{"ip_address":"9.9.9.9","user_agent":"curl","country":"US"},
+		"userContextData":{"ipAddress":"1.1.1.1","encodedData":"data"}}}`)
+
+	if _, err := Handler(awstest.Deps(db), event); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []types.SignInEvent
+	db.Load(t, "sessions", &events)
+	want := &types.ClientReport{IP: "9.9.9.9", UserAgent: "curl", Country: "US"}
+	if len(events) != 1 || events[0].IP != "1.1.1.1" || !reflect.DeepEqual(events[0].Client, want) {
+		t.Errorf("sessions = %+v, want the cognito ip and the client report kept apart", events)
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	runmigrations "ascenda/functions/administrative/run-migrations"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.Invoke(utility.MustDeps(), runmigrations.Handler))
+}
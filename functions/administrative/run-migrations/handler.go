@@ -0,0 +1,25 @@
+package runmigrations
+
+import (
+	"ascenda/migrations"
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+)
+
+// Handler applies the pending migrations of migrations.Migrations, or counts
+// the items they would change in a dry run.
+func Handler(deps *utility.Deps, request types.MigrationRequest) (*types.MigrationReport, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(migrations.Params(migrations.Migrations)...)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := migrations.Run(request, migrations.Migrations, cfg, deps.Dynamo)
+	if err != nil {
+		log.Println(err)
+		return report, err
+	}
+	return report, nil
+}
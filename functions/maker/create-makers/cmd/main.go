@@ -0,0 +1,12 @@
+package main
+
+import (
+	createmakers "ascenda/functions/maker/create-makers"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), createmakers.Handler))
+}
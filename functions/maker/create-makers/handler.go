@@ -1,131 +1,76 @@
-package main
+package createmakers
 
 import (
 	"ascenda/types"
 	"ascenda/utility"
+	"ascenda/validation"
 	"encoding/json"
-	"errors"
 	"log"
-	"os"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
 )
 
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	//getting variables
-	region := os.Getenv("AWS_REGION")
-
-	//setting up dynamo session
-	awsSession, err := session.NewSession(&aws.Config{
-		Region: aws.String(region)})
-
-	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error setting up aws session"),
-		}, nil
-	}
-
-	dynaClient := dynamodb.New(awsSession)
-
+// Handler serves POST /makers.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Get the parameter value
-	paramUser := "USER_TABLE"
-	outputUser, err := utility.GetParameterValue(awsSession, paramUser)
-	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting user table parameter store"),
-		}, nil
-	}
-	USER_TABLE := *outputUser.Parameter.Value
-
-	paramPoints := "POINTS_TABLE"
-	outputPoints, err := utility.GetParameterValue(awsSession, paramPoints)
-	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting points table parameter store"),
-		}, nil
-	}
-	POINTS_TABLE := *outputPoints.Parameter.Value
-
-	paramMaker := "MAKER_TABLE"
-	outputMaker, err := utility.GetParameterValue(awsSession, paramMaker)
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamPointsTable, utility.ParamMakerTable)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting maker table parameter store"),
-		}, nil
+		return utility.Error(request, err), nil
 	}
-	MAKER_TABLE := *outputMaker.Parameter.Value
+	USER_TABLE := cfg.UserTable
+	POINTS_TABLE := cfg.PointsTable
+	MAKER_TABLE := cfg.MakerTable
 
 	//calling create maker request to dynamo func
-	res, err := CreateMakerRequest(request, MAKER_TABLE, USER_TABLE, POINTS_TABLE, dynaClient)
+	res, err := CreateMakerRequest(request, MAKER_TABLE, USER_TABLE, POINTS_TABLE, deps.Dynamo, deps.SES)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string(err.Error()),
-		}, nil
+		return utility.Error(request, err), nil
 	}
 
-	body, _ := json.Marshal(res)
-
-	stringBody := string(body)
-
-	return events.APIGatewayProxyResponse{
-		Body:       stringBody,
-		StatusCode: 200,
-		Headers:    map[string]string{"Content-Type": "application/json"},
-	}, nil
+	return utility.JSON(200, res), nil
 }
 
-func CreateMakerRequest(req events.APIGatewayProxyRequest, makerTableName, userTableName, pointsTableName string, dynaClient dynamodbiface.DynamoDBAPI) (
+func CreateMakerRequest(req events.APIGatewayProxyRequest, makerTableName, userTableName, pointsTableName string, dynaClient dynamodbiface.DynamoDBAPI,
+	sesClient sesiface.SESAPI) (
 	[]types.ReturnMakerRequest, error) {
-	var postMakerRequest types.NewMakerRequest
-
-	//marshall body to maker request struct
-	if err := json.Unmarshal([]byte(req.Body), &postMakerRequest); err != nil {
-		return nil, errors.New(types.ErrorInvalidMakerData)
-	}
-
-	if postMakerRequest.MakerUUID == "" {
-		err := errors.New(types.ErrorInvalidMakerData)
+	//decode body to maker request struct
+	postMakerRequest, err := utility.Decode[types.NewMakerRequest](req, validation.NewMakerRequest)
+	if err != nil {
 		return nil, err
 	}
 
-	_, err := FetchUserByID(postMakerRequest.MakerUUID, req, userTableName, dynaClient)
+	_, err = utility.FetchUserByID(postMakerRequest.MakerUUID, userTableName, dynaClient)
 	if err != nil {
-		return nil, errors.New(types.ErrorUserDoesNotExist)
+		return nil, types.ErrorUserDoesNotExist
 	}
 
 	if postMakerRequest.ResourceType == "user" {
 		//marshall body to point struct
 		var userData types.User
 		if err := json.Unmarshal(postMakerRequest.RequestData, &userData); err != nil {
-			return nil, errors.New(types.ErrorCouldNotMarshalItem)
+			return nil, types.ErrorCouldNotMarshalItem
 		}
 
 		// check if user exist
-		_, err = FetchUserByID(userData.User_ID, req, userTableName, dynaClient)
+		_, err = utility.FetchUserByID(userData.User_ID, userTableName, dynaClient)
 		if err != nil {
-			return nil, errors.New(userData.User_ID)
+			return nil, types.ErrorUserDoesNotExist
 		}
 		// send out email
 		for _, role := range postMakerRequest.CheckerRoles {
-			users, err := FetchUsersByRoles(role, req, userTableName, dynaClient)
+			users, err := FetchUsersByRoles(role, userTableName, dynaClient)
 			if err != nil {
-				return nil, errors.New(types.ErrorFailedToFetchRecord)
+				return nil, types.ErrorFailedToFetchRecord
 			}
 			if len(users) > 0 {
 				for _, user := range users {
-					err := sendEmail(user.Email, req, dynaClient)
+					err := sendEmail(user.Email, sesClient)
 					if err != nil {
 						log.Println("error sending email")
 					}
@@ -143,27 +88,27 @@ func CreateMakerRequest(req events.APIGatewayProxyRequest, makerTableName, userT
 		//marshall body to point struct
 		var pointsData types.UserPoint
 		if err := json.Unmarshal(postMakerRequest.RequestData, &pointsData); err != nil {
-			return nil, errors.New(types.ErrorCouldNotMarshalItem)
+			return nil, types.ErrorCouldNotMarshalItem
 		}
 		// check if points exist
-		_, err = FetchUserPoint(pointsData.User_ID, req, pointsTableName, dynaClient)
+		_, err = FetchUserPoint(pointsData.User_ID, pointsTableName, dynaClient)
 		if err != nil {
-			return nil, errors.New(types.ErrorPointsDoesNotExist)
+			return nil, types.ErrorPointsDoesNotExist
 		}
 
 		if pointsData.Points_ID == "" {
-			return nil, errors.New(types.ErrorInvalidPointsID)
+			return nil, types.ErrorInvalidPointsID
 		}
 
 		// send out email
 		for _, role := range postMakerRequest.CheckerRoles {
-			users, err := FetchUsersByRoles(role, req, userTableName, dynaClient)
+			users, err := FetchUsersByRoles(role, userTableName, dynaClient)
 			if err != nil {
-				return nil, errors.New(types.ErrorFailedToFetchRecord)
+				return nil, types.ErrorFailedToFetchRecord
 			}
 			if len(users) > 0 {
 				for _, user := range users {
-					err := sendEmail(user.Email, req, dynaClient)
+					err := sendEmail(user.Email, sesClient)
 					if err != nil {
 						log.Println("error sending email")
 					}
@@ -177,35 +122,10 @@ func CreateMakerRequest(req events.APIGatewayProxyRequest, makerTableName, userT
 		return utility.BatchWriteToDynamoDB(roleCount, makerRequests, makerTableName, dynaClient)
 	}
 
-	return nil, errors.New(types.ErrorInvalidResourceType)
-}
-
-func FetchUserByID(id string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.User, error) {
-	//get single user from dynamo
-	input := &dynamodb.GetItemInput{
-		Key: map[string]*dynamodb.AttributeValue{
-			"user_id": {
-				S: aws.String(id),
-			},
-		},
-		TableName: aws.String(tableName),
-	}
-
-	result, err := dynaClient.GetItem(input)
-
-	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecordID)
-	}
-	item := new(types.User)
-	err = dynamodbattribute.UnmarshalMap(result.Item, item)
-	if err != nil {
-		return nil, errors.New(types.ErrorFailedToUnmarshalRecord)
-	}
-
-	return item, nil
+	return nil, types.ErrorInvalidResourceType
 }
 
-func FetchUsersByRoles(role string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.User, error) {
+func FetchUsersByRoles(role string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.User, error) {
 	//get users with a certain role
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(tableName),
@@ -222,18 +142,18 @@ func FetchUsersByRoles(role string, req events.APIGatewayProxyRequest, tableName
 	result, err := dynaClient.Query(input)
 
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecordID)
+		return nil, types.ErrorFailedToFetchRecordID
 	}
 	users := new([]types.User)
 	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, users)
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToUnmarshalRecord)
+		return nil, types.ErrorFailedToUnmarshalRecord
 	}
 
 	return *users, nil
 }
 
-func FetchUserPoint(user_id string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*[]types.UserPoint, error) {
+func FetchUserPoint(user_id string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*[]types.UserPoint, error) {
 	//getting single single user point
 	input := &dynamodb.QueryInput{
 		TableName: aws.String(tableName),
@@ -251,39 +171,25 @@ func FetchUserPoint(user_id string, req events.APIGatewayProxyRequest, tableName
 
 	result, err := dynaClient.Query(input)
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecord)
+		return nil, types.ErrorFailedToFetchRecord
 	}
 
-	if result.Items == nil {
-		return nil, errors.New("user point does not exist")
+	if len(result.Items) == 0 {
+		return nil, types.ErrorPointsDoesNotExist
 	}
 
 	item := new([]types.UserPoint)
 	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, item)
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToUnmarshalRecord)
+		return nil, types.ErrorFailedToUnmarshalRecord
 	}
 
 	return item, nil
 }
 
-func main() {
-	lambda.Start(handler)
-}
-
-func sendEmail(recipientEmail string, req events.APIGatewayProxyRequest, dynaClient dynamodbiface.DynamoDBAPI) error {
+func sendEmail(recipientEmail string, svc sesiface.SESAPI) error {
 	senderEmail := "pesexoh964@glalen.com"
 
-	// Create an SES session
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String("ap-southeast-1"), // Replace with your desired AWS region
-	})
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-
-	svc := ses.New(sess)
 	// Check if the recipient's email is verified
 	verifyParams := &ses.GetIdentityVerificationAttributesInput{
 		Identities: []*string{aws.String(recipientEmail)},
@@ -314,7 +220,7 @@ func sendEmail(recipientEmail string, req events.APIGatewayProxyRequest, dynaCli
 	`
 
 	// Send the email
-	_, err = svc.SendEmail(&ses.SendEmailInput{
+	_, err := svc.SendEmail(&ses.SendEmailInput{
 		Destination: &ses.Destination{
 			ToAddresses: []*string{aws.String(recipientEmail)},
 		},
@@ -0,0 +1,112 @@
+package createmakers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+const makerID = "3a7b9c1d-2e4f-4a6b-8c0d-1e2f3a4b5c6d"
+
+// fakeSES treats every address as verified and records who it emails.
+type fakeSES struct {
+	sesiface.SESAPI
+	sent []string
+}
+
+func (f *fakeSES) GetIdentityVerificationAttributes(input *ses.GetIdentityVerificationAttributesInput) (*ses.GetIdentityVerificationAttributesOutput, error) {
+	attributes := map[string]*ses.IdentityVerificationAttributes{}
+	for _, identity := range input.Identities {
+		attributes[*identity] = &ses.IdentityVerificationAttributes{VerificationStatus: aws.String("Success")}
+	}
+	return &ses.GetIdentityVerificationAttributesOutput{VerificationAttributes: attributes}, nil
+}
+
+func (f *fakeSES) SendEmail(input *ses.SendEmailInput) (*ses.SendEmailOutput, error) {
+	f.sent = append(f.sent, *input.Destination.ToAddresses[0])
+	return &ses.SendEmailOutput{}, nil
+}
+
+func TestCreateMakerRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantErr   error
+		wantRoles []string
+		wantSent  []string
+	}{
+		{
+			name:      "user request for two roles",
+			body:      `{"checker_roles":["admin","owner"],"maker_id":"` + makerID + `","resource_type":"user","request_data":{"user_id":"2"}}`,
+			wantRoles: []string{"admin", "owner"},
+			wantSent:  []string{"admin@example.com", "owner@example.com"},
+		},
+		{
+			name:      "points request",
+			body:      `{"checker_roles":["admin"],"maker_id":"` + makerID + `","resource_type":"points","request_data":{"user_id":"2","points_id":"p1","points":5}}`,
+			wantRoles: []string{"admin"},
+			wantSent:  []string{"admin@example.com"},
+		},
+		{
+			name:    "missing target user",
+			body:    `{"checker_roles":["admin"],"maker_id":"` + makerID + `","resource_type":"user","request_data":{"user_id":"9"}}`,
+			wantErr: types.ErrorUserDoesNotExist,
+		},
+		{
+			name:    "missing points account",
+			body:    `{"checker_roles":["admin"],"maker_id":"` + makerID + `","resource_type":"points","request_data":{"user_id":"9","points_id":"p9"}}`,
+			wantErr: types.ErrorPointsDoesNotExist,
+		},
+		{
+			name:    "missing maker",
+			body:    `{"checker_roles":["admin"],"maker_id":"0f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9","resource_type":"user","request_data":{"user_id":"2"}}`,
+			wantErr: types.ErrorUserDoesNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "users",
+				types.User{User_ID: makerID, Role: "customer"},
+				types.User{User_ID: "2", Role: "customer"},
+				types.User{User_ID: "3", Email: "admin@example.com", Role: "admin"},
+				types.User{User_ID: "4", Email: "owner@example.com", Role: "owner"})
+			db.Seed(t, "points", types.UserPoint{User_ID: "2", Points_ID: "p1"})
+			sesClient := &fakeSES{}
+			req := events.APIGatewayProxyRequest{Body: tt.body}
+
+			res, err := CreateMakerRequest(req, "makers", "users", "points", db, sesClient)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateMakerRequest() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var requests []types.MakerRequest
+			db.Load(t, "makers", &requests)
+			if tt.wantErr != nil {
+				if len(requests) != 0 || len(sesClient.sent) != 0 {
+					t.Errorf("rejected request stored %+v or emailed %v", requests, sesClient.sent)
+				}
+				return
+			}
+
+			var roles []string
+			for _, request := range requests {
+				if request.RequestUUID != res[0].RequestUUID || request.RequestStatus != "pending" {
+					t.Errorf("stored request = %+v, want pending %s", request, res[0].RequestUUID)
+				}
+				roles = append(roles, request.CheckerRole)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) || !reflect.DeepEqual(sesClient.sent, tt.wantSent) {
+				t.Errorf("checker roles = %v, emailed %v, want %v and %v", roles, sesClient.sent, tt.wantRoles, tt.wantSent)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	getcheckers "ascenda/functions/maker/get-checkers"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getcheckers.Handler))
+}
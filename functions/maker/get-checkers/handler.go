@@ -0,0 +1,68 @@
+package getcheckers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves GET /checkers.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//get variables
+	status := request.QueryStringParameters["status"]
+	role := request.QueryStringParameters["role"]
+
+	//get parameter value
+	cfg, err := deps.Config.Load(utility.ParamMakerTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	MAKER_TABLE := cfg.MakerTable
+
+	// filter by client role and maker request status
+	if len(role) > 0 && len(status) > 0 {
+		res, err := FetchMakerRequestsByCheckerRoleAndStatus(role, status, MAKER_TABLE, deps.Dynamo)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+		return utility.JSON(200, res), nil
+	}
+	if len(role) == 0 {
+		return utility.Error(request, types.MissingParameter("role")), nil
+	}
+	return utility.Error(request, types.MissingParameter("status")), nil
+}
+
+func FetchMakerRequestsByCheckerRoleAndStatus(checker_role, requestStatus, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*[]types.MakerRequest, error) {
+	queryInput := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		IndexName:              aws.String("checker_role-request_status-index"),
+		KeyConditionExpression: aws.String("#checker_role = :checker_role AND #request_status = :request_status"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":checker_role":   {S: aws.String(checker_role)},
+			":request_status": {S: aws.String(requestStatus)},
+		},
+		ExpressionAttributeNames: map[string]*string{
+			"#checker_role":   aws.String("checker_role"),
+			"#request_status": aws.String("request_status"),
+		},
+	}
+
+	result, err := dynaClient.Query(queryInput)
+	if err != nil {
+		return nil, types.ErrorCouldNotQueryDB
+	}
+
+	makerRequests := new([]types.MakerRequest)
+	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, makerRequests)
+	if err != nil {
+		return nil, types.ErrorFailedToUnmarshal
+	}
+
+	return makerRequests, nil
+}
@@ -0,0 +1,42 @@
+package getcheckers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"reflect"
+	"testing"
+)
+
+func TestFetchMakerRequestsByCheckerRoleAndStatus(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "makers",
+		types.MakerRequest{RequestUUID: "r1", CheckerRole: "admin", RequestStatus: "pending"},
+		types.MakerRequest{RequestUUID: "r1", CheckerRole: "owner", RequestStatus: "pending"},
+		types.MakerRequest{RequestUUID: "r2", CheckerRole: "admin", RequestStatus: "approved"},
+		types.MakerRequest{RequestUUID: "r3", CheckerRole: "admin", RequestStatus: "pending"})
+
+	tests := []struct {
+		role, status string
+		want         []string
+	}{
+		{"admin", "pending", []string{"r1", "r3"}},
+		{"admin", "approved", []string{"r2"}},
+		{"owner", "approved", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+" "+tt.status, func(t *testing.T) {
+			requests, err := FetchMakerRequestsByCheckerRoleAndStatus(tt.role, tt.status, "makers", db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, request := range *requests {
+				ids = append(ids, request.RequestUUID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("requests = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
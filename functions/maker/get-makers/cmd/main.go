@@ -0,0 +1,12 @@
+package main
+
+import (
+	getmakers "ascenda/functions/maker/get-makers"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getmakers.Handler))
+}
@@ -1,113 +1,61 @@
-package main
+package getmakers
 
 import (
 	"ascenda/types"
 	"ascenda/utility"
-	"encoding/json"
-	"errors"
-	"os"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// Handler serves GET /makers.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	//get variables
 	req_id := request.QueryStringParameters["req_id"]
-	region := os.Getenv("AWS_REGION")
-
-	//setting up dynamo session
-	awsSession, err := session.NewSession(&aws.Config{
-		Region: aws.String(region)})
-
-	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error setting up aws session"),
-		}, nil
-	}
-	dynaClient := dynamodb.New(awsSession)
 
 	//get parameter value
-	paramMaker := "MAKER_TABLE"
-	outputMaker, err := utility.GetParameterValue(awsSession, paramMaker)
+	cfg, err := deps.Config.Load(utility.ParamMakerTable)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting maker table parameter store"),
-		}, nil
+		return utility.Error(request, err), nil
 	}
-	MAKER_TABLE := *outputMaker.Parameter.Value
+	MAKER_TABLE := cfg.MakerTable
 
 	// get by req id
 	if len(req_id) > 0 {
-		res, err := FetchMakerRequest(req_id, MAKER_TABLE, request, dynaClient)
+		res, err := FetchMakerRequest(req_id, MAKER_TABLE, deps.Dynamo)
 		if err != nil {
-			return events.APIGatewayProxyResponse{
-				StatusCode: 404,
-				Body:       string(err.Error()),
-			}, nil
+			return utility.Error(request, err), nil
 		}
-		stringBody, _ := json.Marshal(res)
-		return events.APIGatewayProxyResponse{
-			Body:       string(stringBody),
-			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "application/json"},
-		}, nil
+		return utility.JSON(200, res), nil
 	}
 
 	// get by maker id and status
 	makerId := request.QueryStringParameters["maker_id"]
 	status := request.QueryStringParameters["status"]
 	if len(makerId) > 0 && len(status) > 0 {
-		res, err := FetchMakerRequestsByMakerIdAndStatus(makerId, status, MAKER_TABLE, request, dynaClient)
+		res, err := FetchMakerRequestsByMakerIdAndStatus(makerId, status, MAKER_TABLE, deps.Dynamo)
 		if err != nil {
-			return events.APIGatewayProxyResponse{
-				StatusCode: 404,
-				Body:       string(err.Error()),
-			}, nil
+			return utility.Error(request, err), nil
 		}
-		stringBody, _ := json.Marshal(res)
-		return events.APIGatewayProxyResponse{
-			Body:       string(stringBody),
-			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "application/json"},
-		}, nil
+		return utility.JSON(200, res), nil
 	} else if len(makerId) > 0 && len(status) == 0 {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Missing status query param"),
-		}, nil
+		return utility.Error(request, types.MissingParameter("status")), nil
 	} else if len(makerId) == 0 && len(status) > 0 {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Missing maker_id query param"),
-		}, nil
+		return utility.Error(request, types.MissingParameter("maker_id")), nil
 	}
 	// get all
-	res, err := FetchMakerRequests(MAKER_TABLE, request, dynaClient)
+	res, err := FetchMakerRequests(MAKER_TABLE, request, deps.Dynamo)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string(err.Error()),
-		}, nil
-	}
-
-	body, _ := json.Marshal(res)
-	stringBody := string(body)
-	return events.APIGatewayProxyResponse{
-		Body:       string(stringBody),
-		StatusCode: 200,
-		Headers:    map[string]string{"Content-Type": "application/json"},
-	}, nil
+		return utility.Error(request, err), nil
+	}
+
+	return utility.JSON(200, res), nil
 }
 
-func FetchMakerRequest(requestID, tableName string, req events.APIGatewayProxyRequest, dynaClient dynamodbiface.DynamoDBAPI) ([]types.ReturnMakerRequest, error) {
+func FetchMakerRequest(requestID, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.ReturnMakerRequest, error) {
 	queryInput := &dynamodb.QueryInput{
 		TableName:              aws.String(tableName),
 		KeyConditionExpression: aws.String("req_id = :req_id"),
@@ -118,17 +66,17 @@ func FetchMakerRequest(requestID, tableName string, req events.APIGatewayProxyRe
 
 	result, err := dynaClient.Query(queryInput)
 	if err != nil {
-		return nil, errors.New(types.ErrorCouldNotQueryDB)
+		return nil, types.ErrorCouldNotQueryDB
 	}
 
 	if len(result.Items) == 0 {
-		return nil, errors.New(types.ErrorMakerReqDoesNotExist)
+		return nil, types.ErrorMakerReqDoesNotExist
 	}
 
 	makerRequests := new([]types.MakerRequest)
 	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, makerRequests)
 	if err != nil {
-		return nil, errors.New(types.ErrorCouldNotMarshalItem)
+		return nil, types.ErrorCouldNotMarshalItem
 	}
 
 	return utility.FormatMakerRequest(*makerRequests), nil
@@ -159,13 +107,13 @@ func FetchMakerRequests(tableName string, req events.APIGatewayProxyRequest, dyn
 	result, err := dynaClient.Scan(input)
 
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecord)
+		return nil, types.ErrorFailedToFetchRecord
 	}
 	item := new([]types.MakerRequest)
 
 	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, item)
 	if err != nil {
-		return nil, errors.New(utility.ErrorCouldNotUnmarshalItem)
+		return nil, types.ErrorFailedToUnmarshal
 	}
 
 	itemWithKey := new(types.ReturnMakerData)
@@ -182,7 +130,7 @@ func FetchMakerRequests(tableName string, req events.APIGatewayProxyRequest, dyn
 	return itemWithKey, nil
 }
 
-func FetchMakerRequestsByMakerIdAndStatus(makerID, requestStatus, tableName string, req events.APIGatewayProxyRequest, dynaClient dynamodbiface.DynamoDBAPI) ([]types.ReturnMakerRequest, error) {
+func FetchMakerRequestsByMakerIdAndStatus(makerID, requestStatus, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.ReturnMakerRequest, error) {
 	queryInput := &dynamodb.QueryInput{
 		TableName:              aws.String(tableName),
 		IndexName:              aws.String("maker_id-request_status-index"),
@@ -199,18 +147,14 @@ func FetchMakerRequestsByMakerIdAndStatus(makerID, requestStatus, tableName stri
 
 	result, err := dynaClient.Query(queryInput)
 	if err != nil {
-		return nil, errors.New(types.ErrorCouldNotQueryDB)
+		return nil, types.ErrorCouldNotQueryDB
 	}
 
 	makerRequests := new([]types.MakerRequest)
 	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, makerRequests)
 	if err != nil {
-		return nil, errors.New(utility.ErrorCouldNotUnmarshalItem)
+		return nil, types.ErrorFailedToUnmarshal
 	}
 
 	return utility.FormatMakerRequest(*makerRequests), nil
 }
-
-func main() {
-	lambda.Start(handler)
-}
@@ -0,0 +1,78 @@
+package getmakers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "makers",
+		types.MakerRequest{RequestUUID: "r1", CheckerRole: "admin", MakerUUID: "m1", RequestStatus: "pending", ResourceType: "user"},
+		types.MakerRequest{RequestUUID: "r1", CheckerRole: "owner", MakerUUID: "m1", RequestStatus: "pending", ResourceType: "user"},
+		types.MakerRequest{RequestUUID: "r2", CheckerRole: "admin", MakerUUID: "m1", RequestStatus: "approved", ResourceType: "points"},
+		types.MakerRequest{RequestUUID: "r3", CheckerRole: "admin", MakerUUID: "m2", RequestStatus: "pending", ResourceType: "user"})
+	deps := &utility.Deps{Dynamo: db, Config: utility.StaticConfig{MakerTable: "makers"}}
+
+	tests := []struct {
+		name       string
+		params     map[string]string
+		wantStatus int
+		want       []string
+	}{
+		{"by request", map[string]string{"req_id": "r1"}, 200, []string{"r1:admin,owner"}},
+		{"missing request", map[string]string{"req_id": "r9"}, 404, nil},
+		{"by maker and status", map[string]string{"maker_id": "m1", "status": "pending"}, 200, []string{"r1:admin,owner"}},
+		{"maker without status", map[string]string{"maker_id": "m1"}, 400, nil},
+		{"status without maker", map[string]string{"status": "pending"}, 400, nil},
+		{"all", nil, 200, []string{"r1:admin,owner", "r2:admin", "r3:admin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Handler(deps, events.APIGatewayProxyRequest{QueryStringParameters: tt.params})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", res.StatusCode, tt.wantStatus, res.Body)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+
+			var requests []types.ReturnMakerRequest
+			if tt.params == nil {
+				var data types.ReturnMakerData
+				json.Unmarshal([]byte(res.Body), &data)
+				requests = data.Data
+			} else {
+				json.Unmarshal([]byte(res.Body), &requests)
+			}
+			if got := summarize(requests); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requests = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// summarize renders each request as id:roles, sorted as the rows of a request
+// are grouped through a map.
+func summarize(requests []types.ReturnMakerRequest) []string {
+	var out []string
+	for _, request := range requests {
+		roles := append([]string{}, request.CheckerRole...)
+		sort.Strings(roles)
+		out = append(out, request.RequestUUID+":"+strings.Join(roles, ","))
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	updatecheckers "ascenda/functions/maker/update-checkers"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), updatecheckers.Handler))
+}
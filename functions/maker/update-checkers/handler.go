@@ -0,0 +1,314 @@
+package updatecheckers
+
+import (
+	"ascenda/validation"
+	"encoding/json"
+
+	"ascenda/types"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves PUT /checkers.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamEmailsTable, utility.ParamPointsTable, utility.ParamMakerTable,
+	)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	EMAILS_TABLE := cfg.EmailsTable
+	POINTS_TABLE := cfg.PointsTable
+	MAKER_TABLE := cfg.MakerTable
+
+	// decode json body into DecisionBody
+	decisionBody, err := utility.Decode[types.DecisionBody](request, validation.DecisionBody)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	//calling  to dynamo func
+	res, err := MakerRequestDecision(decisionBody.RequestId, decisionBody.CheckerRole, decisionBody.CheckerId,
+		decisionBody.Decision, MAKER_TABLE, USER_TABLE, EMAILS_TABLE, POINTS_TABLE, deps.Dynamo)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	return utility.JSON(200, res), nil
+}
+
+func MakerRequestDecision(reqId, checkerRole, checkerUUID, decision, makerTableName, userTableName, emailsTableName, pointsTableName string,
+	dynaClient dynamodbiface.DynamoDBAPI) (
+	[]types.ReturnMakerRequest,
+	error,
+) {
+	currentMakerRequest, err := FetchMakerRequestsByReqIdAndCheckerRole(reqId, checkerRole, makerTableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if len(currentMakerRequest) == 0 || len(currentMakerRequest[0].RequestUUID) == 0 {
+		return nil, types.ErrorMakerDoesNotExist
+	}
+
+	if decision == "approve" {
+		resourceType := currentMakerRequest[0].ResourceType
+
+		// if maker request to change user table
+		if resourceType == "user" {
+			var userData types.User
+			if err := json.Unmarshal(currentMakerRequest[0].RequestData, &userData); err != nil {
+				return nil, types.ErrorFailedToUnmarshalRecord
+			}
+
+			_, err = utility.FetchUserByID(userData.User_ID, userTableName, dynaClient)
+			if err != nil {
+				return nil, types.ErrorUserDoesNotExist
+			}
+
+			if len(userData.User_ID) == 0 {
+				return nil, types.ErrorInvalidUserID
+			}
+			// make changes to user table
+			_, err := UpdateUser(userData, userTableName, emailsTableName, dynaClient)
+			if err != nil {
+				return nil, err
+			}
+
+			// if maker request to change points table
+		} else if resourceType == "points" {
+			var pointsData types.UserPoint
+			if err := json.Unmarshal(currentMakerRequest[0].RequestData, &pointsData); err != nil {
+				return nil, types.ErrorCouldNotMarshalItem
+			}
+			//points of deleted users stay frozen until they are restored
+			user, err := utility.FetchUserByID(pointsData.User_ID, userTableName, dynaClient)
+			if err == nil && utility.IsUserDeleted(*user) {
+				return nil, types.ErrorUserAlreadyDeleted
+			}
+
+			_, err = FetchUserPoint(pointsData.User_ID, pointsTableName, dynaClient)
+			if err != nil {
+				return nil, err
+			}
+
+			// make changes to points table
+			_, err = UpdateUserPoint(pointsData, pointsTableName, dynaClient)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, types.ErrorInvalidResourceType
+		}
+		decision = "approved"
+	} else if decision == "reject" {
+		decision = "rejected"
+	} else {
+		return nil, types.ErrorInvalidDecision
+	}
+
+	makerRequests, err := FetchMakerRequest(reqId, makerTableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	for i, request := range makerRequests {
+		request.RequestStatus = decision
+		request.CheckerUUID = checkerUUID
+
+		makerRequests[i] = request
+	}
+
+	retMakerRequest, err := utility.BatchWriteToDynamoDB(len(makerRequests), makerRequests, makerTableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	return retMakerRequest, nil
+}
+
+func FetchMakerRequest(requestID, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.MakerRequest, error) {
+	queryInput := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("req_id = :req_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":req_id": {S: aws.String(requestID)},
+		},
+	}
+
+	result, err := dynaClient.Query(queryInput)
+
+	if err != nil {
+		return nil, types.ErrorCouldNotQueryDB
+	}
+
+	if len(result.Items) == 0 {
+		return nil, types.ErrorMakerDoesNotExist
+	}
+	makerRequests := new([]types.MakerRequest)
+	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, makerRequests)
+	if err != nil {
+		return nil, types.ErrorCouldNotMarshalItem
+	}
+
+	return *makerRequests, nil
+}
+
+func FetchMakerRequestsByReqIdAndCheckerRole(reqID, checkerRole, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.MakerRequest, error) {
+	queryInput := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("req_id = :req_id AND checker_role = :checker_role"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":req_id":       {S: aws.String(reqID)},
+			":checker_role": {S: aws.String(checkerRole)},
+		},
+	}
+
+	result, err := dynaClient.Query(queryInput)
+
+	if err != nil {
+		return nil, types.ErrorCouldNotQueryDB
+	}
+
+	makerRequests := new([]types.MakerRequest)
+	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, makerRequests)
+
+	if err != nil {
+		return nil, types.ErrorCouldNotMarshalItem
+	}
+
+	if len(*makerRequests) == 0 {
+		return nil, types.ErrorMakerDoesNotExist
+	}
+
+	return *makerRequests, nil
+}
+
+func UpdateUser(user types.User, tableName string, emailsTable string, dynaClient dynamodbiface.DynamoDBAPI) (*types.User, error) {
+	if user.User_ID == "" {
+		err := types.ErrorInvalidUserID
+		return nil, err
+	}
+
+	//checking if user exist
+	checkUser := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(user.User_ID),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(checkUser)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+
+	if result.Item == nil {
+		return nil, types.ErrorUserDoesNotExist
+	}
+
+	//status only changes through disable, delete and restore
+	var current types.User
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &current); err != nil {
+		return nil, types.ErrorFailedToUnmarshal
+	}
+	if utility.IsUserDeleted(current) {
+		return nil, types.ErrorUserAlreadyDeleted
+	}
+	user.Status = current.Status
+	user.DeletedAt = current.DeletedAt
+
+	if err := utility.PutUserItem(user, &current, tableName, emailsTable, dynaClient); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func UpdateUserPoint(userpoint types.UserPoint, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.UserPoint, error) {
+	// check if points id is empty
+	if userpoint.Points_ID == "" {
+		err := types.ErrorInvalidPointsID
+		return nil, err
+	}
+
+	//checking if userpoint exist
+	results, err := FetchUserPoint(userpoint.User_ID, tableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = new(types.UserPoint)
+	for _, v := range *results {
+		if v.Points_ID == userpoint.Points_ID {
+			switch v.Status {
+			case types.PointsStatusClosed:
+				return nil, types.ErrorPointsAccountClosed
+			case types.PointsStatusFrozen:
+				return nil, types.ErrorPointsAccountFrozen
+			}
+			userpoint.Status = v.Status
+			result = &userpoint
+		}
+	}
+
+	if result.Points_ID != userpoint.Points_ID {
+		return nil, types.ErrorPointsDoesNotExist
+	}
+
+	av, err := dynamodbattribute.MarshalMap(result)
+	if err != nil {
+		return nil, types.ErrorCouldNotMarshalItem
+	}
+
+	//updating user point in dynamo
+	input := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName),
+	}
+	_, err = dynaClient.PutItem(input)
+	if err != nil {
+		return nil, types.ErrorCouldNotDynamoPutItem
+	}
+
+	return result, nil
+}
+
+func FetchUserPoint(user_id string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*[]types.UserPoint, error) {
+	//getting single single user point
+	input := &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"user_id": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(user_id),
+					},
+				},
+			},
+		},
+	}
+
+	result, err := dynaClient.Query(input)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecord
+	}
+
+	if len(result.Items) == 0 {
+		return nil, types.ErrorPointsDoesNotExist
+	}
+
+	item := new([]types.UserPoint)
+	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, item)
+	if err != nil {
+		return nil, types.ErrorFailedToUnmarshalRecord
+	}
+
+	return item, nil
+}
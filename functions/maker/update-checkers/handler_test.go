@@ -0,0 +1,108 @@
+package updatecheckers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func seed(t *testing.T, db *dynamotest.DB) {
+	db.Seed(t, "users",
+		types.User{User_ID: "1", Email: "jane@example.com", FirstName: "Jane", Role: "customer"},
+		types.User{User_ID: "2", Email: "gone@example.com", Role: "customer", Status: types.UserStatusDeleted})
+	db.Seed(t, "emails", map[string]string{"email": "jane@example.com", "user_id": "1"})
+	db.Seed(t, "points",
+		types.UserPoint{User_ID: "1", Points_ID: "p1", Points: 10},
+		types.UserPoint{User_ID: "2", Points_ID: "p2", Points: 10, Status: types.PointsStatusFrozen})
+	for _, request := range []struct {
+		id, resourceType, data string
+	}{
+		{"user", "user", `{"user_id":"1","email":"jane.doe@example.com","first_name":"Janet","role":"customer"}`},
+		{"points", "points", `{"user_id":"1","points_id":"p1","points":99}`},
+		{"no-points", "points", `{"user_id":"3","points_id":"p3","points":99}`},
+		{"unknown-points", "points", `{"user_id":"1","points_id":"p9","points":99}`},
+		{"deleted", "user", `{"user_id":"2","first_name":"Gone","role":"customer"}`},
+		{"deleted-points", "points", `{"user_id":"2","points_id":"p2","points":99}`},
+	} {
+		for _, role := range []string{"admin", "owner"} {
+			db.Seed(t, "makers", types.MakerRequest{RequestUUID: request.id, CheckerRole: role, MakerUUID: "m",
+				RequestStatus: "pending", ResourceType: request.resourceType, RequestData: json.RawMessage(request.data)})
+		}
+	}
+}
+
+func TestMakerRequestDecision(t *testing.T) {
+	tests := []struct {
+		name       string
+		reqID      string
+		decision   string
+		wantErr    error
+		wantStatus string
+		check      func(t *testing.T, db *dynamotest.DB)
+	}{
+		{name: "approve user change", reqID: "user", decision: "approve", wantStatus: "approved",
+			check: func(t *testing.T, db *dynamotest.DB) {
+				var users []types.User
+				db.Load(t, "users", &users)
+				var emails []map[string]string
+				db.Load(t, "emails", &emails)
+				if users[0].FirstName != "Janet" || len(emails) != 1 || emails[0]["email"] != "jane.doe@example.com" {
+					t.Errorf("user = %+v, emails = %v, want the change and its email guard", users[0], emails)
+				}
+			}},
+		{name: "approve points change", reqID: "points", decision: "approve", wantStatus: "approved",
+			check: func(t *testing.T, db *dynamotest.DB) {
+				var points []types.UserPoint
+				db.Load(t, "points", &points)
+				if points[0].Points != 99 {
+					t.Errorf("points = %+v, want 99", points[0])
+				}
+			}},
+		{name: "reject", reqID: "user", decision: "reject", wantStatus: "rejected",
+			check: func(t *testing.T, db *dynamotest.DB) {
+				var users []types.User
+				db.Load(t, "users", &users)
+				if users[0].FirstName != "Jane" {
+					t.Errorf("rejected change applied to %+v", users[0])
+				}
+			}},
+		{name: "missing points account", reqID: "no-points", decision: "approve", wantErr: types.ErrorPointsDoesNotExist},
+		{name: "unknown points account", reqID: "unknown-points", decision: "approve",
+			wantErr: types.ErrorPointsDoesNotExist},
+		{name: "deleted user", reqID: "deleted", decision: "approve", wantErr: types.ErrorUserAlreadyDeleted},
+		{name: "points of a deleted user", reqID: "deleted-points", decision: "approve", wantErr: types.ErrorUserAlreadyDeleted},
+		{name: "unknown decision", reqID: "user", decision: "maybe", wantErr: types.ErrorInvalidDecision},
+		{name: "missing request", reqID: "nope", decision: "approve", wantErr: types.ErrorMakerDoesNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			seed(t, db)
+
+			_, err := MakerRequestDecision(tt.reqID, "admin", "checker", tt.decision, "makers", "users", "emails", "points", db)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MakerRequestDecision() error = %v, want %v", err, tt.wantErr)
+			}
+
+			//every checker role row of the request carries the decision
+			var requests []types.MakerRequest
+			db.Load(t, "makers", &requests)
+			for _, request := range requests {
+				want, checker := "pending", ""
+				if request.RequestUUID == tt.reqID && tt.wantErr == nil {
+					want, checker = tt.wantStatus, "checker"
+				}
+				if request.RequestStatus != want || request.CheckerUUID != checker {
+					t.Errorf("request %s/%s = %s by %q, want %s by %q", request.RequestUUID, request.CheckerRole,
+						request.RequestStatus, request.CheckerUUID, want, checker)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, db)
+			}
+		})
+	}
+}
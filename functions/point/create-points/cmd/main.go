@@ -0,0 +1,12 @@
+package main
+
+import (
+	createpoints "ascenda/functions/point/create-points"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), createpoints.Handler))
+}
@@ -0,0 +1,83 @@
+package createpoints
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/google/uuid"
+)
+
+// Handler serves POST /points.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamPointsTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	POINTS_TABLE := cfg.PointsTable
+
+	//calling create point to dynamo func
+	res, err := CreateUserPoint(request, POINTS_TABLE, USER_TABLE, deps.Dynamo)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	return utility.JSON(200, res), nil
+}
+
+func CreateUserPoint(req events.APIGatewayProxyRequest, tableName string, userTable string, dynaClient dynamodbiface.DynamoDBAPI) (*types.UserPoint, error) {
+	//decode body to point struct
+	userpoint, err := utility.Decode[types.UserPoint](req, validation.UserPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	//check if user_id is supplied
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(userpoint.User_ID),
+			},
+		},
+		TableName: aws.String(userTable),
+	}
+
+	result, err := dynaClient.GetItem(input)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+
+	if result.Item == nil {
+		return nil, types.ErrorUserDoesNotExist
+	}
+
+	userpoint.Points_ID = uuid.NewString()
+	userpoint.Points = 0
+
+	//putting into dynamo db
+	av, err := dynamodbattribute.MarshalMap(userpoint)
+
+	if err != nil {
+		return nil, types.ErrorCouldNotMarshalItem
+	}
+
+	data := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName),
+	}
+
+	_, err = dynaClient.PutItem(data)
+
+	if err != nil {
+		return nil, types.ErrorCouldNotDynamoPutItem
+	}
+
+	return &userpoint, nil
+}
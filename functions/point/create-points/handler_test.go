@@ -0,0 +1,49 @@
+package createpoints
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const userID = "5f0c7a3e-4b8e-4c55-9a51-3c2b9d8e1f00"
+
+func TestCreateUserPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{"opens at zero", `{"user_id":"` + userID + `"}`, nil},
+		{"missing user", `{"user_id":"0b8f1c52-7a0e-4f3d-8c8e-6d2a4e9b7c11"}`, types.ErrorUserDoesNotExist},
+		{"user id not a uuid", `{"user_id":"1"}`, types.ErrorValidationFailed},
+		{"points are not accepted", `{"user_id":"` + userID + `","points":500}`, types.ErrorValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "users", types.User{User_ID: userID, Role: "customer"})
+
+			res, err := CreateUserPoint(events.APIGatewayProxyRequest{Body: tt.body}, "points", "users", db)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateUserPoint() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var points []types.UserPoint
+			db.Load(t, "points", &points)
+			if tt.wantErr != nil {
+				if len(points) != 0 {
+					t.Errorf("points = %+v, want none", points)
+				}
+				return
+			}
+			if len(points) != 1 || points[0] != *res || res.Points != 0 || res.Points_ID == "" {
+				t.Errorf("points = %+v, created %+v, want one empty account", points, res)
+			}
+		})
+	}
+}
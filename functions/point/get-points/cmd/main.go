@@ -0,0 +1,12 @@
+package main
+
+import (
+	getpoints "ascenda/functions/point/get-points"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getpoints.Handler))
+}
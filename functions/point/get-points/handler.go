@@ -1,84 +1,46 @@
-package main
+package getpoints
 
 import (
 	"ascenda/types"
 	"ascenda/utility"
-	"encoding/json"
-	"errors"
-	"os"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// Handler serves GET /points.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	//get variables
 	user_id := request.QueryStringParameters["id"]
-	region := os.Getenv("AWS_REGION")
-
-	//setting up dynamo session
-	awsSession, err := session.NewSession(&aws.Config{
-		Region: aws.String(region)})
-
-	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error setting up aws session"),
-		}, nil
-	}
-	dynaClient := dynamodb.New(awsSession)
 
 	//get parameter value
-	paramPoints := "POINTS_TABLE"
-	outputPoints, err := utility.GetParameterValue(awsSession, paramPoints)
+	cfg, err := deps.Config.Load(utility.ParamPointsTable)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting points table parameter store"),
-		}, nil
+		return utility.Error(request, err), nil
 	}
-	POINTS_TABLE := *outputPoints.Parameter.Value
+	POINTS_TABLE := cfg.PointsTable
 
 	//check if user id is specified, if yes call get user point from dynamo func
 	if len(user_id) > 0 {
-		res, err := FetchUserPoint(user_id, request, POINTS_TABLE, dynaClient)
+		res, err := FetchUserPoint(user_id, POINTS_TABLE, deps.Dynamo)
 		if err != nil {
-			return events.APIGatewayProxyResponse{
-				StatusCode: 404,
-				Body:       string("Error getting point by id"),
-			}, nil
+			return utility.Error(request, err), nil
 		}
-		stringBody, _ := json.Marshal(res)
-		return events.APIGatewayProxyResponse{
-			Body:       string(stringBody),
-			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "application/json"},
-		}, nil
+		return utility.JSON(200, res), nil
 	}
 
 	//check if user id is specified, if no call get all user point from dynamo func
-	res, err := FetchUsersPoint(request, POINTS_TABLE, dynaClient)
+	res, err := FetchUsersPoint(request, POINTS_TABLE, deps.Dynamo)
 	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting points"),
-		}, nil
+		return utility.Error(request, err), nil
 	}
-	body, _ := json.Marshal(res)
-	stringBody := string(body)
-	return events.APIGatewayProxyResponse{
-		Body:       string(stringBody),
-		StatusCode: 200,
-		Headers:    map[string]string{"Content-Type": "application/json"},
-	}, nil
+	return utility.JSON(200, res), nil
 }
 
-func FetchUserPoint(user_id string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*[]types.UserPoint, error) {
+func FetchUserPoint(user_id string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*[]types.UserPoint, error) {
 	//getting single single user point
 	input := &dynamodb.QueryInput{
 		TableName: aws.String(tableName),
@@ -96,17 +58,17 @@ func FetchUserPoint(user_id string, req events.APIGatewayProxyRequest, tableName
 
 	result, err := dynaClient.Query(input)
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecord)
+		return nil, types.ErrorFailedToFetchRecord
 	}
 
 	if result.Items == nil {
-		return nil, errors.New("user point does not exist")
+		return nil, types.ErrorPointsDoesNotExist
 	}
 
 	item := new([]types.UserPoint)
 	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, item)
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToUnmarshalRecord)
+		return nil, types.ErrorFailedToUnmarshalRecord
 	}
 
 	return item, nil
@@ -139,7 +101,7 @@ func FetchUsersPoint(req events.APIGatewayProxyRequest, tableName string, dynaCl
 	result, err := dynaClient.Scan(input)
 
 	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecord)
+		return nil, types.ErrorFailedToFetchRecord
 	}
 
 	for _, i := range result.Items {
@@ -162,7 +124,3 @@ func FetchUsersPoint(req events.APIGatewayProxyRequest, tableName string, dynaCl
 
 	return itemWithKey, nil
 }
-
-func main() {
-	lambda.Start(handler)
-}
@@ -0,0 +1,53 @@
+package getpoints
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestFetchUserPoint(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "points",
+		types.UserPoint{User_ID: "1", Points_ID: "a", Points: 10},
+		types.UserPoint{User_ID: "1", Points_ID: "b", Points: 20},
+		types.UserPoint{User_ID: "2", Points_ID: "c", Points: 30})
+
+	points, err := FetchUserPoint("1", "points", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*points) != 2 || (*points)[0].Points_ID != "a" || (*points)[1].Points_ID != "b" {
+		t.Errorf("points = %+v, want accounts a and b", *points)
+	}
+}
+
+func TestFetchUsersPointPages(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	for i := 0; i < 130; i++ {
+		db.Seed(t, "points", types.UserPoint{User_ID: fmt.Sprint(i % 3), Points_ID: fmt.Sprintf("%03d", i)})
+	}
+
+	first, err := FetchUsersPoint(events.APIGatewayProxyRequest{}, "points", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Data) != 100 || first.KeyUser == "" || first.KeyPoint == "" {
+		t.Fatalf("first page has %d accounts and keys %q %q, want 100 and keys", len(first.Data), first.KeyUser, first.KeyPoint)
+	}
+
+	req := events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"keyUser": first.KeyUser, "keyPoint": first.KeyPoint}}
+	second, err := FetchUsersPoint(req, "points", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Data) != 30 || second.KeyUser != "" {
+		t.Errorf("second page has %d accounts and key %q, want 30 and no key", len(second.Data), second.KeyUser)
+	}
+	if second.Data[0] == first.Data[99] {
+		t.Errorf("second page repeats %+v", second.Data[0])
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	updatepoints "ascenda/functions/point/update-points"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), updatepoints.Handler))
+}
@@ -0,0 +1,167 @@
+package updatepoints
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves PUT /points.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	user_id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamTTL, utility.ParamLogsTable, utility.ParamPointsTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	POINTS_TABLE := cfg.PointsTable
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	//checking if user id is specified, if yes then update user in dynamo func
+	if len(user_id) > 0 {
+		res, err := UpdateUserPoint(user_id, request, POINTS_TABLE, USER_TABLE, LOGS_TABLE, TTL, deps.Dynamo, policy)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+
+		return utility.JSON(200, res), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+
+}
+
+func UpdateUserPoint(user_id string, req events.APIGatewayProxyRequest, tableName string, userTable string, logTable string, ttl string,
+	dynaClient dynamodbiface.DynamoDBAPI, policy *types.Policy) (*types.UserPoint, error) {
+	oldPoints := 0
+	//decode body into the points update
+	update, err := utility.Decode[types.PointsUpdate](req, validation.UserPointUpdate)
+	if err != nil {
+		return nil, err
+	}
+	userpoint := types.UserPoint{User_ID: user_id, Points_ID: update.Points_ID, Points: update.Points}
+
+	//points of deleted users stay frozen until they are restored
+	user, err := utility.FetchUserByID(user_id, userTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if utility.IsUserDeleted(*user) {
+		return nil, types.ErrorUserAlreadyDeleted
+	}
+
+	//checking if userpoint exist
+	results, err := FetchUserPoint(user_id, tableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = new(types.UserPoint)
+	for _, v := range *results {
+		if v.Points_ID == userpoint.Points_ID {
+			switch v.Status {
+			case types.PointsStatusClosed:
+				return nil, types.ErrorPointsAccountClosed
+			case types.PointsStatusFrozen:
+				return nil, types.ErrorPointsAccountFrozen
+			}
+			oldPoints = v.Points
+			userpoint.Status = v.Status
+			result = &userpoint
+		}
+	}
+
+	if result.Points_ID != userpoint.Points_ID {
+		return nil, types.ErrorPointsDoesNotExist
+	}
+
+	if update.ExpectedPoints != nil && *update.ExpectedPoints != oldPoints {
+		return nil, types.ErrorPointsChanged
+	}
+
+	if !utility.CanChangePoints(policy, oldPoints, userpoint.Points) {
+		return nil, types.ErrorNotPermittedByPolicy
+	}
+
+	av, err := dynamodbattribute.MarshalMap(result)
+	if err != nil {
+		return nil, types.ErrorCouldNotMarshalItem
+	}
+
+	//updating user point in dynamo, unless it changed since it was read
+	input := &dynamodb.PutItemInput{
+		Item:                av,
+		TableName:           aws.String(tableName),
+		ConditionExpression: aws.String("points = :points"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":points": {N: aws.String(strconv.Itoa(oldPoints))},
+		},
+	}
+	_, err = dynaClient.PutItem(input)
+	var conditionFailed *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, types.ErrorPointsChanged
+	}
+	if err != nil {
+		return nil, types.ErrorCouldNotDynamoPutItem
+	}
+
+	//logging
+	if logErr := utility.SendUpdatePointLogs(req, dynaClient, userTable, logTable, ttl, user_id, oldPoints, userpoint.Points); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return result, nil
+}
+
+func FetchUserPoint(user_id string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*[]types.UserPoint, error) {
+	//get single user point
+	input := &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"user_id": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(user_id),
+					},
+				},
+			},
+		},
+	}
+
+	result, err := dynaClient.Query(input)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecord
+	}
+
+	if result.Items == nil {
+		return nil, types.ErrorUserDoesNotExist
+	}
+
+	item := new([]types.UserPoint)
+	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, item)
+	if err != nil {
+		return nil, types.ErrorFailedToUnmarshalRecord
+	}
+
+	return item, nil
+}
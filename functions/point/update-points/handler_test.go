@@ -0,0 +1,85 @@
+package updatepoints
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	openID   = "1c9e2f4a-6b3d-4e8f-9a0b-2c4d6e8f0a1b"
+	closedID = "2d0f3a5b-7c4e-4f9a-8b1c-3d5e7f9a1b2c"
+	frozenID = "3e1a4b6c-8d5f-4a0b-9c2d-4e6f8a0b2c3d"
+)
+
+func TestUpdateUserPoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		body       string
+		policy     *types.Policy
+		wantErr    error
+		wantPoints int
+	}{
+		{name: "adjusts the balance", body: `{"points_id":"` + openID + `","points":150}`, wantPoints: 150},
+		{name: "closed account", body: `{"points_id":"` + closedID + `","points":150}`, wantErr: types.ErrorPointsAccountClosed},
+		{name: "frozen account", body: `{"points_id":"` + frozenID + `","points":150}`, wantErr: types.ErrorPointsAccountFrozen},
+		{name: "unknown account", body: `{"points_id":"4f2b5c7d-9e6a-4b1c-8d3e-5f7a9b1c3d4e","points":150}`,
+			wantErr: types.ErrorPointsDoesNotExist},
+		{name: "deleted user", userID: "2", body: `{"points_id":"` + openID + `","points":150}`,
+			wantErr: types.ErrorUserAlreadyDeleted},
+		{name: "change above the policy limit", body: `{"points_id":"` + openID + `","points":150}`,
+			policy: &types.Policy{MaxPointsChange: 10}, wantErr: types.ErrorNotPermittedByPolicy},
+		{name: "expected balance", body: `{"points_id":"` + openID + `","points":150,"expected_points":100}`,
+			wantPoints: 150},
+		{name: "balance changed since it was read", body: `{"points_id":"` + openID + `","points":150,"expected_points":90}`,
+			wantErr: types.ErrorPointsChanged},
+		{name: "change within the policy limit", body: `{"points_id":"` + openID + `","points":90}`,
+			policy: &types.Policy{MaxPointsChange: 10}, wantPoints: 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "users",
+				types.User{User_ID: "1", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+				types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDeleted})
+			db.Seed(t, "points",
+				types.UserPoint{User_ID: "1", Points_ID: openID, Points: 100},
+				types.UserPoint{User_ID: "1", Points_ID: closedID, Status: types.PointsStatusClosed},
+				types.UserPoint{User_ID: "1", Points_ID: frozenID, Points: 100, Status: types.PointsStatusFrozen},
+				types.UserPoint{User_ID: "2", Points_ID: openID, Points: 100})
+			req := events.APIGatewayProxyRequest{Body: tt.body, QueryStringParameters: map[string]string{"requester": "Ada-Admin"}}
+
+			if tt.userID == "" {
+				tt.userID = "1"
+			}
+			_, err := UpdateUserPoint(tt.userID, req, "points", "users", "logs", "30", db, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateUserPoint() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var points []types.UserPoint
+			db.Load(t, "points", &points)
+			var logs []types.Log
+			db.Load(t, "logs", &logs)
+			if tt.wantErr != nil {
+				if points[0].Points != 100 || len(logs) != 0 {
+					t.Errorf("rejected update changed %+v or logged %+v", points[0], logs)
+				}
+				return
+			}
+			if points[0].Points != tt.wantPoints {
+				t.Errorf("points = %d, want %d", points[0].Points, tt.wantPoints)
+			}
+			want := "Ada Admin adjusted points of Jane Doe from 100 to " + strconv.Itoa(tt.wantPoints)
+			if len(logs) != 1 || logs[0].Description != want {
+				t.Errorf("logs = %+v, want %q", logs, want)
+			}
+		})
+	}
+}
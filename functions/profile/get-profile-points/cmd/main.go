@@ -0,0 +1,12 @@
+package main
+
+import (
+	getprofilepoints "ascenda/functions/profile/get-profile-points"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getprofilepoints.Handler))
+}
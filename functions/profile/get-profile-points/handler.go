@@ -0,0 +1,47 @@
+package getprofilepoints
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves GET /me/points.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamPointsTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	POINTS_TABLE := cfg.PointsTable
+
+	//identity comes from the verified token, never from the request
+	userID, err := utility.UserIDFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	res, err := FetchProfilePoints(userID, USER_TABLE, POINTS_TABLE, deps.Dynamo)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	return utility.JSON(200, res), nil
+}
+
+// FetchProfilePoints returns the points accounts of the caller.
+func FetchProfilePoints(userID string, userTable string, pointsTable string,
+	dynaClient dynamodbiface.DynamoDBAPI) ([]types.UserPoint, error) {
+	user, err := utility.FetchUserByID(userID, userTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if utility.IsUserDeleted(*user) {
+		return nil, types.ErrorUserDoesNotExist
+	}
+
+	return utility.FetchPointsByUser(userID, pointsTable, dynaClient)
+}
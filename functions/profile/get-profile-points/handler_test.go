@@ -0,0 +1,48 @@
+package getprofilepoints
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"testing"
+)
+
+func TestFetchProfilePoints(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "users",
+		types.User{User_ID: "1", Role: "customer"},
+		types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDeleted})
+	db.Seed(t, "points",
+		types.UserPoint{User_ID: "1", Points_ID: "a", Points: 10},
+		types.UserPoint{User_ID: "1", Points_ID: "b", Points: 20},
+		types.UserPoint{User_ID: "2", Points_ID: "c", Points: 30},
+		types.UserPoint{User_ID: "3", Points_ID: "d", Points: 40})
+
+	tests := []struct {
+		name    string
+		id      string
+		wantLen int
+		wantErr error
+	}{
+		{name: "own accounts", id: "1", wantLen: 2},
+		{name: "deleted user", id: "2", wantErr: types.ErrorUserDoesNotExist},
+		{name: "missing user", id: "3", wantErr: types.ErrorUserDoesNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := FetchProfilePoints(tt.id, "users", "points", db)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchProfilePoints() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(points) != tt.wantLen {
+				t.Errorf("points = %+v, want %d accounts", points, tt.wantLen)
+			}
+			for _, point := range points {
+				if point.User_ID != tt.id {
+					t.Errorf("returned the account %+v of another user", point)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	getprofile "ascenda/functions/profile/get-profile"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getprofile.Handler))
+}
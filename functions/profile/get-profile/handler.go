@@ -0,0 +1,44 @@
+package getprofile
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves GET /me.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+
+	//identity comes from the verified token, never from the request
+	userID, err := utility.UserIDFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	res, err := FetchProfile(userID, USER_TABLE, deps.Dynamo)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	return utility.JSON(200, res), nil
+}
+
+// FetchProfile returns the caller's own user, treating deleted users as missing.
+func FetchProfile(userID string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.User, error) {
+	user, err := utility.FetchUserByID(userID, tableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if utility.IsUserDeleted(*user) {
+		return nil, types.ErrorUserDoesNotExist
+	}
+	return user, nil
+}
@@ -0,0 +1,43 @@
+package getprofile
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "users",
+		types.User{User_ID: "1", FirstName: "Jane", Role: "customer"},
+		types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDeleted})
+	deps := &utility.Deps{Dynamo: db, Config: utility.StaticConfig{UserTable: "users"}}
+
+	tests := []struct {
+		name       string
+		authorizer map[string]interface{}
+		wantStatus int
+		wantBody   string
+	}{
+		{"own profile", map[string]interface{}{utility.UserIDContextKey: "1"}, 200, `"first_name":"Jane"`},
+		{"deleted user", map[string]interface{}{utility.UserIDContextKey: "2"}, 404, ""},
+		{"not authenticated", nil, 401, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := events.APIGatewayProxyRequest{RequestContext: events.APIGatewayProxyRequestContext{Authorizer: tt.authorizer}}
+			res, err := Handler(deps, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.wantStatus || !strings.Contains(res.Body, tt.wantBody) {
+				t.Errorf("response = %d %s, want %d containing %q", res.StatusCode, res.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
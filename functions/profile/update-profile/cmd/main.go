@@ -0,0 +1,12 @@
+package main
+
+import (
+	updateprofile "ascenda/functions/profile/update-profile"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), updateprofile.Handler))
+}
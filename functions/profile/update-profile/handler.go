@@ -0,0 +1,92 @@
+package updateprofile
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves PATCH /me.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamEmailsTable, utility.ParamLogsTable, utility.ParamTTL,
+		utility.ParamUserPoolID, utility.ParamReconciliationQueueURL,
+	)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	EMAILS_TABLE := cfg.EmailsTable
+	LOGS_TABLE := cfg.LogsTable
+	TTL := cfg.TTL
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	//identity comes from the verified token, never from the request
+	userID, err := utility.UserIDFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	res, err := UpdateProfile(userID, request, USER_TABLE, EMAILS_TABLE, LOGS_TABLE, TTL, deps.Dynamo, deps.Cognito, USER_POOL_ID, reconciler)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	return utility.JSON(200, res), nil
+}
+
+// UpdateProfile changes the caller's own first and last name. Any other field
+// in the body is rejected, users cannot change their email or role.
+func UpdateProfile(userID string, req events.APIGatewayProxyRequest, tableName string, emailsTable string, logTable string,
+	ttl string, dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	userPoolID string, reconciler utility.Reconciler) (*types.User, error) {
+	//decode body into profile patch, rejecting fields users cannot change
+	patch, err := utility.Decode[types.ProfilePatch](req, validation.ProfilePatch)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := FetchProfile(userID, tableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	user := utility.ApplyUserPatch(*current, types.UserPatch{FirstName: patch.FirstName, LastName: patch.LastName})
+
+	fields := utility.ChangedUserFields(*current, user)
+	if len(fields) == 0 {
+		return &user, nil
+	}
+
+	if err := utility.SaveUserChanges(*current, user, tableName, emailsTable, userPoolID, dynaClient, cognitoClient, reconciler); err != nil {
+		return nil, err
+	}
+
+	//logging
+	if logErr := utility.SendUpdateUserLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName,
+		fields); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return &user, nil
+}
+
+// FetchProfile returns the caller's own user, treating deleted users as missing.
+func FetchProfile(userID string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.User, error) {
+	user, err := utility.FetchUserByID(userID, tableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if utility.IsUserDeleted(*user) {
+		return nil, types.ErrorUserDoesNotExist
+	}
+	return user, nil
+}
@@ -0,0 +1,90 @@
+package updateprofile
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo holds the caller's user and records writes and log descriptions.
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	writes int
+	logs   []string
+}
+
+func (f *fakeDynamo) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"user_id":    {S: aws.String("1")},
+		"email":      {S: aws.String("jane@example.com")},
+		"first_name": {S: aws.String("Jane")},
+		"last_name":  {S: aws.String("Doe")},
+		"role":       {S: aws.String("customer")},
+	}}, nil
+}
+
+func (f *fakeDynamo) TransactWriteItems(*dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	f.writes++
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (f *fakeDynamo) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	f.logs = append(f.logs, *input.Item["description"].S)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+func (f *fakeCognito) AdminUpdateUserAttributes(*cognitoidentityprovider.AdminUpdateUserAttributesInput) (*cognitoidentityprovider.AdminUpdateUserAttributesOutput, error) {
+	return &cognitoidentityprovider.AdminUpdateUserAttributesOutput{}, nil
+}
+
+func TestUpdateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+		wantLog string
+	}{
+		{"name change logged as self", `{"last_name":"Smith"}`, nil, "1 updated last_name for Jane Smith"},
+		{"email cannot be changed", `{"email":"new@example.com"}`, types.ErrorValidationFailed, ""},
+		{"role cannot be changed", `{"role":"admin"}`, types.ErrorValidationFailed, ""},
+		{"blank name", `{"first_name":""}`, types.ErrorValidationFailed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynaClient := &fakeDynamo{}
+			req := events.APIGatewayProxyRequest{Body: tt.body, RequestContext: events.APIGatewayProxyRequestContext{
+				Authorizer: map[string]interface{}{utility.UserIDContextKey: "1"},
+			}}
+
+			_, err := UpdateProfile("1", req, "users", "emails", "logs", "30", dynaClient, &fakeCognito{}, "pool", nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateProfile() error = %v, want %s", err, tt.wantErr)
+				}
+				if dynaClient.writes != 0 {
+					t.Error("rejected profile update was written")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateProfile() error = %v", err)
+			}
+			if len(dynaClient.logs) != 1 || dynaClient.logs[0] != tt.wantLog {
+				t.Errorf("logs = %v, want %q", dynaClient.logs, tt.wantLog)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	createroles "ascenda/functions/role/create-roles"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), createroles.Handler))
+}
@@ -0,0 +1,64 @@
+package createroles
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves POST /roles.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamRolesTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	ROLES_TABLE := cfg.RolesTable
+
+	//calling create role in dynamo func
+	res, err := CreateRole(request, ROLES_TABLE, deps.Dynamo)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	return utility.JSON(200, res), nil
+}
+
+func CreateRole(req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (
+	*types.Role,
+	error,
+) {
+	//decode body into role
+	role, err := utility.Decode[types.Role](req, validation.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	//check inherited roles exist and do not form a cycle
+	if err := utility.ValidateRoleInheritance(role, tableName, dynaClient); err != nil {
+		return nil, err
+	}
+
+	//putting role into dynamo
+	av, err := utility.MarshalRole(role)
+
+	if err != nil {
+		return nil, types.ErrorCouldNotMarshalItem
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName),
+	}
+
+	_, err = dynaClient.PutItem(input)
+	if err != nil {
+		return nil, types.ErrorCouldNotDynamoPutItem
+	}
+
+	return &role, nil
+}
@@ -0,0 +1,38 @@
+package createroles
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestCreateRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{"new role", `{"role":"support","inherits":["viewer"],"access":{"/users":["GET"]}}`, nil},
+		{"missing parent", `{"role":"support","inherits":["pirate"]}`, types.ErrorParentRoleDoesNotExist},
+		{"inherits itself", `{"role":"support","inherits":["support"]}`, types.ErrorRoleInheritanceCycle},
+		{"unknown method", `{"role":"support","access":{"/users":["FETCH"]}}`, types.ErrorValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "roles", types.Role{Role: "viewer"})
+
+			_, err := CreateRole(events.APIGatewayProxyRequest{Body: tt.body}, "roles", db)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateRole() error = %v, want %v", err, tt.wantErr)
+			}
+			if created := len(db.Items("roles")) == 2; created != (tt.wantErr == nil) {
+				t.Errorf("roles = %s", db)
+			}
+		})
+	}
+}
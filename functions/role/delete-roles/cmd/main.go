@@ -0,0 +1,12 @@
+package main
+
+import (
+	deleteroles "ascenda/functions/role/delete-roles"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), deleteroles.Handler))
+}
@@ -0,0 +1,126 @@
+package deleteroles
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves DELETE /roles.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	role := request.QueryStringParameters["role"]
+	reassignTo := request.QueryStringParameters["reassign_to"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamRolesTable, utility.ParamUserTable, utility.ParamUserPoolID,
+		utility.ParamReconciliationQueueURL)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	ROLES_TABLE := cfg.RolesTable
+	USER_TABLE := cfg.UserTable
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	//check if role is supplied, if yes call delete role dynamo func
+	if len(role) > 0 {
+		err := DeleteRole(role, reassignTo, ROLES_TABLE, USER_TABLE, USER_POOL_ID, deps.Dynamo, deps.Cognito, reconciler)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+		return utility.Text(200, "Record successfully deleted"), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("role")), nil
+}
+
+// DeleteRole deletes the role once no user holds it and no role inherits from
+// it. Users still holding the role are moved to reassignTo when it is given;
+// otherwise they are listed, with the inheriting roles, in the details of an
+// ErrorRoleInUse and the role is left in place. Each user is moved by its own
+// saga, and the role is only deleted once all of them moved, so a failed
+// deletion can be retried to move the rest.
+func DeleteRole(id string, reassignTo string, tableName string, userTable string, userPoolID string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	reconciler utility.Reconciler) error {
+	//checking if role exist
+	exists, err := utility.RoleExists(id, tableName, dynaClient)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return types.ErrorRoleDoesNotExist
+	}
+
+	//checking if any role or user still depends on the role
+	inheriting, err := utility.FetchInheritingRoles(id, tableName, dynaClient)
+	if err != nil {
+		return err
+	}
+	users, err := utility.FetchAllUsersByRole(id, userTable, dynaClient)
+	if err != nil {
+		return err
+	}
+
+	if len(inheriting) > 0 || (len(users) > 0 && len(reassignTo) == 0) {
+		//users are only blocking when they cannot be reassigned
+		blocking := users
+		if len(reassignTo) > 0 {
+			blocking = []types.User{}
+		}
+		return types.ErrorRoleInUse.WithDetails(types.RoleInUseData{Users: blocking, Roles: inheriting})
+	}
+
+	if len(users) > 0 {
+		if reassignTo == id {
+			return types.ErrorInvalidReassignRole
+		}
+		exists, err := utility.RoleExists(reassignTo, tableName, dynaClient)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return types.ErrorInvalidReassignRole
+		}
+
+		var reassignErr error
+		for _, user := range users {
+			err := utility.ReassignUserRole(user, reassignTo, userTable, userPoolID, dynaClient, cognitoClient, reconciler)
+			if err != nil {
+				log.Println("failed to reassign user", user.User_ID, err)
+				if reassignErr == nil {
+					reassignErr = err
+				}
+			}
+		}
+		if reassignErr != nil {
+			return reassignErr
+		}
+	}
+
+	//attempt to delete role in dynamo
+	input := &dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"role": {
+				S: aws.String(id),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+	_, err = dynaClient.DeleteItem(input)
+	if err != nil {
+		return types.ErrorCouldNotDeleteItem
+	}
+
+	return nil
+}
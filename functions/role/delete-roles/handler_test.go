@@ -0,0 +1,114 @@
+package deleteroles
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+// fakeCognito records the users whose attributes it updates, failing for the
+// user fail.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	fail    string
+	updated []string
+}
+
+func (f *fakeCognito) AdminUpdateUserAttributes(input *cognitoidentityprovider.AdminUpdateUserAttributesInput) (*cognitoidentityprovider.AdminUpdateUserAttributesOutput, error) {
+	if *input.Username == f.fail {
+		return nil, errors.New(cognitoidentityprovider.ErrCodeInternalErrorException)
+	}
+	f.updated = append(f.updated, *input.Username)
+	return &cognitoidentityprovider.AdminUpdateUserAttributesOutput{}, nil
+}
+
+// fakeReconciler records the items it is sent.
+type fakeReconciler struct {
+	items []types.ReconciliationItem
+}
+
+func (f *fakeReconciler) Enqueue(item types.ReconciliationItem) error {
+	f.items = append(f.items, item)
+	return nil
+}
+
+func TestDeleteRole(t *testing.T) {
+	allRoles := []string{"admin", "auditor", "support", "viewer"}
+	tests := []struct {
+		name          string
+		role          string
+		reassignTo    string
+		failCognito   string
+		wantErr       error
+		wantBlocking  types.RoleInUseData
+		wantRoles     []string
+		wantUserRoles []string
+	}{
+		{name: "unused role", role: "auditor", wantRoles: []string{"admin", "support", "viewer"}},
+		{name: "role in use", role: "support", wantErr: types.ErrorRoleInUse,
+			wantBlocking: types.RoleInUseData{Users: []types.User{{User_ID: "1", Role: "support"},
+				{User_ID: "2", Role: "support"}}}, wantRoles: allRoles},
+		{name: "inherited role", role: "viewer", wantErr: types.ErrorRoleInUse,
+			wantBlocking: types.RoleInUseData{Users: []types.User{}, Roles: []string{"auditor"}}, wantRoles: allRoles},
+		{name: "inherited role with users to reassign", role: "viewer", reassignTo: "admin", wantErr: types.ErrorRoleInUse,
+			wantBlocking: types.RoleInUseData{Users: []types.User{}, Roles: []string{"auditor"}}, wantRoles: allRoles},
+		{name: "reassigns users", role: "support", reassignTo: "viewer", wantRoles: []string{"admin", "auditor", "viewer"},
+			wantUserRoles: []string{"viewer", "viewer", "admin"}},
+		{name: "reassign fails for one user", role: "support", reassignTo: "viewer", failCognito: "1",
+			wantErr: types.ErrorCognitoActionFailed, wantRoles: allRoles,
+			wantUserRoles: []string{"support", "viewer", "admin"}},
+		{name: "reassign to itself", role: "support", reassignTo: "support", wantErr: types.ErrorInvalidReassignRole,
+			wantRoles: allRoles},
+		{name: "reassign to missing role", role: "support", reassignTo: "pirate", wantErr: types.ErrorInvalidReassignRole,
+			wantRoles: allRoles},
+		{name: "missing role", role: "pirate", wantErr: types.ErrorRoleDoesNotExist, wantRoles: allRoles},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "roles", types.Role{Role: "admin"}, types.Role{Role: "support"}, types.Role{Role: "viewer"},
+				types.Role{Role: "auditor", Inherits: []string{"viewer"}})
+			db.Seed(t, "users",
+				types.User{User_ID: "1", Role: "support"},
+				types.User{User_ID: "2", Role: "support"},
+				types.User{User_ID: "3", Role: "admin"})
+			cognitoClient := &fakeCognito{fail: tt.failCognito}
+
+			err := DeleteRole(tt.role, tt.reassignTo, "roles", "users", "pool", db, cognitoClient, &fakeReconciler{})
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("DeleteRole() error = %v, want %v", err, tt.wantErr)
+			}
+			var typed *types.Error
+			if errors.As(err, &typed) && errors.Is(err, types.ErrorRoleInUse) &&
+				!reflect.DeepEqual(typed.Details, tt.wantBlocking) {
+				t.Errorf("blocking = %+v, want %+v", typed.Details, tt.wantBlocking)
+			}
+
+			var roles []string
+			for _, item := range db.Items("roles") {
+				roles = append(roles, *item["role"].S)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", roles, tt.wantRoles)
+			}
+
+			if tt.wantUserRoles != nil {
+				var users []types.User
+				db.Load(t, "users", &users)
+				var userRoles []string
+				for _, user := range users {
+					userRoles = append(userRoles, user.Role)
+				}
+				if !reflect.DeepEqual(userRoles, tt.wantUserRoles) {
+					t.Errorf("user roles = %v, want %v", userRoles, tt.wantUserRoles)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	getroles "ascenda/functions/role/get-roles"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getroles.Handler))
+}
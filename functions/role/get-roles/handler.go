@@ -0,0 +1,126 @@
+package getroles
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves GET /roles.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//get variables
+	id := request.QueryStringParameters["role"]
+	effective := request.QueryStringParameters["effective"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamRolesTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	ROLES_TABLE := cfg.RolesTable
+
+	//check if effective access requested, if yes merge in inherited roles
+	if len(id) > 0 && effective == "true" {
+		res, err := utility.EffectiveRole(id, ROLES_TABLE, deps.Dynamo)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+		return utility.JSON(200, res), nil
+	}
+
+	//check if role specified, if yes get single role from dynamo
+	if len(id) > 0 {
+		res, err := FetchRoleByID(id, ROLES_TABLE, deps.Dynamo)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+		return utility.JSON(200, res), nil
+	}
+
+	//check if id specified, if no get all roles from dynamo
+	res, err := FetchRoles(request, ROLES_TABLE, deps.Dynamo)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	return utility.JSON(200, res), nil
+}
+
+func FetchRoleByID(id string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.Role, error) {
+	//get single role from dynamo
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"role": {
+				S: aws.String(id),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(input)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+
+	if result.Item == nil {
+		return nil, types.ErrorRoleDoesNotExist
+	}
+
+	item := new(types.Role)
+	err = dynamodbattribute.UnmarshalMap(result.Item, item)
+	if err != nil {
+		return nil, types.ErrorFailedToUnmarshalRecord
+	}
+
+	return item, nil
+}
+
+func FetchRoles(req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.ReturnRoleData, error) {
+	//get all roles with pagination of limit 100
+	key := req.QueryStringParameters["key"]
+	lastEvaluatedKey := make(map[string]*dynamodb.AttributeValue)
+
+	item := new([]types.Role)
+	itemWithKey := new(types.ReturnRoleData)
+
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+		Limit:     aws.Int64(int64(100)),
+	}
+
+	if len(key) != 0 {
+		lastEvaluatedKey["role"] = &dynamodb.AttributeValue{
+			S: aws.String(key),
+		}
+		input.ExclusiveStartKey = lastEvaluatedKey
+	}
+
+	result, err := dynaClient.Scan(input)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecord
+	}
+
+	for _, i := range result.Items {
+		role := new(types.Role)
+		err := dynamodbattribute.UnmarshalMap(i, role)
+		if err != nil {
+			return nil, err
+		}
+		*item = append(*item, *role)
+	}
+
+	itemWithKey.Data = *item
+
+	if len(result.LastEvaluatedKey) == 0 {
+		return itemWithKey, nil
+	}
+
+	itemWithKey.Key = *result.LastEvaluatedKey["role"].S
+
+	return itemWithKey, nil
+}
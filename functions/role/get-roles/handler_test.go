@@ -0,0 +1,64 @@
+package getroles
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "roles",
+		types.Role{Role: "admin", Inherits: []string{"viewer"}, Access: map[string][]string{"/users": {"POST"}}},
+		types.Role{Role: "viewer", Access: map[string][]string{"/users": {"GET"}}})
+	deps := &utility.Deps{Dynamo: db, Config: utility.StaticConfig{RolesTable: "roles"}}
+
+	tests := []struct {
+		name       string
+		params     map[string]string
+		wantStatus int
+		wantAccess map[string][]string
+	}{
+		{"single role", map[string]string{"role": "admin"}, 200, map[string][]string{"/users": {"POST"}}},
+		{"effective role", map[string]string{"role": "admin", "effective": "true"}, 200, map[string][]string{"/users": {"POST", "GET"}}},
+		{"missing role", map[string]string{"role": "pirate"}, 404, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Handler(deps, events.APIGatewayProxyRequest{QueryStringParameters: tt.params})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", res.StatusCode, tt.wantStatus, res.Body)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			var role types.Role
+			json.Unmarshal([]byte(res.Body), &role)
+			if !reflect.DeepEqual(role.Access, tt.wantAccess) {
+				t.Errorf("access = %v, want %v", role.Access, tt.wantAccess)
+			}
+		})
+	}
+}
+
+func TestFetchRoles(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "roles", types.Role{Role: "admin"}, types.Role{Role: "viewer"})
+
+	res, err := FetchRoles(events.APIGatewayProxyRequest{}, "roles", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data) != 2 || res.Key != "" {
+		t.Errorf("roles = %+v, key %q, want both roles on one page", res.Data, res.Key)
+	}
+}
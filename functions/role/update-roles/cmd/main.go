@@ -0,0 +1,12 @@
+package main
+
+import (
+	updateroles "ascenda/functions/role/update-roles"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), updateroles.Handler))
+}
@@ -0,0 +1,93 @@
+package updateroles
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves PUT /roles.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	role := request.QueryStringParameters["role"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamRolesTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	ROLES_TABLE := cfg.RolesTable
+
+	//checking if role is specified, if yes then update role in dynamo func
+	if len(role) > 0 {
+		res, err := UpdateRole(role, request, ROLES_TABLE, deps.Dynamo)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+
+		return utility.JSON(200, res), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("role")), nil
+
+}
+
+func UpdateRole(id string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.Role, error) {
+	//decode body into role struct
+	role, err := utility.Decode[types.Role](req, validation.RoleUpdate)
+	if err != nil {
+		return nil, err
+	}
+	role.Role = id
+
+	if role.Role == "" {
+		err := types.ErrorInvalidRole
+		return nil, err
+	}
+
+	//checking if role exist
+	checkRole := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"role": {
+				S: aws.String(id),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(checkRole)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+
+	if result.Item == nil {
+		return nil, types.ErrorRoleDoesNotExist
+	}
+
+	//check inherited roles exist and do not form a cycle
+	if err := utility.ValidateRoleInheritance(role, tableName, dynaClient); err != nil {
+		return nil, err
+	}
+
+	av, err := utility.MarshalRole(role)
+	if err != nil {
+		return nil, types.ErrorCouldNotMarshalItem
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName),
+	}
+
+	_, err = dynaClient.PutItem(input)
+	if err != nil {
+		return nil, types.ErrorCouldNotDynamoPutItem
+	}
+
+	return &role, nil
+}
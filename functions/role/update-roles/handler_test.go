@@ -0,0 +1,53 @@
+package updateroles
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestUpdateRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		body    string
+		wantErr error
+	}{
+		{"replaces the role", "viewer", `{"access":{"/users":["GET","PUT"]}}`, nil},
+		{"missing role", "pirate", `{"access":{}}`, types.ErrorRoleDoesNotExist},
+		{"inheritance cycle", "viewer", `{"inherits":["admin"]}`, types.ErrorRoleInheritanceCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			seeded := []types.Role{
+				{Role: "admin", Inherits: []string{"viewer"}},
+				{Role: "viewer", Access: map[string][]string{"/users": {"GET"}}},
+			}
+			db.Seed(t, "roles", seeded[0], seeded[1])
+			req := events.APIGatewayProxyRequest{Body: tt.body}
+
+			_, err := UpdateRole(tt.id, req, "roles", db)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateRole() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var roles []types.Role
+			db.Load(t, "roles", &roles)
+			if tt.wantErr != nil {
+				if !reflect.DeepEqual(roles, seeded) {
+					t.Errorf("roles = %+v, want them unchanged", roles)
+				}
+				return
+			}
+			if want := []string{"GET", "PUT"}; !reflect.DeepEqual(roles[1].Access["/users"], want) {
+				t.Errorf("viewer = %+v, want access %v", roles[1], want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	createusers "ascenda/functions/user/create-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), createusers.Handler))
+}
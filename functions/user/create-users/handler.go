@@ -0,0 +1,86 @@
+package createusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/google/uuid"
+)
+
+// Handler serves POST /users.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamEmailsTable, utility.ParamTTL, utility.ParamLogsTable,
+		utility.ParamRolesTable, utility.ParamUserPoolID, utility.ParamReconciliationQueueURL,
+		utility.ParamAllowAdminPasswords,
+	)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	EMAILS_TABLE := cfg.EmailsTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	ROLES_TABLE := cfg.RolesTable
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+	ALLOW_ADMIN_PASSWORDS := cfg.AllowAdminPasswords
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	res, err := CreateUser(request, USER_TABLE, EMAILS_TABLE, LOGS_TABLE, ROLES_TABLE, TTL, deps.Dynamo, deps.Cognito, deps.SES, USER_POOL_ID,
+		ALLOW_ADMIN_PASSWORDS, reconciler)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	return utility.JSON(200, res), nil
+}
+
+// CreateUser provisions the user in dynamo and cognito. Cognito emails the user
+// a temporary password unless allowAdminPasswords lets the request choose a
+// permanent one.
+func CreateUser(req events.APIGatewayProxyRequest, tableName string, emailsTable string, logTABLE string, rolesTable string, ttl string, dynaClient dynamodbiface.DynamoDBAPI,
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI, sesClient sesiface.SESAPI, userPoolID string, allowAdminPasswords bool, reconciler utility.Reconciler) (
+	*types.User,
+	error,
+) {
+	//decode body into user
+	user, err := utility.Decode[types.CognitoUser](req, validation.User)
+	if err != nil {
+		return nil, err
+	}
+
+	//error checks
+	if len(user.Password) > 0 && !allowAdminPasswords {
+		return nil, types.ErrorAdminPasswordsDisabled
+	}
+	exists, err := utility.RoleExists(user.Role, rolesTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, types.ErrorUnknownRole
+	}
+	user.User_ID = uuid.NewString()
+
+	//create the user in dynamo then cognito, undoing earlier steps on failure
+	if err := utility.ProvisionUser(user, tableName, emailsTable, userPoolID, dynaClient, cognitoClient, reconciler); err != nil {
+		return nil, err
+	}
+
+	utility.EmailVerification(user.Email, sesClient)
+
+	//logging
+	if logErr := utility.SendCreateUserLogs(req, dynaClient, logTABLE, ttl, user.FirstName, user.LastName, user.Role); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return user.User, nil
+}
@@ -0,0 +1,271 @@
+package createusers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+// fakeDynamo knows a single role and records user writes and email guards.
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	users     map[string]bool
+	emails    map[string]string
+	deleteErr error
+}
+
+func (f *fakeDynamo) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	if role, ok := input.Key["role"]; ok && *role.S == "customer" {
+		return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{"role": role}}, nil
+	}
+	if email, ok := input.Key["email"]; ok {
+		if userID, ok := f.emails[*email.S]; ok {
+			return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+				"email":   email,
+				"user_id": {S: aws.String(userID)},
+			}}, nil
+		}
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func (f *fakeDynamo) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	//check every guard before applying anything, as dynamo would
+	reasons := make([]*dynamodb.CancellationReason, len(input.TransactItems))
+	cancelled := false
+	for i, item := range input.TransactItems {
+		reasons[i] = &dynamodb.CancellationReason{Code: aws.String("None")}
+		if item.Put != nil && item.Put.Item["email"] != nil {
+			if owner, ok := f.emails[*item.Put.Item["email"].S]; ok && owner != *item.Put.Item["user_id"].S {
+				reasons[i].Code = aws.String("ConditionalCheckFailed")
+				cancelled = true
+			}
+		}
+	}
+	if cancelled {
+		return nil, &dynamodb.TransactionCanceledException{CancellationReasons: reasons}
+	}
+
+	for _, item := range input.TransactItems {
+		switch {
+		case item.Put != nil && item.Put.Item["email"] != nil && len(item.Put.Item) == 2:
+			f.emails[*item.Put.Item["email"].S] = *item.Put.Item["user_id"].S
+		case item.Put != nil:
+			f.users[*item.Put.Item["user_id"].S] = true
+		case item.Delete != nil && item.Delete.Key["email"] != nil:
+			delete(f.emails, *item.Delete.Key["email"].S)
+		case item.Delete != nil:
+			if f.deleteErr != nil {
+				return nil, f.deleteErr
+			}
+			delete(f.users, *item.Delete.Key["user_id"].S)
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// fakeCognito rejects every user it is asked to create, with err if set.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	err error
+}
+
+func (f *fakeCognito) AdminCreateUser(*cognitoidentityprovider.AdminCreateUserInput) (*cognitoidentityprovider.AdminCreateUserOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return nil, errors.New("cognito unavailable")
+}
+
+type recordingReconciler struct {
+	items []types.ReconciliationItem
+}
+
+func (r *recordingReconciler) Enqueue(item types.ReconciliationItem) error {
+	r.items = append(r.items, item)
+	return nil
+}
+
+func TestCreateUserCompensatesDynamoWhenCognitoFails(t *testing.T) {
+	req := events.APIGatewayProxyRequest{
+		Body: `{"email":"jane@example.com","first_name":"Jane","last_name":"Doe","role":"customer","password":"Passw0rd!"}`,
+	}
+
+	tests := []struct {
+		name          string
+		deleteErr     error
+		wantUsers     int
+		wantReconcile int
+	}{
+		{"orphan dynamo item removed", nil, 0, 0},
+		{"failed removal queued for reconciliation", errors.New("dynamo unavailable"), 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynaClient := &fakeDynamo{users: map[string]bool{}, emails: map[string]string{}, deleteErr: tt.deleteErr}
+			reconciler := &recordingReconciler{}
+
+			_, err := CreateUser(req, "users", "emails", "logs", "roles", "30", dynaClient, &fakeCognito{}, nil, "pool", true, reconciler)
+			if err == nil {
+				t.Fatal("CreateUser() succeeded, want cognito error")
+			}
+			if len(dynaClient.users) != tt.wantUsers {
+				t.Errorf("dynamo users = %d, want %d", len(dynaClient.users), tt.wantUsers)
+			}
+			if len(reconciler.items) != tt.wantReconcile {
+				t.Errorf("reconciliation items = %d, want %d", len(reconciler.items), tt.wantReconcile)
+			}
+		})
+	}
+}
+
+func TestCreateUserMapsCognitoErrors(t *testing.T) {
+	req := events.APIGatewayProxyRequest{
+		Body: `{"email":"jane@example.com","first_name":"Jane","last_name":"Doe","role":"customer"}`,
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{"alias held by another cognito user", &cognitoidentityprovider.AliasExistsException{}, types.ErrorEmailAlreadyExists},
+		{"username taken", &cognitoidentityprovider.UsernameExistsException{}, types.ErrorEmailAlreadyExists},
+		{"other failure", errors.New("cognito unavailable"), types.ErrorCognitoActionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynaClient := &fakeDynamo{users: map[string]bool{}, emails: map[string]string{}}
+
+			_, err := CreateUser(req, "users", "emails", "logs", "roles", "30", dynaClient, &fakeCognito{err: tt.err}, nil, "pool", true,
+				&recordingReconciler{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateUser() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateUserRejectsUnknownRole(t *testing.T) {
+	req := events.APIGatewayProxyRequest{
+		Body: `{"email":"jane@example.com","first_name":"Jane","last_name":"Doe","role":"ghost"}`,
+	}
+	dynaClient := &fakeDynamo{users: map[string]bool{}, emails: map[string]string{}}
+
+	_, err := CreateUser(req, "users", "emails", "logs", "roles", "30", dynaClient, &fakeCognito{}, nil, "pool", false, nil)
+	if !errors.Is(err, types.ErrorUnknownRole) {
+		t.Fatalf("CreateUser() error = %v, want %s", err, types.ErrorUnknownRole)
+	}
+	if len(dynaClient.users) != 0 {
+		t.Error("user written for unknown role")
+	}
+}
+
+func TestCreateUserRejectsDuplicateEmail(t *testing.T) {
+	req := events.APIGatewayProxyRequest{
+		Body: `{"email":"Jane@Example.com","first_name":"Jane","last_name":"Doe","role":"customer"}`,
+	}
+	dynaClient := &fakeDynamo{users: map[string]bool{}, emails: map[string]string{"jane@example.com": "existing"}}
+
+	_, err := CreateUser(req, "users", "emails", "logs", "roles", "30", dynaClient, &fakeCognito{}, nil, "pool", false, nil)
+	if !errors.Is(err, types.ErrorEmailAlreadyExists) {
+		t.Fatalf("CreateUser() error = %v, want %s", err, types.ErrorEmailAlreadyExists)
+	}
+	if len(dynaClient.users) != 0 {
+		t.Error("user written for duplicate email")
+	}
+	if dynaClient.emails["jane@example.com"] != "existing" {
+		t.Error("email guard taken from existing user")
+	}
+}
+
+func TestCreateUserRejectsAdminPasswordUnlessAllowed(t *testing.T) {
+	req := events.APIGatewayProxyRequest{
+		Body: `{"email":"jane@example.com","first_name":"Jane","last_name":"Doe","role":"customer","password":"Passw0rd!"}`,
+	}
+	dynaClient := &fakeDynamo{users: map[string]bool{}, emails: map[string]string{}}
+
+	_, err := CreateUser(req, "users", "emails", "logs", "roles", "30", dynaClient, &fakeCognito{}, nil, "pool", false, nil)
+	if !errors.Is(err, types.ErrorAdminPasswordsDisabled) {
+		t.Fatalf("CreateUser() error = %v, want %s", err, types.ErrorAdminPasswordsDisabled)
+	}
+	if len(dynaClient.users) != 0 {
+		t.Error("user written with admin chosen password")
+	}
+}
+
+func TestHandlerAnswersConflictForDuplicateEmail(t *testing.T) {
+	deps := &utility.Deps{
+		Dynamo:  &fakeDynamo{users: map[string]bool{}, emails: map[string]string{"jane@example.com": "existing"}},
+		Cognito: &fakeCognito{},
+		Config:  utility.StaticConfig{UserTable: "users", EmailsTable: "emails", RolesTable: "roles", TTL: "30"},
+	}
+	req := events.APIGatewayProxyRequest{
+		Body: `{"email":"jane@example.com","first_name":"Jane","last_name":"Doe","role":"customer"}`,
+	}
+
+	res, err := Handler(deps, req)
+	if err != nil || res.StatusCode != 409 {
+		t.Errorf("Handler() = %d %q, %v, want 409", res.StatusCode, res.Body, err)
+	}
+}
+
+// acceptingCognito creates every user it is asked to.
+type acceptingCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+func (acceptingCognito) AdminCreateUser(*cognitoidentityprovider.AdminCreateUserInput) (*cognitoidentityprovider.AdminCreateUserOutput, error) {
+	return &cognitoidentityprovider.AdminCreateUserOutput{}, nil
+}
+
+type fakeSES struct {
+	sesiface.SESAPI
+}
+
+func (fakeSES) VerifyEmailIdentity(*ses.VerifyEmailIdentityInput) (*ses.VerifyEmailIdentityOutput, error) {
+	return &ses.VerifyEmailIdentityOutput{}, nil
+}
+
+func TestCreateUserStoresUserAndEmailGuard(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "roles", types.Role{Role: "customer"})
+	req := events.APIGatewayProxyRequest{
+		Body:                  `{"email":"Jane@Example.com","first_name":"Jane","last_name":"Doe","role":"customer"}`,
+		QueryStringParameters: map[string]string{"requester": "Ada-Admin"},
+	}
+
+	user, err := CreateUser(req, "users", "emails", "logs", "roles", "30", db, acceptingCognito{}, fakeSES{}, "pool", false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var users []types.User
+	db.Load(t, "users", &users)
+	var emails []map[string]string
+	db.Load(t, "emails", &emails)
+	if len(users) != 1 || users[0].User_ID != user.User_ID || len(emails) != 1 || emails[0]["user_id"] != user.User_ID {
+		t.Errorf("users = %+v, emails = %v, want the user and its email guard", users, emails)
+	}
+
+	//a second user with the same email differing in case is turned away
+	if _, err := CreateUser(req, "users", "emails", "logs", "roles", "30", db, acceptingCognito{}, fakeSES{}, "pool", false, nil); !errors.Is(err, types.ErrorEmailAlreadyExists) {
+		t.Errorf("CreateUser() error = %v, want %s", err, types.ErrorEmailAlreadyExists)
+	}
+	if len(db.Items("users")) != 1 || len(db.Items("logs")) != 1 {
+		t.Errorf("database = %s, want only the first user and its log", db)
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	deleteusers "ascenda/functions/user/delete-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), deleteusers.Handler))
+}
@@ -0,0 +1,135 @@
+package deleteusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves DELETE /users.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	id := request.QueryStringParameters["id"]
+	role := request.QueryStringParameters["role"]
+	hard := request.QueryStringParameters["hard"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamEmailsTable, utility.ParamPointsTable, utility.ParamMakerTable, utility.ParamTTL,
+		utility.ParamLogsTable, utility.ParamUserPoolID, utility.ParamReconciliationQueueURL,
+	)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	EMAILS_TABLE := cfg.EmailsTable
+	POINTS_TABLE := cfg.PointsTable
+	MAKER_TABLE := cfg.MakerTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	if len(id) > 0 {
+		res := DeleteUser(id, role, hard == "true", request, USER_TABLE, EMAILS_TABLE, POINTS_TABLE, MAKER_TABLE, LOGS_TABLE, TTL,
+			deps.Dynamo, deps.Cognito, USER_POOL_ID, policy, reconciler)
+		if res != nil {
+			return utility.Error(request, res), nil
+		}
+		return utility.Text(200, "Record successfully deleted"), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+}
+
+// DeleteUser soft deletes the user, marking them deleted, disabling their
+// cognito login, freezing their points accounts and rejecting pending maker
+// requests targeting them. The user can be restored within the retention window
+// after which the scheduled purge removes them for good. hard removes the user
+// and their points accounts at once, and also applies to soft deleted users.
+func DeleteUser(id string, role string, hard bool, req events.APIGatewayProxyRequest, tableName, emailsTable, pointsTable,
+	makerTable, logTABLE, ttl string, dynaClient dynamodbiface.DynamoDBAPI,
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI, userPoolID string, policy *types.Policy,
+	reconciler utility.Reconciler) error {
+	//check if user exist
+	checkUser := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(id),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(checkUser)
+	if err != nil {
+		return types.ErrorFailedToFetchRecordID
+	}
+
+	var user types.User
+	if result.Item == nil {
+		return types.ErrorUserDoesNotExist
+	}
+
+	err = dynamodbattribute.UnmarshalMap(result.Item, &user)
+	if err != nil {
+		return types.ErrorFailedToUnmarshal
+	}
+
+	if !utility.CanTargetRole(policy, user.Role) {
+		return types.ErrorNotPermittedByPolicy
+	}
+
+	if hard {
+		points, err := utility.HardDeleteUser(user, true, tableName, emailsTable, pointsTable, makerTable, userPoolID, dynaClient,
+			cognitoClient, reconciler)
+		if err != nil {
+			return err
+		}
+
+		//logging
+		for _, point := range points {
+			logErr := utility.SendClosePointsLogs(req, dynaClient, logTABLE, ttl, user.FirstName, user.LastName, point)
+			if logErr != nil {
+				log.Println("Logging err :", logErr)
+			}
+		}
+		logErr := utility.SendUserActionLogs(req, dynaClient, logTABLE, ttl, user.FirstName, user.LastName, "permanently deleted")
+		if logErr != nil {
+			log.Println("Logging err :", logErr)
+		}
+		return nil
+	}
+
+	if utility.IsUserDeleted(user) {
+		return types.ErrorUserAlreadyDeleted
+	}
+
+	err = utility.SoftDeleteUser(user, time.Now().Unix(), tableName, pointsTable, makerTable, userPoolID, dynaClient, cognitoClient,
+		reconciler)
+	if err != nil {
+		return err
+	}
+
+	//logging
+	if logErr := utility.SendDeleteUserLogs(req, dynaClient, logTABLE, ttl, user.FirstName, user.LastName); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package deleteusers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+// fakeCognito records the users it disables and deletes.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	disabled []string
+	deleted  []string
+}
+
+func (f *fakeCognito) AdminDisableUser(input *cognitoidentityprovider.AdminDisableUserInput) (*cognitoidentityprovider.AdminDisableUserOutput, error) {
+	f.disabled = append(f.disabled, *input.Username)
+	return &cognitoidentityprovider.AdminDisableUserOutput{}, nil
+}
+
+func (f *fakeCognito) AdminDeleteUser(input *cognitoidentityprovider.AdminDeleteUserInput) (*cognitoidentityprovider.AdminDeleteUserOutput, error) {
+	f.deleted = append(f.deleted, *input.Username)
+	return &cognitoidentityprovider.AdminDeleteUserOutput{}, nil
+}
+
+func TestDeleteUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		policy  *types.Policy
+		wantErr error
+	}{
+		{name: "soft deletes", id: "1"},
+		{name: "already deleted", id: "2", wantErr: types.ErrorUserAlreadyDeleted},
+		{name: "missing user", id: "3", wantErr: types.ErrorUserDoesNotExist},
+		{name: "role outside policy", id: "1", policy: &types.Policy{TargetRoles: []string{"admin"}},
+			wantErr: types.ErrorNotPermittedByPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "users",
+				types.User{User_ID: "1", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+				types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDeleted, DeletedAt: 1})
+			db.Seed(t, "points",
+				types.UserPoint{User_ID: "1", Points_ID: "open", Points: 40},
+				types.UserPoint{User_ID: "1", Points_ID: "closed", Status: types.PointsStatusClosed})
+			db.Seed(t, "makers", types.MakerRequest{RequestUUID: "r1", CheckerRole: "admin", RequestStatus: "pending",
+				RequestData: json.RawMessage(`{"user_id":"1","points":5}`)})
+			cognitoClient := &fakeCognito{}
+			req := events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"requester": "Ada-Admin"}}
+
+			err := DeleteUser(tt.id, "", false, req, "users", "emails", "points", "makers", "logs", "30", db, cognitoClient, "pool",
+				tt.policy, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(cognitoClient.disabled) != 0 || len(db.Items("logs")) != 0 {
+					t.Error("rejected deletion disabled or logged the user")
+				}
+				return
+			}
+
+			var users []types.User
+			db.Load(t, "users", &users)
+			if users[0].Status != types.UserStatusDeleted || users[0].DeletedAt == 0 {
+				t.Errorf("user = %+v, want deleted with deleted_at", users[0])
+			}
+			var logs []types.Log
+			db.Load(t, "logs", &logs)
+			if len(logs) != 1 || logs[0].Description != "Ada Admin deleted user Jane Doe" {
+				t.Errorf("logs = %+v", logs)
+			}
+			var points []types.UserPoint
+			db.Load(t, "points", &points)
+			for _, point := range points {
+				want := types.PointsStatusFrozen
+				if point.Points_ID == "closed" {
+					want = types.PointsStatusClosed
+				}
+				if point.Status != want || point.Points_ID == "open" && point.Points != 40 {
+					t.Errorf("points %+v, want %s with its balance kept", point, want)
+				}
+			}
+			var requests []types.MakerRequest
+			db.Load(t, "makers", &requests)
+			if requests[0].RequestStatus != "rejected" || requests[0].CheckerUUID != utility.SystemCheckerID {
+				t.Errorf("maker request = %+v, want rejected by the system", requests[0])
+			}
+		})
+	}
+}
+
+func TestHardDeleteUser(t *testing.T) {
+	for _, id := range []string{"1", "2"} {
+		db := dynamotest.New(dynamotest.Tables...)
+		db.Seed(t, "users",
+			types.User{User_ID: "1", FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Role: "customer"},
+			types.User{User_ID: "2", FirstName: "Joe", LastName: "Doe", Email: "joe@example.com", Role: "customer",
+				Status: types.UserStatusDeleted, DeletedAt: 1})
+		db.Seed(t, "points", types.UserPoint{User_ID: id, Points_ID: "p", Points: 40})
+		cognitoClient := &fakeCognito{}
+		req := events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"requester": "Ada-Admin"}}
+
+		err := DeleteUser(id, "", true, req, "users", "emails", "points", "makers", "logs", "30", db, cognitoClient, "pool", nil, nil)
+		if err != nil {
+			t.Fatalf("DeleteUser(%s) error = %v", id, err)
+		}
+		if len(db.Items("users")) != 1 || len(db.Items("points")) != 0 || len(cognitoClient.deleted) != 1 {
+			t.Errorf("user %s: %d users, %d points accounts and cognito deleted %v left, want the user and their points removed",
+				id, len(db.Items("users")), len(db.Items("points")), cognitoClient.deleted)
+		}
+		if logs := db.Items("logs"); len(logs) != 2 {
+			t.Errorf("user %s: logged %d items, want the closed account and the deletion", id, len(logs))
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	disableusers "ascenda/functions/user/disable-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), disableusers.Handler))
+}
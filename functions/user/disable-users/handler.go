@@ -0,0 +1,102 @@
+package disableusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves PUT /users/disable.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamTTL, utility.ParamLogsTable, utility.ParamUserPoolID,
+		utility.ParamReconciliationQueueURL,
+	)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	if len(id) > 0 {
+		res := DisableUser(id, request, USER_TABLE, LOGS_TABLE, TTL, deps.Dynamo, deps.Cognito, USER_POOL_ID, policy, reconciler)
+		if res != nil {
+			return utility.Error(request, res), nil
+		}
+		return utility.Text(200, "User successfully disabled"), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+}
+
+// DisableUser blocks the user from signing in without deleting them. Disabled
+// users are still listed and are re-enabled through restore.
+func DisableUser(id string, req events.APIGatewayProxyRequest, tableName string, logTable string, ttl string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	userPoolID string, policy *types.Policy, reconciler utility.Reconciler) error {
+	//check if user exist
+	checkUser := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(id),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(checkUser)
+	if err != nil {
+		return types.ErrorFailedToFetchRecordID
+	}
+
+	var user types.User
+	if result.Item == nil {
+		return types.ErrorUserDoesNotExist
+	}
+
+	err = dynamodbattribute.UnmarshalMap(result.Item, &user)
+	if err != nil {
+		return types.ErrorFailedToUnmarshal
+	}
+
+	if !utility.CanTargetRole(policy, user.Role) {
+		return types.ErrorNotPermittedByPolicy
+	}
+
+	if utility.IsUserDeleted(user) {
+		return types.ErrorUserAlreadyDeleted
+	}
+
+	err = utility.SetUserStatus(user, types.UserStatusDisabled, 0, tableName, userPoolID, dynaClient, cognitoClient, reconciler)
+	if err != nil {
+		return err
+	}
+
+	//logging
+	if logErr := utility.SendUserActionLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, "disabled"); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return nil
+}
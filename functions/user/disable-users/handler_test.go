@@ -0,0 +1,60 @@
+package disableusers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+// fakeCognito disables users, failing when err is set.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	err error
+}
+
+func (f *fakeCognito) AdminDisableUser(*cognitoidentityprovider.AdminDisableUserInput) (*cognitoidentityprovider.AdminDisableUserOutput, error) {
+	return &cognitoidentityprovider.AdminDisableUserOutput{}, f.err
+}
+
+func TestDisableUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		cognitoErr error
+		wantErr    bool
+		wantStatus string
+	}{
+		{name: "disables", id: "1", wantStatus: types.UserStatusDisabled},
+		{name: "deleted user", id: "2", wantErr: true},
+		{name: "cognito failure restores the status", id: "1", cognitoErr: errors.New("down"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "users",
+				types.User{User_ID: "1", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+				types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDeleted, DeletedAt: 1})
+			req := events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"requester": "Ada-Admin"}}
+
+			err := DisableUser(tt.id, req, "users", "logs", "30", db, &fakeCognito{err: tt.cognitoErr}, "pool", nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DisableUser() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			var users []types.User
+			db.Load(t, "users", &users)
+			if users[0].Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", users[0].Status, tt.wantStatus)
+			}
+			if logged := len(db.Items("logs")) == 1; logged == tt.wantErr {
+				t.Errorf("logged = %v, want %v", logged, !tt.wantErr)
+			}
+		})
+	}
+}
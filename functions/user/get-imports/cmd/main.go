@@ -0,0 +1,12 @@
+package main
+
+import (
+	getimports "ascenda/functions/user/get-imports"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getimports.Handler))
+}
@@ -0,0 +1,49 @@
+package getimports
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Handler serves GET /users/import.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//get variables
+	id := request.QueryStringParameters["id"]
+	format := request.QueryStringParameters["format"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamImportJobsTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	IMPORT_JOBS_TABLE := cfg.ImportJobsTable
+
+	if len(id) == 0 {
+		return utility.Error(request, types.MissingParameter("id")), nil
+	}
+
+	res, err := utility.FetchImportJob(id, IMPORT_JOBS_TABLE, deps.Dynamo)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	//per row results as a downloadable csv
+	if format == "csv" {
+		body, err := utility.ImportResultCSV(res)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+		return events.APIGatewayProxyResponse{
+			Body:       body,
+			StatusCode: 200,
+			Headers: map[string]string{
+				"Content-Type":        "text/csv",
+				"Content-Disposition": "attachment; filename=\"import-" + res.Job_ID + ".csv\"",
+			},
+		}, nil
+	}
+
+	return utility.JSON(200, res), nil
+}
@@ -0,0 +1,44 @@
+package getimports
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "import-jobs", types.ImportJob{Job_ID: "job", Status: types.ImportJobCompleted, Rows: []types.ImportRow{
+		{Line: 2, Email: "new@example.com", FirstName: "New", LastName: "User", Role: "customer",
+			Status: types.ImportRowCreated, User_ID: "1"},
+	}})
+	deps := &utility.Deps{Dynamo: db, Config: utility.StaticConfig{ImportJobsTable: "import-jobs"}}
+
+	tests := []struct {
+		name       string
+		params     map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{"json", map[string]string{"id": "job"}, 200, `"job_id":"job"`},
+		{"csv", map[string]string{"id": "job", "format": "csv"}, 200, "2,new@example.com,New,User,customer,created,1,\n"},
+		{"missing job", map[string]string{"id": "nope"}, 404, ""},
+		{"missing id", nil, 400, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Handler(deps, events.APIGatewayProxyRequest{QueryStringParameters: tt.params})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.wantStatus || !strings.Contains(res.Body, tt.wantBody) {
+				t.Errorf("response = %d %s, want %d containing %q", res.StatusCode, res.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	getsessions "ascenda/functions/user/get-sessions"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getsessions.Handler))
+}
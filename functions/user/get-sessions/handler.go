@@ -0,0 +1,56 @@
+package getsessions
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves GET /users/sessions.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//get variables
+	id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamSessionsTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	SESSIONS_TABLE := cfg.SessionsTable
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	if len(id) > 0 {
+		res, err := FetchSessions(id, request, USER_TABLE, SESSIONS_TABLE, deps.Dynamo, policy)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+		return utility.JSON(200, res), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+}
+
+// FetchSessions returns a page of 100 of the user's sign ins, newest first.
+// Deleted users are included so their history can still be investigated.
+// flagged=true only returns sign ins with unusual patterns.
+func FetchSessions(id string, req events.APIGatewayProxyRequest, userTable string, sessionsTable string,
+	dynaClient dynamodbiface.DynamoDBAPI, policy *types.Policy) (*types.ReturnSignInData, error) {
+	user, err := utility.FetchUserByID(id, userTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if !utility.CanTargetRole(policy, user.Role) {
+		return nil, types.ErrorNotPermittedByPolicy
+	}
+
+	key := req.QueryStringParameters["key"]
+	flaggedOnly := req.QueryStringParameters["flagged"] == "true"
+	return utility.FetchSignInEvents(id, key, 100, flaggedOnly, sessionsTable, dynaClient)
+}
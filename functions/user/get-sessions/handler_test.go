@@ -0,0 +1,62 @@
+package getsessions
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestFetchSessions(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "users",
+		types.User{User_ID: "1", Role: "customer"},
+		types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDeleted})
+	for i := int64(1); i <= 120; i++ {
+		event := types.SignInEvent{User_ID: "1", Timestamp: i, Outcome: "success"}
+		if i%40 == 0 {
+			event.Flags = []string{"new_device"}
+		}
+		db.Seed(t, "sessions", event)
+	}
+	db.Seed(t, "sessions", types.SignInEvent{User_ID: "2", Timestamp: 1, Outcome: "success"})
+
+	tests := []struct {
+		name      string
+		id        string
+		params    map[string]string
+		policy    *types.Policy
+		wantFirst int64
+		wantLen   int
+		wantKey   string
+		wantErr   error
+	}{
+		{name: "first page newest first", id: "1", wantFirst: 120, wantLen: 100, wantKey: "21"},
+		{name: "second page", id: "1", params: map[string]string{"key": "21"}, wantFirst: 20, wantLen: 20},
+		{name: "flagged only", id: "1", params: map[string]string{"flagged": "true"}, wantFirst: 120, wantLen: 3, wantKey: "21"},
+		{name: "deleted user keeps history", id: "2", wantFirst: 1, wantLen: 1},
+		{name: "missing user", id: "3", wantErr: types.ErrorUserDoesNotExist},
+		{name: "role outside policy", id: "1", policy: &types.Policy{TargetRoles: []string{"admin"}},
+			wantErr: types.ErrorNotPermittedByPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := events.APIGatewayProxyRequest{QueryStringParameters: tt.params}
+			res, err := FetchSessions(tt.id, req, "users", "sessions", db, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchSessions() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			got := []interface{}{len(res.Data), res.Data[0].Timestamp, res.Key}
+			if want := []interface{}{tt.wantLen, tt.wantFirst, tt.wantKey}; !reflect.DeepEqual(got, want) {
+				t.Errorf("len, first timestamp, key = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	getusers "ascenda/functions/user/get-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), getusers.Handler))
+}
@@ -0,0 +1,180 @@
+package getusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// notDeletedFilter hides soft deleted users, users stored before statuses
+// existed have no status and are kept.
+const notDeletedFilter = "attribute_not_exists(#status) OR #status <> :deleted"
+
+// Handler serves GET /users.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//get variables
+	id := request.QueryStringParameters["id"]
+	role := request.QueryStringParameters["role"]
+	includeDeleted := request.QueryStringParameters["include_deleted"] == "true"
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	//check if id specified, if yes get single user from dynamo
+	if len(id) > 0 {
+		res, err := utility.FetchUserByID(id, USER_TABLE, deps.Dynamo)
+		if err == nil && utility.IsUserDeleted(*res) && !includeDeleted {
+			err = types.ErrorUserDoesNotExist
+		}
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+		if !utility.CanTargetRole(policy, res.Role) {
+			return utility.Error(request, types.ErrorNotPermittedByPolicy), nil
+		}
+		return utility.JSON(200, res), nil
+	}
+
+	if len(role) > 0 {
+		if !utility.CanTargetRole(policy, role) {
+			return utility.Error(request, types.ErrorNotPermittedByPolicy), nil
+		}
+		res, err := FetchUsersByRole(role, request, USER_TABLE, deps.Dynamo)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+		return utility.JSON(200, res), nil
+	}
+
+	//check if id specified, if no get all users from dynamo
+	res, err := FetchUsers(request, USER_TABLE, deps.Dynamo, policy)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	return utility.JSON(200, res), nil
+}
+
+func FetchUsersByRole(role string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.ReturnUserData, error) {
+	//get users with a certain role
+	itemWithKey := new(types.ReturnUserData)
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		IndexName:              aws.String("role-index"),
+		KeyConditionExpression: aws.String("#role = :role"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":role": {S: aws.String(role)},
+		},
+		ExpressionAttributeNames: map[string]*string{
+			"#role": aws.String("role"),
+		},
+	}
+	if req.QueryStringParameters["include_deleted"] != "true" {
+		input.FilterExpression = aws.String(notDeletedFilter)
+		input.ExpressionAttributeNames["#status"] = aws.String("status")
+		input.ExpressionAttributeValues[":deleted"] = &dynamodb.AttributeValue{S: aws.String(types.UserStatusDeleted)}
+	}
+
+	result, err := dynaClient.Query(input)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+	users := new([]types.User)
+	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, users)
+	if err != nil {
+		return nil, types.ErrorFailedToUnmarshalRecord
+	}
+
+	itemWithKey.Data = *users
+	itemWithKey.Key = ""
+
+	return itemWithKey, nil
+}
+
+// FetchUsers returns a page of up to 100 of the users the policy lets the
+// caller view. The filters apply after each scan reads its items, so scans
+// continue from where the last one stopped until the page is full or the
+// table is read, and a page ends at the last user it holds.
+func FetchUsers(req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI,
+	policy *types.Policy) (*types.ReturnUserData, error) {
+	const pageSize = 100
+	key := req.QueryStringParameters["key"]
+	itemWithKey := &types.ReturnUserData{Data: []types.User{}}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(tableName),
+		Limit:                     aws.Int64(int64(pageSize)),
+		ExpressionAttributeNames:  map[string]*string{},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{},
+	}
+	var filters []string
+	if req.QueryStringParameters["include_deleted"] != "true" {
+		filters = append(filters, "("+notDeletedFilter+")")
+		input.ExpressionAttributeNames["#status"] = aws.String("status")
+		input.ExpressionAttributeValues[":deleted"] = &dynamodb.AttributeValue{S: aws.String(types.UserStatusDeleted)}
+	}
+	if policy != nil && len(policy.TargetRoles) > 0 {
+		var roles []string
+		for i, role := range policy.TargetRoles {
+			name := fmt.Sprintf(":role%d", i)
+			roles = append(roles, name)
+			input.ExpressionAttributeValues[name] = &dynamodb.AttributeValue{S: aws.String(role)}
+		}
+		filters = append(filters, "#role IN ("+strings.Join(roles, ", ")+")")
+		input.ExpressionAttributeNames["#role"] = aws.String("role")
+	}
+	if len(filters) > 0 {
+		input.FilterExpression = aws.String(strings.Join(filters, " AND "))
+	} else {
+		input.ExpressionAttributeNames, input.ExpressionAttributeValues = nil, nil
+	}
+	if len(key) != 0 {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{"user_id": {S: aws.String(key)}}
+	}
+
+	for {
+		result, err := dynaClient.Scan(input)
+		if err != nil {
+			return nil, types.ErrorFailedToFetchRecord
+		}
+		for _, i := range result.Items {
+			user := new(types.User)
+			if err := dynamodbattribute.UnmarshalMap(i, user); err != nil {
+				return nil, types.ErrorFailedToUnmarshalRecord
+			}
+			itemWithKey.Data = append(itemWithKey.Data, *user)
+		}
+
+		more := len(result.LastEvaluatedKey) > 0
+		if len(itemWithKey.Data) >= pageSize {
+			//the next page starts after the last user of this one, even if the
+			//scan read past it
+			if more || len(itemWithKey.Data) > pageSize {
+				itemWithKey.Key = itemWithKey.Data[pageSize-1].User_ID
+			}
+			itemWithKey.Data = itemWithKey.Data[:pageSize]
+			return itemWithKey, nil
+		}
+		if !more {
+			return itemWithKey, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,132 @@
+package getusers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "users",
+		types.User{User_ID: "1", Role: "admin"},
+		types.User{User_ID: "2", Role: "customer"},
+		types.User{User_ID: "3", Role: "customer", Status: types.UserStatusDeleted},
+		types.User{User_ID: "4", Role: "customer"})
+	deps := &utility.Deps{Dynamo: db, Config: utility.StaticConfig{UserTable: "users"}}
+
+	tests := []struct {
+		name       string
+		params     map[string]string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{"all users without deleted", nil, 200, []string{"1", "2", "4"}},
+		{"all users with deleted", map[string]string{"include_deleted": "true"}, 200, []string{"1", "2", "3", "4"}},
+		{"role index", map[string]string{"role": "customer"}, 200, []string{"2", "4"}},
+		{"role index with deleted", map[string]string{"role": "customer", "include_deleted": "true"}, 200, []string{"2", "3", "4"}},
+		{"single user", map[string]string{"id": "2"}, 200, []string{"2"}},
+		{"deleted user hidden", map[string]string{"id": "3"}, 404, nil},
+		{"deleted user included", map[string]string{"id": "3", "include_deleted": "true"}, 200, []string{"3"}},
+		{"missing user", map[string]string{"id": "9"}, 404, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := events.APIGatewayProxyRequest{QueryStringParameters: tt.params}
+			req.RequestContext.Authorizer = map[string]interface{}{utility.PolicyContextKey: "{}"}
+			res, err := Handler(deps, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", res.StatusCode, tt.wantStatus, res.Body)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+
+			var ids []string
+			if _, single := tt.params["id"]; single {
+				var user types.User
+				json.Unmarshal([]byte(res.Body), &user)
+				ids = append(ids, user.User_ID)
+			} else {
+				var data types.ReturnUserData
+				json.Unmarshal([]byte(res.Body), &data)
+				for _, user := range data.Data {
+					ids = append(ids, user.User_ID)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("user ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestFetchUsersPages(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	for i := 0; i < 150; i++ {
+		db.Seed(t, "users", types.User{User_ID: string(rune('a'+i/26)) + string(rune('a'+i%26)), Role: "customer"})
+	}
+
+	first, err := FetchUsers(events.APIGatewayProxyRequest{}, "users", db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Data) != 100 || first.Key == "" {
+		t.Fatalf("first page has %d users and key %q, want 100 and a key", len(first.Data), first.Key)
+	}
+	second, err := FetchUsers(events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"key": first.Key}}, "users", db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Data) != 50 || second.Key != "" {
+		t.Errorf("second page has %d users and key %q, want 50 and no key", len(second.Data), second.Key)
+	}
+}
+
+func TestFetchUsersFillsFilteredPages(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	for i := 0; i < 300; i++ {
+		role := "customer"
+		if i%2 == 1 {
+			role = "admin"
+		}
+		db.Seed(t, "users", types.User{User_ID: fmt.Sprintf("user-%03d", i), Role: role})
+	}
+	policy := &types.Policy{TargetRoles: []string{"customer"}}
+
+	var ids []string
+	key := ""
+	for pages := 0; ; pages++ {
+		page, err := FetchUsers(events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"key": key}},
+			"users", db, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Key != "" && len(page.Data) != 100 {
+			t.Fatalf("page %d has %d users and key %q, want a full page before the last", pages, len(page.Data), page.Key)
+		}
+		for _, user := range page.Data {
+			if user.Role != "customer" {
+				t.Fatalf("page %d lists %s of role %s", pages, user.User_ID, user.Role)
+			}
+			ids = append(ids, user.User_ID)
+		}
+		if key = page.Key; key == "" {
+			break
+		}
+	}
+	if len(ids) != 150 || len(slices.Compact(slices.Clone(ids))) != 150 {
+		t.Errorf("listed %d customers, want each of the 150 once", len(ids))
+	}
+}
@@ -1,214 +0,0 @@
-package main
-
-import (
-	"ascenda/types"
-	"ascenda/utility"
-	"encoding/json"
-	"errors"
-	"log"
-	"os"
-
-	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
-)
-
-var dynaClient *dynamodb.DynamoDB
-var awsSession *session.Session
-
-func init() {
-	region := os.Getenv("AWS_REGION")
-	awsSession, err := session.NewSession(&aws.Config{
-		Region: aws.String(region)})
-	if err != nil {
-		log.Println(err)
-	}
-	dynaClient = dynamodb.New(awsSession)
-	log.Println(awsSession)
-	log.Println(dynaClient)
-}
-
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	//get variables
-	id := request.QueryStringParameters["id"]
-	role := request.QueryStringParameters["role"]
-
-	// Get the parameter value
-	paramUser := "USER_TABLE"
-	log.Println(awsSession)
-	outputUser, err := utility.GetParameterValue(awsSession, paramUser)
-	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting user table parameter store"),
-		}, nil
-	}
-	USER_TABLE := *outputUser.Parameter.Value
-
-	//check if id specified, if yes get single user from dynamo
-	if len(id) > 0 {
-		res, err := FetchUserByID(id, request, USER_TABLE, dynaClient)
-		if err != nil {
-			return events.APIGatewayProxyResponse{
-				StatusCode: 404,
-				Body:       string("Error getting user by id"),
-			}, nil
-		}
-		body, _ := json.Marshal(res)
-		stringBody := string(body)
-		return events.APIGatewayProxyResponse{
-			Body:       string(stringBody),
-			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "application/json"},
-		}, nil
-	}
-
-	if len(role) > 0 {
-		res, err := FetchUsersByRole(role, request, USER_TABLE, dynaClient)
-		if err != nil {
-			return events.APIGatewayProxyResponse{
-				StatusCode: 404,
-				Body:       string("Error getting user by role"),
-			}, nil
-		}
-		body, _ := json.Marshal(res)
-		stringBody := string(body)
-		return events.APIGatewayProxyResponse{
-			Body:       string(stringBody),
-			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "application/json"},
-		}, nil
-	}
-
-	//check if id specified, if no get all users from dynamo
-	res, err := FetchUsers(request, USER_TABLE, dynaClient)
-	if err != nil {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 404,
-			Body:       string("Error getting users"),
-		}, nil
-	}
-
-	body, _ := json.Marshal(res)
-	stringBody := string(body)
-	return events.APIGatewayProxyResponse{
-		Body:       string(stringBody),
-		StatusCode: 200,
-		Headers:    map[string]string{"Content-Type": "application/json"},
-	}, nil
-}
-
-func FetchUserByID(id string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.User, error) {
-	//get single user from dynamo
-	input := &dynamodb.GetItemInput{
-		Key: map[string]*dynamodb.AttributeValue{
-			"user_id": {
-				S: aws.String(id),
-			},
-		},
-		TableName: aws.String(tableName),
-	}
-
-	result, err := dynaClient.GetItem(input)
-	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecordID)
-	}
-
-	if result.Item == nil {
-		return nil, errors.New("user does not exist")
-	}
-
-	item := new(types.User)
-	err = dynamodbattribute.UnmarshalMap(result.Item, item)
-	if err != nil {
-		return nil, errors.New(types.ErrorFailedToUnmarshalRecord)
-	}
-
-	return item, nil
-}
-
-func FetchUsersByRole(role string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.ReturnUserData, error) {
-	//get users with a certain role
-	itemWithKey := new(types.ReturnUserData)
-
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String(tableName),
-		IndexName:              aws.String("role-index"),
-		KeyConditionExpression: aws.String("#role = :role"),
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":role": {S: aws.String(role)},
-		},
-		ExpressionAttributeNames: map[string]*string{
-			"#role": aws.String("role"),
-		},
-	}
-
-	result, err := dynaClient.Query(input)
-	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecordID)
-	}
-	users := new([]types.User)
-	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, users)
-	if err != nil {
-		return nil, errors.New(types.ErrorFailedToUnmarshalRecord)
-	}
-
-	itemWithKey.Data = *users
-	itemWithKey.Key = ""
-
-	return itemWithKey, nil
-}
-
-func FetchUsers(req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.ReturnUserData, error) {
-	//get all users with pagination of limit 100
-	key := req.QueryStringParameters["key"]
-	lastEvaluatedKey := make(map[string]*dynamodb.AttributeValue)
-
-	item := new([]types.User)
-	itemWithKey := new(types.ReturnUserData)
-
-	input := &dynamodb.ScanInput{
-		TableName: aws.String(tableName),
-		Limit:     aws.Int64(int64(100)),
-	}
-
-	if len(key) != 0 {
-		lastEvaluatedKey["user_id"] = &dynamodb.AttributeValue{
-			S: aws.String(key),
-		}
-		input.ExclusiveStartKey = lastEvaluatedKey
-	}
-
-	result, err := dynaClient.Scan(input)
-	if err != nil {
-		return nil, errors.New(types.ErrorFailedToFetchRecord)
-	}
-
-	for _, i := range result.Items {
-		user := new(types.User)
-		err := dynamodbattribute.UnmarshalMap(i, user)
-		if err != nil {
-			return nil, err
-		}
-		*item = append(*item, *user)
-	}
-
-	itemWithKey.Data = *item
-
-	if len(result.LastEvaluatedKey) == 0 {
-		return itemWithKey, nil
-	}
-
-	itemWithKey.Key = *result.LastEvaluatedKey["user_id"].S
-
-	return itemWithKey, nil
-
-}
-
-func main() {
-	lambda.Start(handler)
-}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	globalsignout "ascenda/functions/user/global-sign-out"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), globalsignout.Handler))
+}
@@ -0,0 +1,71 @@
+package globalsignout
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves POST /users/signout.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamTTL, utility.ParamLogsTable, utility.ParamUserPoolID)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	USER_POOL_ID := cfg.UserPoolID
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	if len(id) > 0 {
+		res := GlobalSignOut(id, request, USER_TABLE, LOGS_TABLE, TTL, deps.Dynamo, deps.Cognito, USER_POOL_ID, policy)
+		if res != nil {
+			return utility.Error(request, res), nil
+		}
+		return utility.Text(200, "User successfully signed out"), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+}
+
+// GlobalSignOut revokes every refresh token of the user, ending all of their
+// sessions once their current access tokens expire.
+func GlobalSignOut(id string, req events.APIGatewayProxyRequest, tableName string, logTable string, ttl string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	userPoolID string, policy *types.Policy) error {
+	user, err := utility.FetchTargetUser(id, tableName, dynaClient, policy)
+	if err != nil {
+		return err
+	}
+
+	_, err = cognitoClient.AdminUserGlobalSignOut(&cognitoidentityprovider.AdminUserGlobalSignOutInput{
+		UserPoolId: aws.String(userPoolID),
+		Username:   aws.String(user.User_ID),
+	})
+	if err != nil {
+		log.Println(err)
+		return types.ErrorCognitoActionFailed
+	}
+
+	//logging
+	if logErr := utility.SendUserActionLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, "signed out"); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return nil
+}
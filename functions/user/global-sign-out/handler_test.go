@@ -0,0 +1,69 @@
+package globalsignout
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+// fakeCognito records the users it is called for, failing when err is set.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	users []string
+	err   error
+}
+
+func (f *fakeCognito) AdminUserGlobalSignOut(input *cognitoidentityprovider.AdminUserGlobalSignOutInput) (*cognitoidentityprovider.AdminUserGlobalSignOutOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.users = append(f.users, *input.Username)
+	return &cognitoidentityprovider.AdminUserGlobalSignOutOutput{}, nil
+}
+
+func TestGlobalSignOut(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		cognitoErr error
+		wantErr    error
+	}{
+		{name: "active user", id: "1"},
+		{name: "deleted user", id: "2", wantErr: types.ErrorUserAlreadyDeleted},
+		{name: "missing user", id: "3", wantErr: types.ErrorUserDoesNotExist},
+		{name: "cognito failure", id: "1", cognitoErr: errors.New("down"), wantErr: types.ErrorCognitoActionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "users",
+				types.User{User_ID: "1", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+				types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDeleted})
+			cognitoClient := &fakeCognito{err: tt.cognitoErr}
+			req := events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"requester": "Ada-Admin"}}
+
+			err := GlobalSignOut(tt.id, req, "users", "logs", "30", db, cognitoClient, "pool", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GlobalSignOut() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var logs []types.Log
+			db.Load(t, "logs", &logs)
+			if tt.wantErr != nil {
+				if len(logs) != 0 {
+					t.Errorf("failed action was logged: %+v", logs)
+				}
+				return
+			}
+			if len(cognitoClient.users) != 1 || len(logs) != 1 || logs[0].Description != "Ada Admin signed out user Jane Doe" {
+				t.Errorf("cognito called for %v, logs = %+v", cognitoClient.users, logs)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	importusers "ascenda/functions/user/import-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), importusers.Handler))
+}
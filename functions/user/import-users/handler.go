@@ -0,0 +1,105 @@
+package importusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/google/uuid"
+)
+
+// Handler serves POST /users/import.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamRolesTable, utility.ParamImportJobsTable,
+		utility.ParamImportQueueURL, utility.ParamTTL,
+	)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	ROLES_TABLE := cfg.RolesTable
+	IMPORT_JOBS_TABLE := cfg.ImportJobsTable
+	IMPORT_QUEUE_URL := cfg.ImportQueueURL
+	TTL := cfg.TTL
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	res, err := CreateImportJob(request, USER_TABLE, ROLES_TABLE, IMPORT_JOBS_TABLE, TTL, deps.Dynamo, deps.SQS,
+		IMPORT_QUEUE_URL, policy)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	//rows are fetched with the job status, only the summary is returned here
+	res.Rows = nil
+	return utility.JSON(202, res), nil
+}
+
+// CreateImportJob validates the uploaded CSV, stores it as an import job and
+// queues the job so its valid rows are provisioned in the background.
+func CreateImportJob(req events.APIGatewayProxyRequest, userTable string, rolesTable string, jobsTable string, ttl string,
+	dynaClient dynamodbiface.DynamoDBAPI, sqsClient sqsiface.SQSAPI, queueURL string, policy *types.Policy) (*types.ImportJob, error) {
+	body, err := utility.RequestBody(req)
+	if err != nil {
+		return nil, types.ErrorInvalidCSV
+	}
+
+	rows, err := utility.ParseImportCSV(body)
+	if err != nil {
+		return nil, err
+	}
+
+	existingEmails, err := utility.FetchAllEmails(userTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := utility.ValidateImportRows(rows, existingEmails, rolesTable, dynaClient, policy); err != nil {
+		return nil, err
+	}
+
+	ttlNum, err := strconv.Atoi(ttl)
+	if err != nil {
+		return nil, types.ErrorLoadingConfig
+	}
+
+	now := time.Now()
+	job := &types.ImportJob{
+		Job_ID:    uuid.NewString(),
+		Status:    types.ImportJobPending,
+		Requester: utility.Requester(req),
+		CreatedAt: now.Unix(),
+		Rows:      rows,
+		TTL:       now.AddDate(0, 0, ttlNum).Unix(),
+	}
+	if err := utility.SaveImportJob(job, jobsTable, dynaClient); err != nil {
+		return nil, err
+	}
+
+	message, err := json.Marshal(types.ImportJobMessage{Job_ID: job.Job_ID})
+	if err != nil {
+		return nil, types.ErrorCouldNotMarshalItem
+	}
+	_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		MessageBody: aws.String(string(message)),
+		QueueUrl:    aws.String(queueURL),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,93 @@
+package importusers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// fakeSQS records the message bodies it is sent.
+type fakeSQS struct {
+	sqsiface.SQSAPI
+	bodies []string
+}
+
+func (f *fakeSQS) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	f.bodies = append(f.bodies, *input.MessageBody)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestCreateImportJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    error
+		wantStatus []string
+	}{
+		{
+			name: "validates every row",
+			body: "email,first_name,last_name,role\n" +
+				"new@example.com,New,User,customer\n" +
+				"Taken@example.com,Taken,User,customer\n" +
+				"new@example.com,Again,User,customer\n" +
+				"not-an-email,Bad,User,customer\n" +
+				"other@example.com,Other,User,pirate\n",
+			wantStatus: []string{types.ImportRowPending, types.ImportRowDuplicate, types.ImportRowDuplicate,
+				types.ImportRowInvalid, types.ImportRowInvalid},
+		},
+		{name: "missing column", body: "email,first_name\nnew@example.com,New\n", wantErr: types.ErrorInvalidCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "roles", types.Role{Role: "customer"})
+			db.Seed(t, "users", types.User{User_ID: "1", Email: "taken@example.com", Role: "customer"})
+			sqsClient := &fakeSQS{}
+			req := events.APIGatewayProxyRequest{Body: tt.body, QueryStringParameters: map[string]string{"requester": "Ada-Admin"},
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Authorizer: map[string]interface{}{utility.UserIDContextKey: "admin-1"},
+				}}
+
+			job, err := CreateImportJob(req, "users", "roles", "import-jobs", "30", db, sqsClient, "queue", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateImportJob() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(db.Items("import-jobs")) != 0 || len(sqsClient.bodies) != 0 {
+					t.Error("rejected upload stored or queued a job")
+				}
+				return
+			}
+
+			var status []string
+			for _, row := range job.Rows {
+				status = append(status, row.Status)
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("row status = %v, want %v", status, tt.wantStatus)
+			}
+
+			var jobs []types.ImportJob
+			db.Load(t, "import-jobs", &jobs)
+			if len(jobs) != 1 || jobs[0].Job_ID != job.Job_ID || len(jobs[0].Rows) != len(job.Rows) {
+				t.Errorf("stored jobs = %+v, want the created job", jobs)
+			}
+			if job.Requester != "admin-1" {
+				t.Errorf("requester = %q, want the authorized user_id over the requester parameter", job.Requester)
+			}
+			var message types.ImportJobMessage
+			if len(sqsClient.bodies) != 1 || json.Unmarshal([]byte(sqsClient.bodies[0]), &message) != nil || message.Job_ID != job.Job_ID {
+				t.Errorf("queued %v, want the job id", sqsClient.bodies)
+			}
+		})
+	}
+}
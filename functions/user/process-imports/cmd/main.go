@@ -0,0 +1,12 @@
+package main
+
+import (
+	processimports "ascenda/functions/user/process-imports"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.Event(utility.MustDeps(), processimports.Handler))
+}
@@ -0,0 +1,153 @@
+package processimports
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/google/uuid"
+)
+
+// Handler provisions the rows of the import jobs queued on the import queue.
+func Handler(deps *utility.Deps, event events.SQSEvent) error {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamEmailsTable, utility.ParamImportJobsTable, utility.ParamLogsTable,
+		utility.ParamTTL, utility.ParamUserPoolID, utility.ParamReconciliationQueueURL, utility.ParamImportRate,
+	)
+	if err != nil {
+		return err
+	}
+	USER_TABLE := cfg.UserTable
+	EMAILS_TABLE := cfg.EmailsTable
+	IMPORT_JOBS_TABLE := cfg.ImportJobsTable
+	LOGS_TABLE := cfg.LogsTable
+	TTL := cfg.TTL
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+	IMPORT_RATE := cfg.ImportRate
+
+	if IMPORT_RATE <= 0 {
+		return types.ErrorLoadingConfig
+	}
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	for _, record := range event.Records {
+		var message types.ImportJobMessage
+		if err := json.Unmarshal([]byte(record.Body), &message); err != nil {
+			log.Println("invalid import message", record.MessageId, err)
+			continue
+		}
+
+		job, err := utility.FetchImportJob(message.Job_ID, IMPORT_JOBS_TABLE, deps.Dynamo)
+		if err != nil {
+			log.Println("failed to fetch import job", message.Job_ID, err)
+			return err
+		}
+
+		//returning the error leaves the message on the queue to resume the job
+		err = ProcessImportJob(job, USER_TABLE, EMAILS_TABLE, IMPORT_JOBS_TABLE, LOGS_TABLE, TTL, USER_POOL_ID, time.Second/time.Duration(IMPORT_RATE),
+			deps.Dynamo, deps.Cognito, deps.SES, reconciler)
+		if err != nil {
+			log.Println("failed to process import job", message.Job_ID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ProcessImportJob provisions the pending rows of the job one at a time, at
+// most one every interval, recording each row's outcome. Emails are checked
+// again first as users may have been created since the upload. Each row's
+// user_id is saved before the user is provisioned, so a job cut short by the
+// lambda timeout recognises the users it already created when it resumes.
+func ProcessImportJob(job *types.ImportJob, userTable string, emailsTable string, jobsTable string, logTable string, ttl string, userPoolID string,
+	interval time.Duration, dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	sesClient sesiface.SESAPI, reconciler utility.Reconciler) error {
+	if job.Status == types.ImportJobCompleted {
+		return nil
+	}
+
+	existingEmails, err := utility.FetchAllEmails(userTable, dynaClient)
+	if err != nil {
+		return err
+	}
+
+	job.Status = types.ImportJobRunning
+	if err := utility.SaveImportJob(job, jobsTable, dynaClient); err != nil {
+		return err
+	}
+
+	//a synthetic request so each user is logged as enrolled by the uploader
+	req := events.APIGatewayProxyRequest{
+		QueryStringParameters: map[string]string{"requester": job.Requester},
+	}
+
+	provisioned := 0
+	for i := range job.Rows {
+		row := &job.Rows[i]
+		if row.Status != types.ImportRowPending {
+			continue
+		}
+		if existingEmails[utility.EmailKey(row.Email)] {
+			if row.User_ID != "" {
+				if _, err := utility.FetchUserByID(row.User_ID, userTable, dynaClient); err == nil {
+					row.Status = types.ImportRowCreated
+					continue
+				}
+			}
+			row.Status, row.User_ID, row.Error = types.ImportRowDuplicate, "", types.ErrorEmailAlreadyExists.Error()
+			continue
+		}
+
+		if row.User_ID == "" {
+			row.User_ID = uuid.NewString()
+		}
+		if err := utility.SaveImportJob(job, jobsTable, dynaClient); err != nil {
+			return err
+		}
+
+		if provisioned > 0 {
+			time.Sleep(interval)
+		}
+		provisioned++
+
+		user := types.CognitoUser{
+			User: &types.User{
+				Email:     row.Email,
+				User_ID:   row.User_ID,
+				FirstName: row.FirstName,
+				LastName:  row.LastName,
+				Role:      row.Role,
+			},
+		}
+		err := utility.ProvisionUser(user, userTable, emailsTable, userPoolID, dynaClient, cognitoClient, reconciler)
+		if errors.Is(err, types.ErrorEmailAlreadyExists) {
+			row.Status, row.User_ID, row.Error = types.ImportRowDuplicate, "", err.Error()
+		} else if err != nil {
+			row.Status, row.User_ID, row.Error = types.ImportRowFailed, "", err.Error()
+		} else {
+			row.Status = types.ImportRowCreated
+			existingEmails[utility.EmailKey(row.Email)] = true
+
+			utility.EmailVerification(row.Email, sesClient)
+
+			//logging
+			if logErr := utility.SendCreateUserLogs(req, dynaClient, logTable, ttl, row.FirstName, row.LastName, row.Role); logErr != nil {
+				log.Println("Logging err :", logErr)
+			}
+		}
+	}
+
+	job.Status = types.ImportJobCompleted
+	return utility.SaveImportJob(job, jobsTable, dynaClient)
+}
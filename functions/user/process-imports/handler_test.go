@@ -0,0 +1,131 @@
+package processimports
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+// fakeCognito creates users, rejecting the emails in reject.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	reject  map[string]bool
+	created int
+}
+
+func (f *fakeCognito) AdminCreateUser(input *cognitoidentityprovider.AdminCreateUserInput) (*cognitoidentityprovider.AdminCreateUserOutput, error) {
+	for _, attribute := range input.UserAttributes {
+		if *attribute.Name == "email" && f.reject[*attribute.Value] {
+			return nil, errors.New("delivery failed")
+		}
+	}
+	f.created++
+	return &cognitoidentityprovider.AdminCreateUserOutput{}, nil
+}
+
+type fakeSES struct {
+	sesiface.SESAPI
+}
+
+func (fakeSES) VerifyEmailIdentity(*ses.VerifyEmailIdentityInput) (*ses.VerifyEmailIdentityOutput, error) {
+	return &ses.VerifyEmailIdentityOutput{}, nil
+}
+
+func TestProcessImportJob(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	db.Seed(t, "users", types.User{User_ID: "1", Email: "late@example.com", Role: "customer"})
+	cognitoClient := &fakeCognito{reject: map[string]bool{"bounce@example.com": true}}
+	job := &types.ImportJob{
+		Job_ID:    "job",
+		Status:    types.ImportJobPending,
+		Requester: "Ada-Admin",
+		Rows: []types.ImportRow{
+			{Line: 2, Email: "new@example.com", FirstName: "New", LastName: "User", Role: "customer", Status: types.ImportRowPending},
+			{Line: 3, Email: "Late@example.com", FirstName: "Late", LastName: "User", Role: "customer", Status: types.ImportRowPending},
+			{Line: 4, Email: "bounce@example.com", FirstName: "Bounce", LastName: "User", Role: "customer", Status: types.ImportRowPending},
+			{Line: 5, Email: "bad", Role: "customer", Status: types.ImportRowInvalid},
+		},
+	}
+
+	err := ProcessImportJob(job, "users", "emails", "import-jobs", "logs", "30", "pool", 0, db, cognitoClient, fakeSES{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jobs []types.ImportJob
+	db.Load(t, "import-jobs", &jobs)
+	if len(jobs) != 1 || jobs[0].Status != types.ImportJobCompleted {
+		t.Fatalf("stored jobs = %+v, want the completed job", jobs)
+	}
+	var status []string
+	for _, row := range jobs[0].Rows {
+		status = append(status, row.Status)
+	}
+	want := []string{types.ImportRowCreated, types.ImportRowDuplicate, types.ImportRowFailed, types.ImportRowInvalid}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("row status = %v, want %v", status, want)
+	}
+
+	//the failed row was rolled back, leaving the seeded and the created user
+	var users []types.User
+	db.Load(t, "users", &users)
+	if len(users) != 2 || cognitoClient.created != 1 || len(db.Items("emails")) != 1 {
+		t.Errorf("users = %+v, emails = %v, cognito created %d", users, db.Items("emails"), cognitoClient.created)
+	}
+	var logs []types.Log
+	db.Load(t, "logs", &logs)
+	if len(logs) != 1 {
+		t.Errorf("logs = %+v, want the created user", logs)
+	}
+
+	//a completed job is left alone when the message is redelivered
+	db.Fail("Scan", errors.New("scanned a completed job"))
+	if err := ProcessImportJob(&jobs[0], "users", "emails", "import-jobs", "logs", "30", "pool", 0, db, cognitoClient, fakeSES{}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProcessImportJobResumes(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	//the job stopped after creating "done" but before saving its row
+	db.Seed(t, "users",
+		types.User{User_ID: "done", Email: "done@example.com", Role: "customer"},
+		types.User{User_ID: "other", Email: "taken@example.com", Role: "customer"})
+	cognitoClient := &fakeCognito{}
+	job := &types.ImportJob{
+		Job_ID:    "job",
+		Status:    types.ImportJobRunning,
+		Requester: "Ada-Admin",
+		Rows: []types.ImportRow{
+			{Line: 2, Email: "done@example.com", Role: "customer", Status: types.ImportRowPending, User_ID: "done"},
+			{Line: 3, Email: "taken@example.com", Role: "customer", Status: types.ImportRowPending, User_ID: "lost"},
+			{Line: 4, Email: "next@example.com", Role: "customer", Status: types.ImportRowPending},
+		},
+	}
+
+	err := ProcessImportJob(job, "users", "emails", "import-jobs", "logs", "30", "pool", 0, db, cognitoClient, fakeSES{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jobs []types.ImportJob
+	db.Load(t, "import-jobs", &jobs)
+	want := []types.ImportRow{
+		{Line: 2, Email: "done@example.com", Role: "customer", Status: types.ImportRowCreated, User_ID: "done"},
+		{Line: 3, Email: "taken@example.com", Role: "customer", Status: types.ImportRowDuplicate,
+			Error: types.ErrorEmailAlreadyExists.Error()},
+	}
+	if !reflect.DeepEqual(jobs[0].Rows[:2], want) {
+		t.Errorf("rows = %+v, want %+v", jobs[0].Rows[:2], want)
+	}
+	if row := jobs[0].Rows[2]; row.Status != types.ImportRowCreated || row.User_ID == "" || cognitoClient.created != 1 {
+		t.Errorf("row = %+v, cognito created %d, want only the new row provisioned", row, cognitoClient.created)
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	purgeusers "ascenda/functions/user/purge-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.Event(utility.MustDeps(), purgeusers.Handler))
+}
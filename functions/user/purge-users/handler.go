@@ -0,0 +1,125 @@
+package purgeusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// PurgeRequester is recorded in the logs as the requester of scheduled purges.
+const PurgeRequester = "scheduled-purge"
+
+// Handler runs on a schedule, purging users deleted longer than the retention
+// window ago.
+func Handler(deps *utility.Deps, event events.CloudWatchEvent) error {
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamEmailsTable, utility.ParamTTL, utility.ParamLogsTable,
+		utility.ParamPointsTable, utility.ParamMakerTable, utility.ParamUserPoolID, utility.ParamReconciliationQueueURL,
+		utility.ParamRetentionDays,
+	)
+	if err != nil {
+		return err
+	}
+	USER_TABLE := cfg.UserTable
+	EMAILS_TABLE := cfg.EmailsTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	POINTS_TABLE := cfg.PointsTable
+	MAKER_TABLE := cfg.MakerTable
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+	RETENTION_DAYS := cfg.RetentionDays
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	users, err := FetchDeletedUsers(USER_TABLE, deps.Dynamo)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	//a synthetic request so the purge is logged like any other deletion
+	req := events.APIGatewayProxyRequest{
+		QueryStringParameters: map[string]string{"requester": PurgeRequester},
+	}
+
+	now := time.Now()
+	for _, user := range users {
+		if !utility.IsPastRetention(user, RETENTION_DAYS, now) {
+			continue
+		}
+		//keep purging the rest, a failed saga has already queued reconciliation
+		if err := PurgeUser(user, req, USER_TABLE, EMAILS_TABLE, LOGS_TABLE, POINTS_TABLE, MAKER_TABLE, TTL, USER_POOL_ID, deps.Dynamo,
+			deps.Cognito, reconciler); err != nil {
+			log.Println("failed to purge user", user.User_ID, err)
+		}
+	}
+
+	return nil
+}
+
+// PurgeUser hard deletes the soft deleted user and logs the closed points
+// accounts and the deletion.
+func PurgeUser(user types.User, req events.APIGatewayProxyRequest, tableName, emailsTable, logTable, pointsTable, makerTable, ttl, userPoolID string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	reconciler utility.Reconciler) error {
+	points, err := utility.HardDeleteUser(user, false, tableName, emailsTable, pointsTable, makerTable, userPoolID, dynaClient,
+		cognitoClient, reconciler)
+	if err != nil {
+		return err
+	}
+
+	//logging
+	for _, point := range points {
+		if logErr := utility.SendClosePointsLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, point); logErr != nil {
+			log.Println("Logging err :", logErr)
+		}
+	}
+	if logErr := utility.SendUserActionLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, "purged"); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return nil
+}
+
+// FetchDeletedUsers returns every soft deleted user.
+func FetchDeletedUsers(tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.User, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(tableName),
+		FilterExpression: aws.String("#status = :deleted"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":deleted": {S: aws.String(types.UserStatusDeleted)},
+		},
+	}
+
+	users := []types.User{}
+	for {
+		result, err := dynaClient.Scan(input)
+		if err != nil {
+			return nil, types.ErrorFailedToFetchRecord
+		}
+
+		page := []types.User{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, types.ErrorFailedToUnmarshalRecord
+		}
+		users = append(users, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return users, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
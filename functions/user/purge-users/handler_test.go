@@ -0,0 +1,115 @@
+package purgeusers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+// fakeCognito records the users it deletes, failing when err is set.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	deleted []string
+	err     error
+}
+
+func (f *fakeCognito) AdminDeleteUser(input *cognitoidentityprovider.AdminDeleteUserInput) (*cognitoidentityprovider.AdminDeleteUserOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.deleted = append(f.deleted, *input.Username)
+	return &cognitoidentityprovider.AdminDeleteUserOutput{}, nil
+}
+
+func seed(t *testing.T, db *dynamotest.DB) {
+	expired := time.Now().AddDate(0, 0, -31).Unix()
+	db.Seed(t, "users",
+		types.User{User_ID: "1", Email: "old@example.com", FirstName: "Old", LastName: "User", Role: "customer",
+			Status: types.UserStatusDeleted, DeletedAt: expired},
+		types.User{User_ID: "2", Email: "recent@example.com", Role: "customer", Status: types.UserStatusDeleted,
+			DeletedAt: time.Now().Unix()},
+		types.User{User_ID: "3", Email: "active@example.com", Role: "customer"})
+	db.Seed(t, "emails", map[string]string{"email": "old@example.com", "user_id": "1"})
+	db.Seed(t, "points", types.UserPoint{User_ID: "1", Points_ID: "p1", Points: 50})
+	db.Seed(t, "makers", types.MakerRequest{RequestUUID: "r1", CheckerRole: "admin", MakerUUID: "3", RequestStatus: "pending",
+		ResourceType: "user", RequestData: json.RawMessage(`{"user_id":"1"}`)})
+}
+
+func TestPurgeUsers(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	seed(t, db)
+	cognitoClient := &fakeCognito{}
+	deps := &utility.Deps{Dynamo: db, Cognito: cognitoClient, Config: utility.StaticConfig{
+		UserTable: "users", EmailsTable: "emails", LogsTable: "logs", PointsTable: "points", MakerTable: "makers",
+		TTL: "30", RetentionDays: 30,
+	}}
+
+	if err := Handler(deps, events.CloudWatchEvent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(cognitoClient.deleted, []string{"1"}) {
+		t.Errorf("cognito deleted %v, want only the expired user", cognitoClient.deleted)
+	}
+	var users []types.User
+	db.Load(t, "users", &users)
+	if len(users) != 2 || users[0].User_ID != "2" || len(db.Items("emails")) != 0 {
+		t.Errorf("users = %+v, emails = %v, want the expired user and email gone", users, db.Items("emails"))
+	}
+	var points []types.UserPoint
+	db.Load(t, "points", &points)
+	if points[0].Points != 0 || points[0].Status != types.PointsStatusClosed {
+		t.Errorf("points = %+v, want closed", points[0])
+	}
+	var requests []types.MakerRequest
+	db.Load(t, "makers", &requests)
+	if requests[0].RequestStatus != "rejected" || requests[0].CheckerUUID != utility.SystemCheckerID {
+		t.Errorf("maker request = %+v, want rejected by the system", requests[0])
+	}
+	var logs []types.Log
+	db.Load(t, "logs", &logs)
+	if len(logs) != 2 {
+		t.Errorf("logs = %+v, want closed points and purge", logs)
+	}
+}
+
+// stored loads the tables a purge changes.
+type stored struct {
+	Users    []types.User
+	Emails   []map[string]string
+	Points   []types.UserPoint
+	Requests []types.MakerRequest
+}
+
+func load(t *testing.T, db *dynamotest.DB) stored {
+	var s stored
+	db.Load(t, "users", &s.Users)
+	db.Load(t, "emails", &s.Emails)
+	db.Load(t, "points", &s.Points)
+	db.Load(t, "makers", &s.Requests)
+	return s
+}
+
+func TestPurgeUserRollsBack(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	seed(t, db)
+	before := load(t, db)
+
+	err := PurgeUser(before.Users[0], events.APIGatewayProxyRequest{}, "users", "emails", "logs", "points", "makers", "30", "pool", db,
+		&fakeCognito{err: errors.New("down")}, nil)
+	if err == nil {
+		t.Fatal("PurgeUser() succeeded with cognito down")
+	}
+	if after := load(t, db); !reflect.DeepEqual(after, before) {
+		t.Errorf("tables after rollback = %+v, want %+v", after, before)
+	}
+}
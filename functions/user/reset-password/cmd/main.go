@@ -0,0 +1,12 @@
+package main
+
+import (
+	resetpassword "ascenda/functions/user/reset-password"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), resetpassword.Handler))
+}
@@ -0,0 +1,71 @@
+package resetpassword
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves POST /users/password/reset.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamTTL, utility.ParamLogsTable, utility.ParamUserPoolID)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	USER_POOL_ID := cfg.UserPoolID
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	if len(id) > 0 {
+		res := ResetPassword(id, request, USER_TABLE, LOGS_TABLE, TTL, deps.Dynamo, deps.Cognito, USER_POOL_ID, policy)
+		if res != nil {
+			return utility.Error(request, res), nil
+		}
+		return utility.Text(200, "Password reset code sent"), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+}
+
+// ResetPassword invalidates the user's password and has cognito send them a
+// code to choose a new one at their next sign in.
+func ResetPassword(id string, req events.APIGatewayProxyRequest, tableName string, logTable string, ttl string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	userPoolID string, policy *types.Policy) error {
+	user, err := utility.FetchTargetUser(id, tableName, dynaClient, policy)
+	if err != nil {
+		return err
+	}
+
+	_, err = cognitoClient.AdminResetUserPassword(&cognitoidentityprovider.AdminResetUserPasswordInput{
+		UserPoolId: aws.String(userPoolID),
+		Username:   aws.String(user.User_ID),
+	})
+	if err != nil {
+		log.Println(err)
+		return types.ErrorCognitoActionFailed
+	}
+
+	//logging
+	if logErr := utility.SendUserActionLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, "reset the password of"); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	restoreusers "ascenda/functions/user/restore-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), restoreusers.Handler))
+}
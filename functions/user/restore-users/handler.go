@@ -0,0 +1,114 @@
+package restoreusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves PUT /users/restore.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamPointsTable, utility.ParamMakerTable, utility.ParamTTL, utility.ParamLogsTable,
+		utility.ParamUserPoolID, utility.ParamReconciliationQueueURL, utility.ParamRetentionDays,
+	)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	POINTS_TABLE := cfg.PointsTable
+	MAKER_TABLE := cfg.MakerTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+	RETENTION_DAYS := cfg.RetentionDays
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	if len(id) > 0 {
+		res := RestoreUser(id, request, USER_TABLE, POINTS_TABLE, MAKER_TABLE, LOGS_TABLE, TTL, RETENTION_DAYS, time.Now(),
+			deps.Dynamo, deps.Cognito, USER_POOL_ID, policy, reconciler)
+		if res != nil {
+			return utility.Error(request, res), nil
+		}
+		return utility.Text(200, "User successfully restored"), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+}
+
+// RestoreUser re-enables a disabled user, or a deleted user whose deletion is
+// still within the retention window, unfreezing their points accounts and
+// reopening the maker requests rejected by the deletion.
+func RestoreUser(id string, req events.APIGatewayProxyRequest, tableName, pointsTable, makerTable, logTable, ttl string,
+	retentionDays int, now time.Time, dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	userPoolID string, policy *types.Policy, reconciler utility.Reconciler) error {
+	//check if user exist
+	checkUser := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(id),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(checkUser)
+	if err != nil {
+		return types.ErrorFailedToFetchRecordID
+	}
+
+	var user types.User
+	if result.Item == nil {
+		return types.ErrorUserDoesNotExist
+	}
+
+	err = dynamodbattribute.UnmarshalMap(result.Item, &user)
+	if err != nil {
+		return types.ErrorFailedToUnmarshal
+	}
+
+	if !utility.CanTargetRole(policy, user.Role) {
+		return types.ErrorNotPermittedByPolicy
+	}
+
+	switch user.Status {
+	case types.UserStatusDisabled:
+		err = utility.SetUserStatus(user, types.UserStatusActive, 0, tableName, userPoolID, dynaClient, cognitoClient, reconciler)
+	case types.UserStatusDeleted:
+		if utility.IsPastRetention(user, retentionDays, now) {
+			return types.ErrorRetentionExpired
+		}
+		err = utility.UndeleteUser(user, tableName, pointsTable, makerTable, userPoolID, dynaClient, cognitoClient, reconciler)
+	default:
+		return types.ErrorUserNotDeleted
+	}
+	if err != nil {
+		return err
+	}
+
+	//logging
+	if logErr := utility.SendUserActionLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, "restored"); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return nil
+}
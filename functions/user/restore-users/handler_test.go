@@ -0,0 +1,84 @@
+package restoreusers
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+func (f *fakeCognito) AdminEnableUser(*cognitoidentityprovider.AdminEnableUserInput) (*cognitoidentityprovider.AdminEnableUserOutput, error) {
+	return &cognitoidentityprovider.AdminEnableUserOutput{}, nil
+}
+
+func TestRestoreUser(t *testing.T) {
+	now := time.Unix(100*24*60*60, 0)
+	tests := []struct {
+		name    string
+		user    types.User
+		wantErr error
+	}{
+		{"disabled user", types.User{Status: types.UserStatusDisabled}, nil},
+		{"deleted within retention", types.User{Status: types.UserStatusDeleted, DeletedAt: now.AddDate(0, 0, -29).Unix()}, nil},
+		{"deleted past retention", types.User{Status: types.UserStatusDeleted, DeletedAt: now.AddDate(0, 0, -31).Unix()},
+			types.ErrorRetentionExpired},
+		{"active user", types.User{Status: types.UserStatusActive}, types.ErrorUserNotDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			tt.user.User_ID, tt.user.FirstName, tt.user.LastName, tt.user.Role = "1", "Jane", "Doe", "customer"
+			db.Seed(t, "users", tt.user)
+			db.Seed(t, "points", types.UserPoint{User_ID: "1", Points_ID: "p", Points: 40, Status: types.PointsStatusFrozen})
+			db.Seed(t, "makers",
+				types.MakerRequest{RequestUUID: "system", CheckerRole: "admin", CheckerUUID: utility.SystemCheckerID,
+					RequestStatus: "rejected", RequestData: json.RawMessage(`{"user_id":"1"}`)},
+				types.MakerRequest{RequestUUID: "checker", CheckerRole: "admin", CheckerUUID: "c1",
+					RequestStatus: "rejected", RequestData: json.RawMessage(`{"user_id":"1"}`)})
+			req := events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"requester": "Ada-Admin"}}
+
+			err := RestoreUser("1", req, "users", "points", "makers", "logs", "30", 30, now, db, &fakeCognito{}, "pool", nil, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RestoreUser() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var users []types.User
+			db.Load(t, "users", &users)
+			want := tt.user
+			if tt.wantErr == nil {
+				want.Status, want.DeletedAt = types.UserStatusActive, 0
+			}
+			if users[0] != want {
+				t.Errorf("user = %+v, want %+v", users[0], want)
+			}
+
+			//only a restored deletion reopens what the deletion froze
+			reopened := tt.wantErr == nil && tt.user.Status == types.UserStatusDeleted
+			var points []types.UserPoint
+			db.Load(t, "points", &points)
+			if (points[0].Status == types.PointsStatusActive) != reopened || points[0].Points != 40 {
+				t.Errorf("points = %+v, reopened %v", points[0], reopened)
+			}
+			var requests []types.MakerRequest
+			db.Load(t, "makers", &requests)
+			for _, request := range requests {
+				pending := request.RequestStatus == "pending"
+				if pending != (reopened && request.RequestUUID == "system") {
+					t.Errorf("maker request %+v, reopened %v", request, reopened)
+				}
+			}
+		})
+	}
+}
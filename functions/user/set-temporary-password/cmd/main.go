@@ -0,0 +1,12 @@
+package main
+
+import (
+	settemporarypassword "ascenda/functions/user/set-temporary-password"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), settemporarypassword.Handler))
+}
@@ -0,0 +1,87 @@
+package settemporarypassword
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+	"errors"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves POST /users/password/temporary.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamTTL, utility.ParamLogsTable, utility.ParamUserPoolID)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	USER_POOL_ID := cfg.UserPoolID
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	if len(id) > 0 {
+		res := SetTemporaryPassword(id, request, USER_TABLE, LOGS_TABLE, TTL, deps.Dynamo, deps.Cognito, USER_POOL_ID, policy)
+		if res != nil {
+			return utility.Error(request, res), nil
+		}
+		return utility.Text(200, "Temporary password set"), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+}
+
+// SetTemporaryPassword gives the user a password they must change at their
+// next sign in. Admins never choose a user's permanent password.
+func SetTemporaryPassword(id string, req events.APIGatewayProxyRequest, tableName string, logTable string, ttl string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	userPoolID string, policy *types.Policy) error {
+	body, err := utility.Decode[types.TemporaryPassword](req, validation.TemporaryPassword)
+	if err != nil {
+		return err
+	}
+	if len(body.Password) == 0 {
+		return types.ErrorInvalidPassword
+	}
+
+	user, err := utility.FetchTargetUser(id, tableName, dynaClient, policy)
+	if err != nil {
+		return err
+	}
+
+	_, err = cognitoClient.AdminSetUserPassword(&cognitoidentityprovider.AdminSetUserPasswordInput{
+		Password:   aws.String(body.Password),
+		Permanent:  aws.Bool(false),
+		UserPoolId: aws.String(userPoolID),
+		Username:   aws.String(user.User_ID),
+	})
+	if err != nil {
+		var invalid *cognitoidentityprovider.InvalidPasswordException
+		if errors.As(err, &invalid) {
+			return types.ErrorInvalidPassword
+		}
+		log.Println(err)
+		return types.ErrorCognitoActionFailed
+	}
+
+	//logging
+	if logErr := utility.SendUserActionLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, "set a temporary password for"); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return nil
+}
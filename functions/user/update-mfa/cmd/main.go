@@ -0,0 +1,12 @@
+package main
+
+import (
+	updatemfa "ascenda/functions/user/update-mfa"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), updatemfa.Handler))
+}
@@ -0,0 +1,119 @@
+package updatemfa
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves PUT /users/mfa.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(utility.ParamUserTable, utility.ParamTTL, utility.ParamLogsTable, utility.ParamUserPoolID)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	USER_POOL_ID := cfg.UserPoolID
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	if len(id) > 0 {
+		res := UpdateMFA(id, request, USER_TABLE, LOGS_TABLE, TTL, deps.Dynamo, deps.Cognito, USER_POOL_ID, policy)
+		if res != nil {
+			return utility.Error(request, res), nil
+		}
+		return utility.Text(200, "MFA preference successfully updated"), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+}
+
+// UpdateMFA enables, disables or prefers the user's mfa factors.
+func UpdateMFA(id string, req events.APIGatewayProxyRequest, tableName string, logTable string, ttl string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	userPoolID string, policy *types.Policy) error {
+	preference, err := utility.Decode[types.MFAPreference](req, validation.MFAPreference)
+	if err != nil {
+		return err
+	}
+
+	input, err := MFAPreferenceInput(preference)
+	if err != nil {
+		return err
+	}
+
+	user, err := utility.FetchTargetUser(id, tableName, dynaClient, policy)
+	if err != nil {
+		return err
+	}
+
+	input.UserPoolId = aws.String(userPoolID)
+	input.Username = aws.String(user.User_ID)
+	if _, err := cognitoClient.AdminSetUserMFAPreference(input); err != nil {
+		log.Println(err)
+		return types.ErrorCognitoActionFailed
+	}
+
+	//logging
+	if logErr := utility.SendUserActionLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, "updated the mfa settings of"); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return nil
+}
+
+// MFAPreferenceInput builds the cognito settings for the preference. The
+// preferred factor is enabled with it and may not be disabled in the same
+// request.
+func MFAPreferenceInput(preference types.MFAPreference) (*cognitoidentityprovider.AdminSetUserMFAPreferenceInput, error) {
+	if preference.SMS == nil && preference.SoftwareToken == nil && preference.Preferred == "" {
+		return nil, types.ErrorInvalidMFAPreference
+	}
+
+	input := &cognitoidentityprovider.AdminSetUserMFAPreferenceInput{}
+	if preference.SMS != nil {
+		input.SMSMfaSettings = &cognitoidentityprovider.SMSMfaSettingsType{Enabled: preference.SMS}
+	}
+	if preference.SoftwareToken != nil {
+		input.SoftwareTokenMfaSettings = &cognitoidentityprovider.SoftwareTokenMfaSettingsType{Enabled: preference.SoftwareToken}
+	}
+
+	switch preference.Preferred {
+	case "":
+	case types.MFAFactorSMS:
+		if preference.SMS != nil && !*preference.SMS {
+			return nil, types.ErrorInvalidMFAPreference
+		}
+		input.SMSMfaSettings = &cognitoidentityprovider.SMSMfaSettingsType{Enabled: aws.Bool(true), PreferredMfa: aws.Bool(true)}
+		if input.SoftwareTokenMfaSettings != nil {
+			input.SoftwareTokenMfaSettings.PreferredMfa = aws.Bool(false)
+		}
+	case types.MFAFactorSoftwareToken:
+		if preference.SoftwareToken != nil && !*preference.SoftwareToken {
+			return nil, types.ErrorInvalidMFAPreference
+		}
+		input.SoftwareTokenMfaSettings = &cognitoidentityprovider.SoftwareTokenMfaSettingsType{Enabled: aws.Bool(true), PreferredMfa: aws.Bool(true)}
+		if input.SMSMfaSettings != nil {
+			input.SMSMfaSettings.PreferredMfa = aws.Bool(false)
+		}
+	default:
+		return nil, types.ErrorInvalidMFAPreference
+	}
+	return input, nil
+}
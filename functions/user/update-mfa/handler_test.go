@@ -0,0 +1,92 @@
+package updatemfa
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+func TestMFAPreferenceInput(t *testing.T) {
+	tests := []struct {
+		name          string
+		preference    types.MFAPreference
+		wantErr       bool
+		wantSMS       *bool
+		wantPreferred string
+	}{
+		{"empty preference", types.MFAPreference{}, true, nil, ""},
+		{"unknown factor", types.MFAPreference{Preferred: "EMAIL"}, true, nil, ""},
+		{"preferring a disabled factor", types.MFAPreference{SMS: aws.Bool(false), Preferred: types.MFAFactorSMS}, true, nil, ""},
+		{"disable sms", types.MFAPreference{SMS: aws.Bool(false)}, false, aws.Bool(false), ""},
+		{"prefer software token", types.MFAPreference{SMS: aws.Bool(true), Preferred: types.MFAFactorSoftwareToken}, false, aws.Bool(true), types.MFAFactorSoftwareToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := MFAPreferenceInput(tt.preference)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MFAPreferenceInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantSMS != nil && aws.BoolValue(input.SMSMfaSettings.Enabled) != *tt.wantSMS {
+				t.Errorf("sms enabled = %v, want %v", aws.BoolValue(input.SMSMfaSettings.Enabled), *tt.wantSMS)
+			}
+			if tt.wantPreferred == types.MFAFactorSoftwareToken {
+				if !aws.BoolValue(input.SoftwareTokenMfaSettings.PreferredMfa) || aws.BoolValue(input.SMSMfaSettings.PreferredMfa) {
+					t.Error("software token not the only preferred factor")
+				}
+			}
+		})
+	}
+}
+
+// fakeCognito records the mfa preferences it is sent.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	inputs []*cognitoidentityprovider.AdminSetUserMFAPreferenceInput
+}
+
+func (f *fakeCognito) AdminSetUserMFAPreference(input *cognitoidentityprovider.AdminSetUserMFAPreferenceInput) (*cognitoidentityprovider.AdminSetUserMFAPreferenceOutput, error) {
+	f.inputs = append(f.inputs, input)
+	return &cognitoidentityprovider.AdminSetUserMFAPreferenceOutput{}, nil
+}
+
+func TestUpdateMFA(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		body    string
+		wantErr error
+	}{
+		{"prefer software token", "1", `{"software_token":true,"preferred":"SOFTWARE_TOKEN"}`, nil},
+		{"invalid preference checked before the user", "3", `{}`, types.ErrorInvalidMFAPreference},
+		{"deleted user", "2", `{"sms":false}`, types.ErrorUserAlreadyDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "users",
+				types.User{User_ID: "1", FirstName: "Jane", LastName: "Doe", Role: "customer"},
+				types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDeleted})
+			cognitoClient := &fakeCognito{}
+			req := events.APIGatewayProxyRequest{Body: tt.body, QueryStringParameters: map[string]string{"requester": "Ada-Admin"}}
+
+			err := UpdateMFA(tt.id, req, "users", "logs", "30", db, cognitoClient, "pool", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateMFA() error = %v, want %v", err, tt.wantErr)
+			}
+			if wrote := len(cognitoClient.inputs) == 1 && len(db.Items("logs")) == 1; wrote != (tt.wantErr == nil) {
+				t.Errorf("cognito inputs = %v, logs = %v", cognitoClient.inputs, db.Items("logs"))
+			}
+		})
+	}
+}
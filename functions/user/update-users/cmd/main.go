@@ -0,0 +1,12 @@
+package main
+
+import (
+	updateusers "ascenda/functions/user/update-users"
+	"ascenda/utility"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	lambda.Start(utility.API(utility.MustDeps(), updateusers.Handler))
+}
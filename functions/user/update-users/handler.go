@@ -0,0 +1,139 @@
+package updateusers
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"ascenda/validation"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Handler serves PUT /users and PATCH /users.
+func Handler(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	//getting variables
+	user_id := request.QueryStringParameters["id"]
+
+	// Get the parameter value
+	cfg, err := deps.Config.Load(
+		utility.ParamUserTable, utility.ParamEmailsTable, utility.ParamTTL, utility.ParamLogsTable,
+		utility.ParamRolesTable, utility.ParamUserPoolID, utility.ParamReconciliationQueueURL,
+	)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+	USER_TABLE := cfg.UserTable
+	EMAILS_TABLE := cfg.EmailsTable
+	TTL := cfg.TTL
+	LOGS_TABLE := cfg.LogsTable
+	ROLES_TABLE := cfg.RolesTable
+	USER_POOL_ID := cfg.UserPoolID
+	RECONCILIATION_QUEUE_URL := cfg.ReconciliationQueueURL
+
+	reconciler := deps.Reconciler(RECONCILIATION_QUEUE_URL)
+
+	policy, err := utility.PolicyFromRequest(request)
+	if err != nil {
+		return utility.Error(request, err), nil
+	}
+
+	//checking if user id is specified, if yes then update user in dynamo func
+	if len(user_id) > 0 {
+		res, err := UpdateUser(user_id, request, USER_TABLE, EMAILS_TABLE, LOGS_TABLE, ROLES_TABLE, TTL, deps.Dynamo, deps.Cognito, USER_POOL_ID,
+			policy, reconciler)
+		if err != nil {
+			return utility.Error(request, err), nil
+		}
+
+		return utility.JSON(200, res), nil
+	}
+
+	return utility.Error(request, types.MissingParameter("id")), nil
+
+}
+
+// UpdateUser applies a partial update to the user. Only the fields present in
+// the body change, they are validated as on create and only the changed
+// attributes are written to dynamo and cognito.
+func UpdateUser(id string, req events.APIGatewayProxyRequest, tableName string, emailsTable string, logTable string, rolesTable string,
+	ttl string, dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI, userPoolID string,
+	policy *types.Policy, reconciler utility.Reconciler) (*types.User, error) {
+	//decode body into user patch
+	patch, err := utility.Decode[types.UserPatch](req, validation.UserPatch)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		err := types.ErrorInvalidUserID
+		return nil, err
+	}
+
+	//checking if user exist
+	checkUser := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(id),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(checkUser)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+
+	if result.Item == nil {
+		return nil, types.ErrorUserDoesNotExist
+	}
+
+	var current types.User
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &current); err != nil {
+		return nil, types.ErrorFailedToUnmarshal
+	}
+	user := utility.ApplyUserPatch(current, patch)
+	fields := utility.ChangedUserFields(current, user)
+
+	//checking role policy against the stored user
+	if !utility.CanTargetRole(policy, current.Role) || !utility.CanTargetRole(policy, user.Role) ||
+		!utility.CanUpdateUserFields(policy, fields) {
+		return nil, types.ErrorNotPermittedByPolicy
+	}
+
+	//status only changes through disable, delete and restore
+	if utility.IsUserDeleted(current) {
+		return nil, types.ErrorUserAlreadyDeleted
+	}
+
+	if patch.Role != nil {
+		exists, err := utility.RoleExists(user.Role, rolesTable, dynaClient)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, types.ErrorUnknownRole
+		}
+	}
+
+	if len(fields) == 0 {
+		return &user, nil
+	}
+
+	//update dynamo then cognito, restoring the stored fields on failure
+	if err := utility.SaveUserChanges(current, user, tableName, emailsTable, userPoolID, dynaClient, cognitoClient, reconciler); err != nil {
+		return nil, err
+	}
+
+	//logging
+	if logErr := utility.SendUpdateUserLogs(req, dynaClient, logTable, ttl, user.FirstName, user.LastName, fields); logErr != nil {
+		log.Println("Logging err :", logErr)
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,150 @@
+package updateusers
+
+import (
+	"ascenda/types"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo holds one stored user, knows the customer and admin roles and
+// records the update expression and log descriptions it is sent.
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	user    map[string]*dynamodb.AttributeValue
+	updates []*dynamodb.Update
+	logs    []string
+}
+
+func (f *fakeDynamo) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	if role, ok := input.Key["role"]; ok && (*role.S == "customer" || *role.S == "admin") {
+		return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{"role": role}}, nil
+	}
+	if _, ok := input.Key["user_id"]; ok {
+		return &dynamodb.GetItemOutput{Item: f.user}, nil
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func (f *fakeDynamo) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	for _, item := range input.TransactItems {
+		if item.Update != nil {
+			f.updates = append(f.updates, item.Update)
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (f *fakeDynamo) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	f.logs = append(f.logs, *input.Item["description"].S)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// fakeCognito records the attributes it is asked to update.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	attributes []string
+}
+
+func (f *fakeCognito) AdminUpdateUserAttributes(input *cognitoidentityprovider.AdminUpdateUserAttributesInput) (*cognitoidentityprovider.AdminUpdateUserAttributesOutput, error) {
+	for _, attribute := range input.UserAttributes {
+		f.attributes = append(f.attributes, *attribute.Name)
+	}
+	return &cognitoidentityprovider.AdminUpdateUserAttributesOutput{}, nil
+}
+
+func storedUser() map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"user_id":    {S: aws.String("1")},
+		"email":      {S: aws.String("jane@example.com")},
+		"first_name": {S: aws.String("Jane")},
+		"last_name":  {S: aws.String("Doe")},
+		"role":       {S: aws.String("customer")},
+	}
+}
+
+func TestUpdateUserPatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantErr        error
+		wantExpression string
+		wantCognito    string
+		wantLog        string
+	}{
+		{
+			name:           "only supplied fields change",
+			body:           `{"last_name":"Smith"}`,
+			wantExpression: "SET #last_name = :last_name",
+			wantCognito:    "name",
+			wantLog:        "updated last_name for Jane Smith",
+		},
+		{
+			name:           "role only syncs custom:role",
+			body:           `{"role":"admin","first_name":"Jane"}`,
+			wantExpression: "SET #role = :role",
+			wantCognito:    "custom:role",
+			wantLog:        "updated role for Jane Doe",
+		},
+		{
+			name: "unchanged values write nothing",
+			body: `{"email":"jane@example.com"}`,
+		},
+		{name: "invalid email", body: `{"email":"nope"}`, wantErr: types.ErrorValidationFailed},
+		{name: "blank first name", body: `{"first_name":" "}`, wantErr: types.ErrorValidationFailed},
+		{name: "unknown role", body: `{"role":"ghost"}`, wantErr: types.ErrorUnknownRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynaClient := &fakeDynamo{user: storedUser()}
+			cognitoClient := &fakeCognito{}
+			req := events.APIGatewayProxyRequest{
+				Body:                  tt.body,
+				QueryStringParameters: map[string]string{"requester": "Ada-Admin"},
+			}
+
+			user, err := UpdateUser("1", req, "users", "emails", "logs", "roles", "30", dynaClient, cognitoClient, "pool", nil, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateUser() error = %v, want %s", err, tt.wantErr)
+				}
+				if len(dynaClient.updates) != 0 {
+					t.Error("invalid update was written")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+			if user.Email != "jane@example.com" {
+				t.Errorf("email = %q, omitted field was not kept", user.Email)
+			}
+
+			var expression string
+			if len(dynaClient.updates) > 0 {
+				expression = *dynaClient.updates[0].UpdateExpression
+			}
+			if expression != tt.wantExpression {
+				t.Errorf("update expression = %q, want %q", expression, tt.wantExpression)
+			}
+			if got := strings.Join(cognitoClient.attributes, ","); got != tt.wantCognito {
+				t.Errorf("cognito attributes = %q, want %q", got, tt.wantCognito)
+			}
+			var logged string
+			if len(dynaClient.logs) > 0 {
+				logged = dynaClient.logs[0]
+			}
+			if !strings.HasSuffix(logged, tt.wantLog) {
+				t.Errorf("log = %q, want suffix %q", logged, tt.wantLog)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package localserver
+
+import (
+	getlogs "ascenda/functions/administrative/get-logs"
+	getopenapi "ascenda/functions/administrative/get-openapi"
+	getreconciliation "ascenda/functions/administrative/get-reconciliation"
+	createmakers "ascenda/functions/maker/create-makers"
+	getcheckers "ascenda/functions/maker/get-checkers"
+	getmakers "ascenda/functions/maker/get-makers"
+	updatecheckers "ascenda/functions/maker/update-checkers"
+	createpoints "ascenda/functions/point/create-points"
+	getpoints "ascenda/functions/point/get-points"
+	updatepoints "ascenda/functions/point/update-points"
+	getprofile "ascenda/functions/profile/get-profile"
+	getprofilepoints "ascenda/functions/profile/get-profile-points"
+	updateprofile "ascenda/functions/profile/update-profile"
+	createroles "ascenda/functions/role/create-roles"
+	deleteroles "ascenda/functions/role/delete-roles"
+	getroles "ascenda/functions/role/get-roles"
+	updateroles "ascenda/functions/role/update-roles"
+	createusers "ascenda/functions/user/create-users"
+	deleteusers "ascenda/functions/user/delete-users"
+	disableusers "ascenda/functions/user/disable-users"
+	getimports "ascenda/functions/user/get-imports"
+	getsessions "ascenda/functions/user/get-sessions"
+	getusers "ascenda/functions/user/get-users"
+	globalsignout "ascenda/functions/user/global-sign-out"
+	importusers "ascenda/functions/user/import-users"
+	resetpassword "ascenda/functions/user/reset-password"
+	restoreusers "ascenda/functions/user/restore-users"
+	settemporarypassword "ascenda/functions/user/set-temporary-password"
+	updatemfa "ascenda/functions/user/update-mfa"
+	updateusers "ascenda/functions/user/update-users"
+	"ascenda/utility"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Handlers maps the CodeUri of every api function in template.yaml to its
+// handler.
+var Handlers = map[string]utility.APIHandler{
+	"functions/user/get-users":                    getusers.Handler,
+	"functions/user/create-users":                 createusers.Handler,
+	"functions/user/update-users":                 updateusers.Handler,
+	"functions/user/delete-users":                 deleteusers.Handler,
+	"functions/user/disable-users":                disableusers.Handler,
+	"functions/user/restore-users":                restoreusers.Handler,
+	"functions/user/reset-password":               resetpassword.Handler,
+	"functions/user/set-temporary-password":       settemporarypassword.Handler,
+	"functions/user/update-mfa":                   updatemfa.Handler,
+	"functions/user/global-sign-out":              globalsignout.Handler,
+	"functions/user/get-sessions":                 getsessions.Handler,
+	"functions/user/import-users":                 importusers.Handler,
+	"functions/user/get-imports":                  getimports.Handler,
+	"functions/point/get-points":                  getpoints.Handler,
+	"functions/point/create-points":               createpoints.Handler,
+	"functions/point/update-points":               updatepoints.Handler,
+	"functions/maker/get-makers":                  getmakers.Handler,
+	"functions/maker/create-makers":               createmakers.Handler,
+	"functions/maker/get-checkers":                getcheckers.Handler,
+	"functions/maker/update-checkers":             updatecheckers.Handler,
+	"functions/role/get-roles":                    getroles.Handler,
+	"functions/role/create-roles":                 createroles.Handler,
+	"functions/role/update-roles":                 updateroles.Handler,
+	"functions/role/delete-roles":                 deleteroles.Handler,
+	"functions/profile/get-profile":               getprofile.Handler,
+	"functions/profile/update-profile":            updateprofile.Handler,
+	"functions/profile/get-profile-points":        getprofilepoints.Handler,
+	"functions/administrative/get-logs":           getlogs.Handler,
+	"functions/administrative/get-reconciliation": getreconciliation.Handler,
+	"functions/administrative/get-openapi":        getopenapi.Handler,
+}
+
+// Route is an api event of a function in template.yaml.
+type Route struct {
+	Method  string
+	Path    string
+	CodeURI string
+}
+
+// samTemplate is the part of a SAM template routes are read from. Resources
+// and Events are kept as nodes so the routes come out in template order.
+type samTemplate struct {
+	Resources yaml.Node `yaml:"Resources"`
+}
+
+type samFunction struct {
+	Type       string `yaml:"Type"`
+	Properties struct {
+		CodeUri string    `yaml:"CodeUri"`
+		Events  yaml.Node `yaml:"Events"`
+	} `yaml:"Properties"`
+}
+
+type samEvent struct {
+	Type       string `yaml:"Type"`
+	Properties struct {
+		Path   string `yaml:"Path"`
+		Method string `yaml:"Method"`
+	} `yaml:"Properties"`
+}
+
+// ReadRoutes returns the Api events of the serverless functions in the SAM
+// template at path, in the order the template declares them.
+func ReadRoutes(path string) ([]Route, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var template samTemplate
+	if err := yaml.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var routes []Route
+	for _, resource := range entries(&template.Resources) {
+		name := resource.Key.Value
+		var function samFunction
+		if err := resource.Value.Decode(&function); err != nil {
+			return nil, fmt.Errorf("%s: resource %s: %w", path, name, err)
+		}
+		if function.Type != "AWS::Serverless::Function" {
+			continue
+		}
+		for _, entry := range entries(&function.Properties.Events) {
+			event := entry.Key.Value
+			var api samEvent
+			if err := entry.Value.Decode(&api); err != nil {
+				return nil, fmt.Errorf("%s: event %s of %s: %w", path, event, name, err)
+			}
+			if api.Type != "Api" {
+				continue
+			}
+			if function.Properties.CodeUri == "" || api.Properties.Path == "" || api.Properties.Method == "" {
+				return nil, fmt.Errorf("%s: event %s of %s needs a CodeUri, Path and Method", path, event, name)
+			}
+			routes = append(routes, Route{
+				Method:  strings.ToUpper(api.Properties.Method),
+				Path:    api.Properties.Path,
+				CodeURI: strings.TrimSuffix(function.Properties.CodeUri, "/"),
+			})
+		}
+	}
+	return routes, nil
+}
+
+// entry is a key and its value in a yaml mapping.
+type entry struct {
+	Key, Value *yaml.Node
+}
+
+// entries returns the entries of a yaml mapping node in document order. It is
+// empty for any other node.
+func entries(node *yaml.Node) []entry {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var found []entry
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		found = append(found, entry{Key: node.Content[i], Value: node.Content[i+1]})
+	}
+	return found
+}
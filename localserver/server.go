@@ -0,0 +1,234 @@
+// Package localserver serves the api routes of template.yaml over net/http by
+// calling the function handlers in process, so endpoints can be tried without
+// deploying to AWS.
+package localserver
+
+import (
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
+)
+
+// Stage is the api stage reported to handlers.
+const Stage = "local"
+
+var (
+	errRouteNotFound    = &types.Error{Status: 404, Code: "route_not_found", Message: "no route matches the request"}
+	errMethodNotAllowed = &types.Error{Status: 405, Code: "method_not_allowed", Message: "method not allowed on this route"}
+)
+
+// Authorizer is a lambda REQUEST authorizer run in front of every route, as
+// API Gateway runs it for the REST api.
+type Authorizer func(deps *utility.Deps, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (
+	events.APIGatewayCustomAuthorizerResponse, error)
+
+// Server translates http requests to api gateway proxy requests for the
+// handler of their route.
+type Server struct {
+	Deps *utility.Deps
+	// Authorizer, when set, decides every request and its context is passed
+	// to the handler as API Gateway does.
+	Authorizer Authorizer
+	// UserID is passed to the handler as the caller when there is no
+	// Authorizer, for the self service routes. Without an Authorizer every
+	// request is also given an unrestricted policy, as a full access role.
+	UserID string
+
+	routes map[string]map[string]utility.APIHandler
+}
+
+// New serves routes with the handler registered for their CodeURI in
+// handlers, failing if a route has none.
+func New(deps *utility.Deps, routes []Route, handlers map[string]utility.APIHandler) (*Server, error) {
+	server := &Server{
+		Deps:   deps,
+		routes: map[string]map[string]utility.APIHandler{},
+	}
+	for _, route := range routes {
+		handler, ok := handlers[route.CodeURI]
+		if !ok {
+			return nil, fmt.Errorf("no handler for %s %s in %s", route.Method, route.Path, route.CodeURI)
+		}
+		if server.routes[route.Path] == nil {
+			server.routes[route.Path] = map[string]utility.APIHandler{}
+		}
+		server.routes[route.Path][route.Method] = handler
+	}
+	return server, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request := ProxyRequest(r, body)
+
+	response := s.serve(r, request)
+	log.Printf("%s %s %d", r.Method, r.URL.Path, response.StatusCode)
+	writeResponse(w, response)
+}
+
+func (s *Server) serve(r *http.Request, request events.APIGatewayProxyRequest) events.APIGatewayProxyResponse {
+	methods, ok := s.routes[r.URL.Path]
+	if !ok {
+		return utility.Error(request, errRouteNotFound)
+	}
+	handler, ok := methods[r.Method]
+	if !ok {
+		return utility.Error(request, errMethodNotAllowed)
+	}
+
+	if s.Authorizer != nil {
+		authorizerContext, err := s.authorize(r, request)
+		if err != nil {
+			return utility.Error(request, err)
+		}
+		request.RequestContext.Authorizer = authorizerContext
+	} else {
+		request.RequestContext.Authorizer = map[string]interface{}{utility.PolicyContextKey: "{}"}
+		if s.UserID != "" {
+			request.RequestContext.Authorizer[utility.UserIDContextKey] = s.UserID
+		}
+	}
+
+	response, _ := utility.API(s.Deps, handler)(request)
+	return response
+}
+
+// authorize runs the Authorizer on the request, returning the context it
+// grants.
+func (s *Server) authorize(r *http.Request, request events.APIGatewayProxyRequest) (map[string]interface{}, error) {
+	if r.Header.Get("Authorization") == "" {
+		return nil, types.ErrorNotAuthenticated
+	}
+
+	response, err := s.Authorizer(s.Deps, events.APIGatewayCustomAuthorizerRequestTypeRequest{
+		Type:                  "REQUEST",
+		MethodArn:             "arn:aws:execute-api:local:000000000000:local/" + Stage + "/" + r.Method + r.URL.Path,
+		Resource:              r.URL.Path,
+		Path:                  r.URL.Path,
+		HTTPMethod:            r.Method,
+		Headers:               request.Headers,
+		MultiValueHeaders:     request.MultiValueHeaders,
+		QueryStringParameters: request.QueryStringParameters,
+		RequestContext: events.APIGatewayCustomAuthorizerRequestTypeRequestContext{
+			RequestID:    request.RequestContext.RequestID,
+			Stage:        Stage,
+			ResourcePath: r.URL.Path,
+			HTTPMethod:   r.Method,
+			Path:         r.URL.Path,
+		},
+	})
+	//api gateway answers the authorizer's Unauthorized error with 401 and any other with 500
+	if err != nil && err.Error() == "Unauthorized" {
+		return nil, types.ErrorNotAuthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !allows(response.PolicyDocument) {
+		return nil, types.ErrorNotPermittedByPolicy
+	}
+	return response.Context, nil
+}
+
+// allows reports whether the policy allows the request, denying any request
+// it does not explicitly allow.
+func allows(policy events.APIGatewayCustomAuthorizerPolicy) bool {
+	allowed := false
+	for _, statement := range policy.Statement {
+		switch statement.Effect {
+		case "Deny":
+			return false
+		case "Allow":
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+// ProxyRequest translates r, whose body was read into body, to the request
+// API Gateway passes to a proxy integration.
+func ProxyRequest(r *http.Request, body []byte) events.APIGatewayProxyRequest {
+	request := events.APIGatewayProxyRequest{
+		Resource:   r.URL.Path,
+		Path:       r.URL.Path,
+		HTTPMethod: r.Method,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID:        uuid.NewString(),
+			Stage:            Stage,
+			ResourcePath:     r.URL.Path,
+			HTTPMethod:       r.Method,
+			Path:             r.URL.Path,
+			RequestTimeEpoch: time.Now().UnixMilli(),
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  r.RemoteAddr,
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}
+
+	if len(r.Header) > 0 {
+		request.Headers = map[string]string{}
+		request.MultiValueHeaders = map[string][]string{}
+		for name, values := range r.Header {
+			request.Headers[name] = values[len(values)-1]
+			request.MultiValueHeaders[name] = values
+		}
+	}
+	if query := r.URL.Query(); len(query) > 0 {
+		request.QueryStringParameters = map[string]string{}
+		request.MultiValueQueryStringParameters = map[string][]string{}
+		for name, values := range query {
+			request.QueryStringParameters[name] = values[len(values)-1]
+			request.MultiValueQueryStringParameters[name] = values
+		}
+	}
+
+	if utf8.Valid(body) {
+		request.Body = string(body)
+	} else {
+		request.Body = base64.StdEncoding.EncodeToString(body)
+		request.IsBase64Encoded = true
+	}
+	return request
+}
+
+func writeResponse(w http.ResponseWriter, response events.APIGatewayProxyResponse) {
+	for name, values := range response.MultiValueHeaders {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(response.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		body = decoded
+	}
+
+	status := response.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
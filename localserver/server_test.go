@@ -0,0 +1,231 @@
+package localserver
+
+import (
+	"ascenda/awstest"
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestTemplateRoutesHaveHandlers(t *testing.T) {
+	routes, err := ReadRoutes("../template.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) == 0 {
+		t.Fatal("ReadRoutes() found no routes")
+	}
+	if _, err := New(&utility.Deps{}, routes, Handlers); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadRoutes(t *testing.T) {
+	const template = `Resources:
+  Queue:
+    Type: AWS::SQS::Queue
+  ListItems:   # a comment
+    Type: AWS::Serverless::Function
+    Properties:
+      CodeUri: functions/item/get-items/
+      Role: !Sub arn:aws:iam::${AWS::AccountId}:role/ItemRole
+      Events:
+        List: {Type: Api, Properties: {Path: /items, Method: get, RestApiId: !Ref Api}}
+        Nightly:
+          Type: Schedule
+          Properties: {Schedule: rate(1 day)}
+        Delete:
+          Type: Api
+          Properties:
+            Path: /items
+            Method: delete
+  CreateItems:
+    Type: AWS::Serverless::Function
+    Properties:
+      CodeUri: functions/item/create-items
+      Events:
+        Create:
+          Type: Api
+          Properties:
+            Path: /items
+            Method: post
+`
+	path := filepath.Join(t.TempDir(), "template.yaml")
+	if err := os.WriteFile(path, []byte(template), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := ReadRoutes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Route{
+		{Method: "GET", Path: "/items", CodeURI: "functions/item/get-items"},
+		{Method: "DELETE", Path: "/items", CodeURI: "functions/item/get-items"},
+		{Method: "POST", Path: "/items", CodeURI: "functions/item/create-items"},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("ReadRoutes() = %v, want %v", routes, want)
+	}
+
+	missing := strings.Replace(template, "Method: post", "", 1)
+	if err := os.WriteFile(path, []byte(missing), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadRoutes(path); err == nil {
+		t.Error("ReadRoutes() accepted an api event without a method")
+	}
+}
+
+// TestProfileRoutesNeedAuthorizerContext checks the self service routes of
+// template.yaml only serve callers whose user_id the authorizer passed on.
+func TestProfileRoutesNeedAuthorizerContext(t *testing.T) {
+	const userID = "6c5f1f0e-2d3a-4b8c-9e7f-0a1b2c3d4e02"
+	routes, err := ReadRoutes("../template.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withUser := func(deps *utility.Deps, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (
+		events.APIGatewayCustomAuthorizerResponse, error) {
+		return events.APIGatewayCustomAuthorizerResponse{
+			PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+				Statement: []events.IAMPolicyStatement{{Effect: "Allow", Resource: []string{request.MethodArn}}},
+			},
+			Context: map[string]interface{}{utility.UserIDContextKey: userID},
+		}, nil
+	}
+	tests := []struct {
+		name       string
+		authorizer Authorizer
+		wantStatus int
+	}{
+		{"without authorizer context", nil, 401},
+		{"with authorizer context", withUser, 200},
+	}
+
+	for _, tt := range tests {
+		for _, path := range []string{"/me", "/me/points"} {
+			t.Run(tt.name+" "+path, func(t *testing.T) {
+				db := dynamotest.New(dynamotest.Tables...)
+				db.Seed(t, "users", types.User{User_ID: userID, Email: "jane@example.com", Role: "customer"})
+				db.Seed(t, "points", types.UserPoint{User_ID: userID, Points_ID: "points-1", Points: 10})
+				server, err := New(awstest.Deps(db), routes, Handlers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				server.Authorizer = tt.authorizer
+
+				req := httptest.NewRequest("GET", path, nil)
+				req.Header.Set("Authorization", "token")
+				res := httptest.NewRecorder()
+				server.ServeHTTP(res, req)
+				if res.Code != tt.wantStatus {
+					t.Errorf("GET %s = %d %q, want %d", path, res.Code, res.Body.String(), tt.wantStatus)
+				}
+			})
+		}
+	}
+}
+
+// echo answers with the request it was given.
+func echo(deps *utility.Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return utility.JSON(201, request), nil
+}
+
+func newTestServer(t *testing.T) *Server {
+	server, err := New(&utility.Deps{}, []Route{{Method: "POST", Path: "/users", CodeURI: "functions/user/create-users"}},
+		map[string]utility.APIHandler{"functions/user/create-users": echo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestServeHTTPTranslatesRequest(t *testing.T) {
+	server := newTestServer(t)
+	server.UserID = "user-1"
+
+	req := httptest.NewRequest("POST", "/users?id=42", strings.NewReader(`{"email":"jane@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	server.ServeHTTP(res, req)
+
+	var got events.APIGatewayProxyRequest
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || res.Code != 201 {
+		t.Fatalf("ServeHTTP() = %d %q", res.Code, res.Body.String())
+	}
+	if got.HTTPMethod != "POST" || got.QueryStringParameters["id"] != "42" || got.Body != `{"email":"jane@example.com"}` ||
+		got.Headers["Content-Type"] != "application/json" || got.RequestContext.Authorizer[utility.UserIDContextKey] != "user-1" {
+		t.Errorf("handler request = %+v", got)
+	}
+}
+
+func TestServeHTTPErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		authorizer Authorizer
+		header     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"unknown route", "GET", "/nope", nil, "", 404, "route_not_found"},
+		{"unknown method", "DELETE", "/users", nil, "", 405, "method_not_allowed"},
+		{"no token", "POST", "/users", allow(true), "", 401, "not_authenticated"},
+		{"invalid token", "POST", "/users", allow(true), "stale", 401, "not_authenticated"},
+		{"denied", "POST", "/users", allow(false), "token", 403, "not_permitted"},
+		{"allowed", "POST", "/users", allow(true), "token", 201, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			server.Authorizer = tt.authorizer
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			res := httptest.NewRecorder()
+			server.ServeHTTP(res, req)
+
+			var body types.ErrorResponse
+			json.Unmarshal(res.Body.Bytes(), &body)
+			if res.Code != tt.wantStatus || body.Code != tt.wantCode {
+				t.Errorf("ServeHTTP() = %d %q, want %d %s", res.Code, res.Body.String(), tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func allow(authorized bool) Authorizer {
+	return func(deps *utility.Deps, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (
+		events.APIGatewayCustomAuthorizerResponse, error) {
+		if request.Headers["Authorization"] == "stale" {
+			return events.APIGatewayCustomAuthorizerResponse{}, errors.New("Unauthorized")
+		}
+		if request.Headers["Authorization"] != "token" || request.HTTPMethod != "POST" || request.Resource != "/users" {
+			return events.APIGatewayCustomAuthorizerResponse{}, nil
+		}
+		effect := "Deny"
+		if authorized {
+			effect = "Allow"
+		}
+		return events.APIGatewayCustomAuthorizerResponse{PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Statement: []events.IAMPolicyStatement{{Effect: effect, Resource: []string{request.MethodArn}}},
+		}}, nil
+	}
+}
+
+var _ http.Handler = (*Server)(nil)
@@ -0,0 +1,407 @@
+// Package migrations backfills existing items when the shape of a table
+// changes. Migrations are numbered steps that each update the items of one
+// table. Run applies the pending ones in order, scanning each table in
+// parallel segments, and records them in the table named by MIGRATIONS_TABLE,
+// checkpointing every page so a failed run resumes where it stopped.
+package migrations
+
+import (
+	"ascenda/schema"
+	"ascenda/types"
+	"ascenda/utility"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Change is an update of one item, made with UpdateItem on the item's key.
+// The item is skipped when it was deleted since it was scanned or the
+// condition no longer holds.
+type Change struct {
+	UpdateExpression          string
+	ConditionExpression       string
+	ExpressionAttributeNames  map[string]*string
+	ExpressionAttributeValues map[string]*dynamodb.AttributeValue
+}
+
+// Migration is one numbered step over the items of a table. Apply is called
+// with every item and returns the change to make, or nil to leave the item as
+// it is. Steps must be idempotent: an item changed by an interrupted run, or
+// already written in the new shape by the handlers, is passed again and must
+// be left as it is, by returning nil or a change whose condition fails.
+type Migration struct {
+	Version     int
+	Description string
+	// Param names the parameter holding the table, such as
+	// utility.ParamPointsTable.
+	Param string
+	Apply func(item map[string]*dynamodb.AttributeValue) (*Change, error)
+}
+
+// Migrations are the steps of the deployment in version order. A step is
+// appended with the next version and never changed once deployed.
+var Migrations = []Migration{}
+
+// DefaultSegments is how many segments a table is scanned in when the request
+// sets none.
+const DefaultSegments = 4
+
+// pageSize is the most items a segment reads between checkpoints.
+var pageSize int64 = 100
+
+// Params returns the parameters Run needs to apply the migrations.
+func Params(migrations []Migration) []string {
+	params := []string{utility.ParamMigrationsTable}
+	seen := map[string]bool{utility.ParamMigrationsTable: true}
+	for _, migration := range migrations {
+		if !seen[migration.Param] {
+			seen[migration.Param] = true
+			params = append(params, migration.Param)
+		}
+	}
+	return params
+}
+
+// Validate checks that the versions are positive and increasing and that
+// every migration has a table of schema.Tables and an Apply.
+func Validate(migrations []Migration) error {
+	last := 0
+	for _, migration := range migrations {
+		if migration.Version <= last {
+			return fmt.Errorf("migration %d must be greater than %d", migration.Version, last)
+		}
+		last = migration.Version
+		if _, ok := schemaTable(migration.Param); !ok {
+			return fmt.Errorf("migration %d: no table is named by %s", migration.Version, migration.Param)
+		}
+		if migration.Apply == nil {
+			return fmt.Errorf("migration %d has no Apply", migration.Version)
+		}
+	}
+	return nil
+}
+
+func schemaTable(param string) (schema.Table, bool) {
+	for _, table := range schema.Tables {
+		if table.Param == param {
+			return table, true
+		}
+	}
+	return schema.Table{}, false
+}
+
+// Run applies the migrations not yet applied, up to the target version, and
+// stops at the first that fails. The report is returned with the error so the
+// progress of the failed migration is known.
+func Run(request types.MigrationRequest, migrations []Migration, cfg *utility.Config,
+	dynaClient dynamodbiface.DynamoDBAPI) (*types.MigrationReport, error) {
+	if err := Validate(migrations); err != nil {
+		return nil, err
+	}
+	segments := request.Segments
+	if segments <= 0 {
+		segments = DefaultSegments
+	}
+
+	records, err := FetchRecords(cfg.MigrationsTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.MigrationReport{DryRun: request.DryRun, AlreadyApplied: []int{}, Migrations: []types.MigrationResult{}}
+	for _, migration := range migrations {
+		if request.Target > 0 && migration.Version > request.Target {
+			break
+		}
+		record, ok := records[migration.Version]
+		if ok && record.Status == types.MigrationApplied {
+			report.AlreadyApplied = append(report.AlreadyApplied, migration.Version)
+			continue
+		}
+
+		r := &run{
+			migration:       migration,
+			dryRun:          request.DryRun,
+			tableName:       cfg.Value(migration.Param),
+			migrationsTable: cfg.MigrationsTable,
+			dynaClient:      dynaClient,
+			result:          types.MigrationResult{Version: migration.Version, Description: migration.Description},
+		}
+		r.table, _ = schemaTable(migration.Param)
+		if ok {
+			r.record = record
+			r.result.Resumed = true
+		} else {
+			r.record = &Record{MigrationRecord: types.MigrationRecord{
+				Version:       migration.Version,
+				Description:   migration.Description,
+				Status:        types.MigrationRunning,
+				TotalSegments: segments,
+				StartedAt:     time.Now().Unix(),
+			}}
+		}
+
+		err := r.apply()
+		report.Migrations = append(report.Migrations, r.result)
+		if err != nil {
+			return report, fmt.Errorf("migration %d: %w", migration.Version, err)
+		}
+	}
+	return report, nil
+}
+
+// Record is a migration's item in the migrations table with the checkpoints
+// of its segments while it runs.
+type Record struct {
+	types.MigrationRecord
+	// Checkpoints holds the key each started segment continues from, empty
+	// once the segment is done.
+	Checkpoints map[int]map[string]*dynamodb.AttributeValue
+}
+
+// FetchRecords returns the records of the migrations table by version.
+func FetchRecords(tableName string, dynaClient dynamodbiface.DynamoDBAPI) (map[int]*Record, error) {
+	records := map[int]*Record{}
+	input := &dynamodb.ScanInput{TableName: aws.String(tableName)}
+	for {
+		result, err := dynaClient.Scan(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			record := &Record{Checkpoints: map[int]map[string]*dynamodb.AttributeValue{}}
+			if err := dynamodbattribute.UnmarshalMap(item, &record.MigrationRecord); err != nil {
+				return nil, types.ErrorFailedToUnmarshalRecord
+			}
+			if segments := item["segments"]; segments != nil {
+				for segment, checkpoint := range segments.M {
+					n, err := strconv.Atoi(segment)
+					if err != nil || checkpoint.M == nil {
+						return nil, types.ErrorFailedToUnmarshalRecord
+					}
+					record.Checkpoints[n] = checkpoint.M
+				}
+			}
+			records[record.Version] = record
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return records, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// run is the application of one migration.
+type run struct {
+	migration       Migration
+	dryRun          bool
+	tableName       string
+	table           schema.Table
+	migrationsTable string
+	dynaClient      dynamodbiface.DynamoDBAPI
+	record          *Record
+
+	mu     sync.Mutex
+	result types.MigrationResult
+	failed bool
+}
+
+func (r *run) apply() error {
+	if !r.dryRun && !r.result.Resumed {
+		if err := r.start(); err != nil {
+			return err
+		}
+	}
+
+	errs := make([]error, r.record.TotalSegments)
+	var wg sync.WaitGroup
+	for segment := 0; segment < r.record.TotalSegments; segment++ {
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			if errs[segment] = r.segment(segment); errs[segment] != nil {
+				r.mu.Lock()
+				r.failed = true
+				r.mu.Unlock()
+			}
+		}(segment)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if r.dryRun {
+		return nil
+	}
+	if err := r.finish(); err != nil {
+		return err
+	}
+	r.result.Applied = true
+	return nil
+}
+
+// start records the migration as running with no checkpoints.
+func (r *run) start() error {
+	av, err := dynamodbattribute.MarshalMap(r.record.MigrationRecord)
+	if err != nil {
+		return types.ErrorCouldNotMarshalItem
+	}
+	av["segments"] = &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{}}
+
+	_, err = r.dynaClient.PutItem(&dynamodb.PutItemInput{
+		TableName:           aws.String(r.migrationsTable),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(version)"),
+	})
+	return err
+}
+
+// segment scans one segment from its checkpoint, changing the items a page at
+// a time, until it is done or another segment fails.
+func (r *run) segment(segment int) error {
+	startKey, started := r.record.Checkpoints[segment]
+	if started && len(startKey) == 0 {
+		return nil
+	}
+
+	for {
+		r.mu.Lock()
+		failed := r.failed
+		r.mu.Unlock()
+		if failed {
+			return nil
+		}
+
+		result, err := r.dynaClient.Scan(&dynamodb.ScanInput{
+			TableName:         aws.String(r.tableName),
+			Segment:           aws.Int64(int64(segment)),
+			TotalSegments:     aws.Int64(int64(r.record.TotalSegments)),
+			ExclusiveStartKey: startKey,
+			Limit:             aws.Int64(pageSize),
+		})
+		if err != nil {
+			return err
+		}
+
+		var updated, skipped int
+		for _, item := range result.Items {
+			change, err := r.migration.Apply(item)
+			if err != nil {
+				return err
+			}
+			if change == nil {
+				continue
+			}
+			if r.dryRun {
+				updated++
+				continue
+			}
+			ok, err := r.change(item, change)
+			if err != nil {
+				return err
+			}
+			if ok {
+				updated++
+			} else {
+				skipped++
+			}
+		}
+
+		if !r.dryRun {
+			if err := r.checkpoint(segment, result.LastEvaluatedKey, len(result.Items), updated); err != nil {
+				return err
+			}
+		}
+		r.mu.Lock()
+		r.result.Scanned += len(result.Items)
+		r.result.Updated += updated
+		r.result.Skipped += skipped
+		r.mu.Unlock()
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = result.LastEvaluatedKey
+	}
+}
+
+// change updates the item, reporting false when it was skipped.
+func (r *run) change(item map[string]*dynamodb.AttributeValue, change *Change) (bool, error) {
+	key := map[string]*dynamodb.AttributeValue{}
+	for _, attribute := range r.table.Key.Attributes() {
+		key[attribute] = item[attribute]
+	}
+
+	names := map[string]*string{"#migration_key": aws.String(r.table.Key.Hash)}
+	for placeholder, name := range change.ExpressionAttributeNames {
+		names[placeholder] = name
+	}
+	condition := "attribute_exists(#migration_key)"
+	if change.ConditionExpression != "" {
+		condition += " AND (" + change.ConditionExpression + ")"
+	}
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       key,
+		UpdateExpression:          aws.String(change.UpdateExpression),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: change.ExpressionAttributeValues,
+	}
+
+	_, err := r.dynaClient.UpdateItem(input)
+	var conditionFailed *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// checkpoint records the key the segment continues from, none once it is
+// done, and adds the page to the counts of the record.
+func (r *run) checkpoint(segment int, lastEvaluatedKey map[string]*dynamodb.AttributeValue, scanned int, updated int) error {
+	if lastEvaluatedKey == nil {
+		lastEvaluatedKey = map[string]*dynamodb.AttributeValue{}
+	}
+	_, err := r.dynaClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.migrationsTable),
+		Key:              map[string]*dynamodb.AttributeValue{"version": {N: aws.String(strconv.Itoa(r.migration.Version))}},
+		UpdateExpression: aws.String("SET #segments.#segment = :checkpoint ADD scanned :scanned, updated :updated"),
+		ExpressionAttributeNames: map[string]*string{
+			"#segments": aws.String("segments"),
+			"#segment":  aws.String(strconv.Itoa(segment)),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":checkpoint": {M: lastEvaluatedKey},
+			":scanned":    {N: aws.String(strconv.Itoa(scanned))},
+			":updated":    {N: aws.String(strconv.Itoa(updated))},
+		},
+	})
+	return err
+}
+
+// finish records the migration as applied, dropping its checkpoints.
+func (r *run) finish() error {
+	_, err := r.dynaClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                aws.String(r.migrationsTable),
+		Key:                      map[string]*dynamodb.AttributeValue{"version": {N: aws.String(strconv.Itoa(r.migration.Version))}},
+		UpdateExpression:         aws.String("SET #status = :status, applied_at = :applied_at REMOVE #segments"),
+		ExpressionAttributeNames: map[string]*string{"#status": aws.String("status"), "#segments": aws.String("segments")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":status":     {S: aws.String(types.MigrationApplied)},
+			":applied_at": {N: aws.String(strconv.FormatInt(time.Now().Unix(), 10))},
+		},
+	})
+	return err
+}
@@ -0,0 +1,234 @@
+package migrations
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"ascenda/utility"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var cfg = &utility.Config{PointsTable: "points", UserTable: "users", MigrationsTable: "migrations"}
+
+// versionPoints sets a version on points accounts without one.
+var versionPoints = Migration{
+	Version:     1,
+	Description: "version points accounts",
+	Param:       utility.ParamPointsTable,
+	Apply: func(item map[string]*dynamodb.AttributeValue) (*Change, error) {
+		if item["version"] != nil {
+			return nil, nil
+		}
+		return &Change{
+			UpdateExpression:          "SET version = :zero",
+			ConditionExpression:       "attribute_not_exists(version)",
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":zero": {N: aws.String("0")}},
+		}, nil
+	},
+}
+
+// activateUsers sets the status of users without one.
+var activateUsers = Migration{
+	Version:     2,
+	Description: "activate users",
+	Param:       utility.ParamUserTable,
+	Apply: func(item map[string]*dynamodb.AttributeValue) (*Change, error) {
+		if item["status"] != nil {
+			return nil, nil
+		}
+		return &Change{
+			UpdateExpression:          "SET #status = :active",
+			ConditionExpression:       "attribute_not_exists(#status)",
+			ExpressionAttributeNames:  map[string]*string{"#status": aws.String("status")},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":active": {S: aws.String(types.UserStatusActive)}},
+		}, nil
+	},
+}
+
+func seed(t *testing.T, db *dynamotest.DB, accounts int) {
+	for i := 0; i < accounts; i++ {
+		db.Seed(t, "points", types.UserPoint{User_ID: strconv.Itoa(i), Points_ID: "p" + strconv.Itoa(i), Points: i})
+	}
+	db.Seed(t, "users", types.User{User_ID: "1", Role: "admin"}, types.User{User_ID: "2", Role: "customer", Status: types.UserStatusDisabled})
+}
+
+func versioned(db *dynamotest.DB) int {
+	count := 0
+	for _, item := range db.Items("points") {
+		if item["version"] != nil {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRun(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	seed(t, db, 25)
+
+	report, err := Run(types.MigrationRequest{}, []Migration{versionPoints, activateUsers}, cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Migrations) != 2 || report.Migrations[0].Scanned != 25 || report.Migrations[0].Updated != 25 ||
+		report.Migrations[1].Updated != 1 || !report.Migrations[1].Applied {
+		t.Fatalf("report = %+v", report)
+	}
+	if versioned(db) != 25 {
+		t.Errorf("%d accounts versioned, want 25", versioned(db))
+	}
+	var users []types.User
+	db.Load(t, "users", &users)
+	if users[0].Status != types.UserStatusActive || users[1].Status != types.UserStatusDisabled {
+		t.Errorf("users = %+v, want only the first activated", users)
+	}
+
+	records, err := FetchRecords("migrations", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[1].Status != types.MigrationApplied || records[1].Scanned != 25 || records[1].Updated != 25 ||
+		records[1].AppliedAt == 0 || len(records[1].Checkpoints) != 0 {
+		t.Errorf("record = %+v, want applied without checkpoints", records[1])
+	}
+
+	report, err = Run(types.MigrationRequest{}, []Migration{versionPoints, activateUsers}, cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Migrations) != 0 || len(report.AlreadyApplied) != 2 {
+		t.Errorf("second report = %+v, want both already applied", report)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	seed(t, db, 10)
+
+	report, err := Run(types.MigrationRequest{DryRun: true, Target: 1}, []Migration{versionPoints, activateUsers}, cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Migrations) != 1 || report.Migrations[0].Updated != 10 || report.Migrations[0].Applied {
+		t.Fatalf("report = %+v, want version 1 counted only", report)
+	}
+	if versioned(db) != 0 || len(db.Items("migrations")) != 0 {
+		t.Error("dry run wrote items or records")
+	}
+}
+
+// failingDB fails UpdateItem on the points table after a number of calls.
+type failingDB struct {
+	*dynamotest.DB
+	mu    sync.Mutex
+	after int
+}
+
+var errThrottled = errors.New("throttled")
+
+func (db *failingDB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	if *input.TableName == "points" {
+		db.mu.Lock()
+		db.after--
+		failed := db.after < 0
+		db.mu.Unlock()
+		if failed {
+			return nil, errThrottled
+		}
+	}
+	return db.DB.UpdateItem(input)
+}
+
+func TestRunResumes(t *testing.T) {
+	defer func(size int64) { pageSize = size }(pageSize)
+	pageSize = 4
+	db := dynamotest.New(dynamotest.Tables...)
+	seed(t, db, 20)
+
+	failing := &failingDB{DB: db, after: 10}
+	request := types.MigrationRequest{Segments: 2}
+	report, err := Run(request, []Migration{versionPoints}, cfg, failing)
+	if !errors.Is(err, errThrottled) {
+		t.Fatalf("Run() error = %v, want %v", err, errThrottled)
+	}
+	records, err := FetchRecords("migrations", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[1].Status != types.MigrationRunning || len(records[1].Checkpoints) == 0 {
+		t.Fatalf("record = %+v, want running with checkpoints", records[1])
+	}
+	firstScanned := report.Migrations[0].Scanned
+
+	//a different segment count is ignored, the record keeps the one started with
+	report, err = Run(types.MigrationRequest{Segments: 5}, []Migration{versionPoints}, cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := report.Migrations[0]
+	if !result.Resumed || !result.Applied || result.Scanned >= 20 || firstScanned+result.Scanned < 20 {
+		t.Errorf("resumed result = %+v after scanning %d", result, firstScanned)
+	}
+	if versioned(db) != 20 {
+		t.Errorf("%d accounts versioned, want 20", versioned(db))
+	}
+}
+
+func TestRunSkipsDeletedItems(t *testing.T) {
+	db := dynamotest.New(dynamotest.Tables...)
+	seed(t, db, 3)
+
+	//the account is deleted between the scan and its update
+	deleting := versionPoints
+	deleting.Apply = func(item map[string]*dynamodb.AttributeValue) (*Change, error) {
+		if *item["user_id"].S == "1" {
+			_, err := db.DeleteItem(&dynamodb.DeleteItemInput{TableName: aws.String("points"),
+				Key: map[string]*dynamodb.AttributeValue{"user_id": item["user_id"], "points_id": item["points_id"]}})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return versionPoints.Apply(item)
+	}
+
+	report, err := Run(types.MigrationRequest{Segments: 1}, []Migration{deleting}, cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := report.Migrations[0]; result.Updated != 2 || result.Skipped != 1 {
+		t.Errorf("result = %+v, want 2 updated and 1 skipped", result)
+	}
+	if len(db.Items("points")) != 2 {
+		t.Errorf("points = %v, want the deleted account not recreated", db.Items("points"))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(Migrations); err != nil {
+		t.Errorf("Validate(Migrations) error = %v", err)
+	}
+
+	unknownTable := versionPoints
+	unknownTable.Param = utility.ParamTTL
+	tests := []struct {
+		name       string
+		migrations []Migration
+	}{
+		{name: "out of order", migrations: []Migration{activateUsers, versionPoints}},
+		{name: "repeated version", migrations: []Migration{versionPoints, versionPoints}},
+		{name: "unknown table", migrations: []Migration{unknownTable}},
+		{name: "no apply", migrations: []Migration{{Version: 1, Param: utility.ParamUserTable}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.migrations); err == nil {
+				t.Error("Validate() accepted invalid migrations")
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type audit struct {
+	Actor string `json:"actor"`
+}
+
+type account struct {
+	audit
+	ID      string            `json:"id"`
+	Balance int64             `json:"balance"`
+	Note    string            `json:"note,omitempty"`
+	Owner   *account          `json:"owner"`
+	Tags    []string          `json:"tags"`
+	Limits  map[string]int    `json:"limits"`
+	Raw     json.RawMessage   `json:"raw"`
+	Ignored string            `json:"-"`
+	hidden  string            //unexported fields are not encoded
+	Extra   map[string]string `json:"extra,omitempty"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	var components Components
+	schema := components.SchemaOf([]account{})
+	if schema.Type != "array" || !reflect.DeepEqual(schema.Items, Ref("account")) {
+		t.Fatalf("SchemaOf([]account) = %+v", schema)
+	}
+
+	got := components.Schemas["account"]
+	want := []string{"actor", "balance", "id", "limits", "owner", "raw", "tags"}
+	if len(got.Properties) != 9 || got.AdditionalProperties != false {
+		t.Errorf("account = %+v, want the 9 encoded fields only", got)
+	}
+	if !reflect.DeepEqual(sorted(got.Required), want) {
+		t.Errorf("account required = %v, want %v", got.Required, want)
+	}
+
+	tests := []struct {
+		field string
+		want  *Schema
+	}{
+		{"actor", &Schema{Type: "string"}},
+		{"balance", &Schema{Type: "integer", Format: "int64"}},
+		{"owner", &Schema{Nullable: true, AllOf: []*Schema{Ref("account")}}},
+		{"tags", &Schema{Type: "array", Items: &Schema{Type: "string"}, Nullable: true}},
+		{"limits", &Schema{Type: "object", AdditionalProperties: &Schema{Type: "integer"}, Nullable: true}},
+		{"raw", &Schema{}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(got.Properties[tt.field], tt.want) {
+			t.Errorf("%s = %+v, want %+v", tt.field, got.Properties[tt.field], tt.want)
+		}
+	}
+}
+
+func sorted(values []string) []string {
+	values = append([]string(nil), values...)
+	sort.Strings(values)
+	return values
+}
+
+func TestValidate(t *testing.T) {
+	doc := &Document{}
+	schema := doc.Components.SchemaOf(account{})
+	doc.Components.Schemas["status"] = &Schema{Type: "string", Enum: []string{"open", "closed"}}
+	status := &Schema{OneOf: []*Schema{Ref("status"), {Type: "integer", Minimum: Float(0)}}}
+
+	tests := []struct {
+		name   string
+		schema *Schema
+		body   string
+		want   []string
+	}{
+		{name: "valid", schema: schema,
+			body: `{"actor":"a","id":"1","balance":3,"owner":{"actor":"b","id":"2","balance":0,"owner":null,` +
+				`"tags":null,"limits":null,"raw":[1]},"tags":["x"],"limits":{"daily":5},"raw":{"any":true}}`},
+		{name: "wrong types", schema: schema,
+			body: `{"actor":1,"id":"1","balance":1.5,"owner":null,"tags":[2],"limits":{"daily":"5"},"raw":null,"nope":1}`,
+			want: []string{"$.actor: is not a string", "$.balance: is not an integer", `$.limits.daily: is not an integer`,
+				"$.nope: is not a known field", "$.tags[0]: is not a string"}},
+		{name: "missing fields", schema: schema, body: `{"id":"1"}`,
+			want: []string{"$.actor: is required", "$.balance: is required", "$.limits: is required", "$.owner: is required",
+				"$.raw: is required", "$.tags: is required"}},
+		{name: "not an object", schema: schema, body: `[]`, want: []string{"$: is not an object"}},
+		{name: "enum", schema: status, body: `"open"`},
+		{name: "other schema", schema: status, body: `2`},
+		{name: "no schema", schema: status, body: `"shut"`, want: []string{"$: matches 0 of the schemas, want one"}},
+		{name: "below minimum", schema: status, body: `-1`, want: []string{"$: matches 0 of the schemas, want one"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+				t.Fatal(err)
+			}
+			got := sorted(doc.Validate(tt.schema, value))
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
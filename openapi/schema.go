@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var marshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// SchemaOf returns the schema of the json encoding/json produces for values
+// of v's type. Named structs are added to the components and referred to by
+// name, so two different types of the same name cannot be described.
+func (c *Components) SchemaOf(v interface{}) *Schema {
+	return c.schema(reflect.TypeOf(v))
+}
+
+func (c *Components) schema(t reflect.Type) *Schema {
+	//types encoding themselves, such as json.RawMessage, hold any json
+	if t == nil || t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return Nullable(c.schema(t.Elem()))
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice:
+		//nil slices and maps are encoded as null
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: true}
+		}
+		return &Schema{Type: "array", Items: c.schema(t.Elem()), Nullable: true}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: c.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: c.schema(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.object(t)
+		}
+		if c.Schemas == nil {
+			c.Schemas = map[string]*Schema{}
+		}
+		if c.types == nil {
+			c.types = map[string]reflect.Type{}
+		}
+		if existing, ok := c.types[t.Name()]; ok {
+			if existing != t {
+				panic(fmt.Sprintf("openapi: %s and %s are both named %s", existing.PkgPath(), t.PkgPath(), t.Name()))
+			}
+			return Ref(t.Name())
+		}
+		//registered before its fields so recursive types end
+		c.types[t.Name()] = t
+		c.Schemas[t.Name()] = &Schema{}
+		*c.Schemas[t.Name()] = *c.object(t)
+		return Ref(t.Name())
+	}
+	panic(fmt.Sprintf("openapi: %s cannot be encoded as json", t))
+}
+
+// object describes the exported fields of a struct, with the fields that are
+// never omitted as required. Fields of embedded structs are promoted.
+func (c *Components) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	c.fields(t, s)
+	return s
+}
+
+func (c *Components) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				c.fields(embedded, s)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = c.schema(field.Type)
+		if !strings.Contains(","+options+",", ",omitempty,") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// Nullable returns s allowing null as well.
+func Nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		//siblings of a reference are ignored
+		return &Schema{Nullable: true, AllOf: []*Schema{s}}
+	}
+	nullable := *s
+	nullable.Nullable = true
+	return &nullable
+}
@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate returns the ways value, as decoded by encoding/json into an
+// interface{}, does not match the schema, resolving references against the
+// document's components. Formats are not checked.
+func (d *Document) Validate(schema *Schema, value interface{}) []string {
+	return d.validate("$", schema, value)
+}
+
+func (d *Document) validate(path string, s *Schema, value interface{}) []string {
+	if s.Ref != "" {
+		target, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %s", path, s.Ref)}
+		}
+		return d.validate(path, target, value)
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type == "" && len(s.AllOf) == 0 && len(s.OneOf) == 0 {
+			return nil
+		}
+		return []string{path + ": is null"}
+	}
+
+	var problems []string
+	for _, schema := range s.AllOf {
+		problems = append(problems, d.validate(path, schema, value)...)
+	}
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, schema := range s.OneOf {
+			if len(d.validate(path, schema, value)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			problems = append(problems, fmt.Sprintf("%s: matches %d of the schemas, want one", path, matches))
+		}
+	}
+
+	switch s.Type {
+	case "string":
+		v, ok := value.(string)
+		if !ok {
+			return append(problems, path+": is not a string")
+		}
+		if s.MinLength != nil && utf8.RuneCountInString(v) < *s.MinLength {
+			problems = append(problems, fmt.Sprintf("%s: is shorter than %d", path, *s.MinLength))
+		}
+		if s.MaxLength != nil && utf8.RuneCountInString(v) > *s.MaxLength {
+			problems = append(problems, fmt.Sprintf("%s: is longer than %d", path, *s.MaxLength))
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, v) {
+			problems = append(problems, fmt.Sprintf("%s: %q is not one of %s", path, v, strings.Join(s.Enum, ", ")))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, path+": is not a boolean")
+		}
+	case "integer", "number":
+		v, ok := value.(float64)
+		if !ok || s.Type == "integer" && v != math.Trunc(v) {
+			return append(problems, path+": is not an "+s.Type)
+		}
+		if s.Minimum != nil && v < *s.Minimum {
+			problems = append(problems, fmt.Sprintf("%s: is less than %v", path, *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			problems = append(problems, fmt.Sprintf("%s: is greater than %v", path, *s.Maximum))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(problems, path+": is not an array")
+		}
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			problems = append(problems, fmt.Sprintf("%s: has fewer than %d items", path, *s.MinItems))
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				problems = append(problems, d.validate(fmt.Sprintf("%s[%d]", path, i), s.Items, item)...)
+			}
+		}
+	case "object":
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return append(problems, path+": is not an object")
+		}
+		problems = append(problems, d.validateObject(path, s, fields)...)
+	}
+	return problems
+}
+
+func (d *Document) validateObject(path string, s *Schema, fields map[string]interface{}) []string {
+	var problems []string
+	for _, name := range s.Required {
+		if _, ok := fields[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s: is required", path, name))
+		}
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if property, ok := s.Properties[name]; ok {
+			problems = append(problems, d.validate(path+"."+name, property, fields[name])...)
+			continue
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				problems = append(problems, fmt.Sprintf("%s.%s: is not a known field", path, name))
+			}
+		case *Schema:
+			problems = append(problems, d.validate(path+"."+name, additional, fields[name])...)
+		}
+	}
+	return problems
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
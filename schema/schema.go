@@ -0,0 +1,98 @@
+// Package schema defines the DynamoDB tables of the deployment. They are not
+// part of template.yaml, only their names are kept in SSM, so provisioning and
+// the in-memory database of the tests read their keys and indexes from here.
+package schema
+
+import "sort"
+
+// Key names the hash and optional range key attributes of a table or index.
+type Key struct {
+	Hash  string
+	Range string
+}
+
+// Attributes returns the hash then the range attribute, if any.
+func (k Key) Attributes() []string {
+	if k.Range == "" {
+		return []string{k.Hash}
+	}
+	return []string{k.Hash, k.Range}
+}
+
+// Table describes a table and its global secondary indexes by name. Indexes
+// project every attribute.
+type Table struct {
+	// Name is the table's name in development environments and tests.
+	Name string
+	// Param is the parameter holding the table's deployed name.
+	Param   string
+	Key     Key
+	Indexes map[string]Key
+	// Numbers lists the key attributes stored as numbers, the others are
+	// strings.
+	Numbers []string
+	// TTL is the attribute holding the expiry of items, empty when they never
+	// expire.
+	TTL string
+}
+
+// AttributeType returns the dynamo type of the key attribute, S or N.
+func (t Table) AttributeType(attribute string) string {
+	for _, number := range t.Numbers {
+		if number == attribute {
+			return "N"
+		}
+	}
+	return "S"
+}
+
+// KeyAttributes returns every attribute used in the key of the table or one
+// of its indexes, each once.
+func (t Table) KeyAttributes() []string {
+	keys := []Key{t.Key}
+	for _, name := range t.IndexNames() {
+		keys = append(keys, t.Indexes[name])
+	}
+
+	var attributes []string
+	seen := map[string]bool{}
+	for _, key := range keys {
+		for _, attribute := range key.Attributes() {
+			if !seen[attribute] {
+				seen[attribute] = true
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	return attributes
+}
+
+// IndexNames returns the names of the table's indexes in order.
+func (t Table) IndexNames() []string {
+	names := make([]string, 0, len(t.Indexes))
+	for name := range t.Indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tables are the tables of the deployment.
+var Tables = []Table{
+	{Name: "users", Param: "USER_TABLE", Key: Key{Hash: "user_id"}, Indexes: map[string]Key{
+		"role-index": {Hash: "role"},
+	}},
+	{Name: "emails", Param: "EMAILS_TABLE", Key: Key{Hash: "email"}},
+	{Name: "points", Param: "POINTS_TABLE", Key: Key{Hash: "user_id", Range: "points_id"}},
+	{Name: "makers", Param: "MAKER_TABLE", Key: Key{Hash: "req_id", Range: "checker_role"}, Indexes: map[string]Key{
+		"maker_id-request_status-index":     {Hash: "maker_id", Range: "request_status"},
+		"checker_role-request_status-index": {Hash: "checker_role", Range: "request_status"},
+	}},
+	{Name: "roles", Param: "ROLES_TABLE", Key: Key{Hash: "role"}},
+	{Name: "logs", Param: "LOGS_TABLE", Key: Key{Hash: "log_id"}, TTL: "ttl"},
+	{Name: "sessions", Param: "SESSIONS_TABLE", Key: Key{Hash: "user_id", Range: "timestamp"}, Numbers: []string{"timestamp"},
+		TTL: "ttl"},
+	{Name: "import-jobs", Param: "IMPORT_JOBS_TABLE", Key: Key{Hash: "job_id"}, TTL: "ttl"},
+	{Name: "migrations", Param: "MIGRATIONS_TABLE", Key: Key{Hash: "version"}, Numbers: []string{"version"}},
+	{Name: "drifts", Param: "DRIFTS_TABLE", Key: Key{Hash: "run_id", Range: "page"}, Numbers: []string{"page"}, TTL: "ttl"},
+}
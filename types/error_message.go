@@ -1,28 +1,133 @@
 package types
 
+// Error is an error reported to api callers with the http Status to answer
+// and a machine readable Code the frontend can switch on.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches any error with the same code, so copies carrying details still
+// match the declared error with errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails returns a copy of e carrying details for the caller.
+func (e *Error) WithDetails(details interface{}) *Error {
+	copy := *e
+	copy.Details = details
+	return &copy
+}
+
+// Retryable reports whether the request may succeed if sent again.
+func (e *Error) Retryable() bool {
+	return e.Status == 503
+}
+
+// ErrorResponse is the body of every failed api request.
+type ErrorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id"`
+}
+
+// MissingParameter is ErrorMissingParameter naming the query parameter.
+func MissingParameter(name string) *Error {
+	return ErrorMissingParameter.WithDetails(map[string]string{"parameter": name})
+}
+
+func newError(status int, code string, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// malformed requests
+var (
+	ErrorInvalidUserData     = newError(400, "invalid_user_data", "invalid user data")
+	ErrorInvalidPointsID     = newError(400, "invalid_points_id", "invalid points id")
+	ErrorInvalidUserID       = newError(400, "invalid_user_id", "invalid user id")
+	ErrorInvalidResourceType = newError(400, "invalid_resource_type", "resource type is invalid")
+	ErrorInvalidCSV          = newError(400, "invalid_csv", "invalid csv")
+	ErrorMissingParameter    = newError(400, "missing_parameter", "missing required parameter")
+	ErrorInvalidBody         = newError(400, "invalid_body", "request body is not a json object")
+	ErrorBodyTooLarge        = newError(413, "body_too_large", "request body is too large")
+)
+
+// requests the caller may not make
+var (
+	ErrorNotAuthenticated       = newError(401, "not_authenticated", "caller is not authenticated")
+	ErrorForbidden              = newError(403, "forbidden", "request did not pass through the authorizer")
+	ErrorNotPermittedByPolicy   = newError(403, "not_permitted", "action not permitted by role policy")
+	ErrorInvalidPolicy          = newError(403, "invalid_policy", "invalid role policy")
+	ErrorAdminPasswordsDisabled = newError(403, "admin_passwords_disabled", "admin chosen passwords are disabled")
+)
+
+// missing records
+var (
+	ErrorUserDoesNotExist      = newError(404, "user_not_found", "target user does not exist")
+	ErrorPointsDoesNotExist    = newError(404, "points_not_found", "target points does not exist")
+	ErrorRoleDoesNotExist      = newError(404, "role_not_found", "role does not exist")
+	ErrorMakerReqDoesNotExist  = newError(404, "maker_request_not_found", "maker request id does not exist")
+	ErrorMakerDoesNotExist     = newError(404, "maker_not_found", "target maker_id does not exist")
+	ErrorReportDoesNotExist    = newError(404, "report_not_found", "reconciliation report does not exist")
+	ErrorImportJobDoesNotExist = newError(404, "import_job_not_found", "import job does not exist")
+	ErrorLogDoesNotExist       = newError(404, "log_not_found", "log does not exist")
+)
+
+// conflicts with the current state of a record
+var (
+	ErrorEmailAlreadyExists  = newError(409, "email_exists", "email already exists")
+	ErrorRoleInUse           = newError(409, "role_in_use", "role is still assigned to users")
+	ErrorPointsAccountClosed = newError(409, "points_account_closed", "points account is closed")
+	ErrorPointsAccountFrozen = newError(409, "points_account_frozen", "points account is frozen while its user is deleted")
+	ErrorPointsChanged       = newError(409, "points_changed", "points account no longer holds the expected points")
+	ErrorRecordChanged       = newError(409, "record_changed", "record changed while it was being written")
+	ErrorUserNotDeleted      = newError(409, "user_not_deleted", "user is not disabled or deleted")
+	ErrorUserAlreadyDeleted  = newError(409, "user_deleted", "user is already deleted")
+	ErrorRetentionExpired    = newError(410, "retention_expired", "user is past the restore retention window")
+)
+
+// well formed requests with invalid values
+var (
+	ErrorValidationFailed       = newError(422, "validation_failed", "request body failed validation")
+	ErrorInvalidRole            = newError(422, "invalid_role", "invalid role")
+	ErrorUnknownRole            = newError(422, "unknown_role", "assigned role does not exist")
+	ErrorInvalidEmail           = newError(422, "invalid_email", "invalid email")
+	ErrorInvalidFirstName       = newError(422, "invalid_first_name", "invalid first name")
+	ErrorInvalidLastName        = newError(422, "invalid_last_name", "invalid last name")
+	ErrorInvalidDecision        = newError(422, "invalid_decision", "invalid decision")
+	ErrorRoleInheritanceCycle   = newError(422, "role_inheritance_cycle", "role inheritance cycle")
+	ErrorParentRoleDoesNotExist = newError(422, "parent_role_not_found", "inherited role does not exist")
+	ErrorInvalidReassignRole    = newError(422, "invalid_reassign_role", "invalid reassign_to role")
+	ErrorImportTooLarge         = newError(422, "import_too_large", "import exceeds the maximum number of rows")
+	ErrorInvalidPassword        = newError(422, "invalid_password", "password does not meet the pool policy")
+	ErrorInvalidMFAPreference   = newError(422, "invalid_mfa_preference", "invalid mfa preference")
+)
+
+// failures of the backend, the request was valid
+var (
+	ErrorInternal                = newError(500, "internal", "internal error")
+	ErrorCouldNotMarshalItem     = newError(500, "marshal_failed", "could not marshal item")
+	ErrorFailedToUnmarshalRecord = newError(500, "unmarshal_failed", "failed to unmarshal record")
+	ErrorFailedToUnmarshal       = newError(500, "unmarshal_failed", "failed to unmarshal record from db")
+)
+
+// failures of aws, the same request may succeed later
 var (
-	ErrorCouldNotMarshalItem     = "could not marshal item"
-	ErrorInvalidMakerData        = "invalid maker data"
-	ErrorInvalidPointsID         = "invalid points id"
-	ErrorInvalidResourceType     = "resource type is invalid"
-	ErrorUserDoesNotExist        = "target user does not exist"
-	ErrorPointsDoesNotExist      = "target points does not exist"
-	ErrorFailedToUnmarshalRecord = "failed to unmarshal record"
-	ErrorFailedToFetchRecord     = "failed to fetch record"
-	ErrorFailedToFetchRecordID   = "failed to fetch record by uuid"
-	ErrorInvalidUserData         = "invalid user data"
-	ErrorCouldNotDynamoPutItem   = "could not dynamo put item"
-	ErrorInvalidRoleData         = "invalid role data"
-	ErrorInvalidRole             = "invalid role"
-	ErrorInvalidEmail            = "invalid email"
-	ErrorInvalidFirstName        = "invalid first name"
-	ErrorInvalidLastName         = "invalid last name"
-	ErrorRoleDoesNotExist        = "role does not exist"
-	ErrorCouldNotDeleteItem      = "could not delete item"
-	ErrorFailedToUnmarshal       = "failed to unmarshal record from db"
-	ErrorCouldNotQueryDB         = "could not query db"
-	ErrorMakerReqDoesNotExist  = "maker request id does not exist"
-	ErrorInvalidUserID           = "invalid user id"
-	ErrorInvalidDecision         = "invalid decision"
-	ErrorMakerDoesNotExist       = "target maker_id does not exist"
+	ErrorFailedToFetchRecord   = newError(503, "fetch_failed", "failed to fetch record")
+	ErrorFailedToFetchRecordID = newError(503, "fetch_failed", "failed to fetch record by uuid")
+	ErrorCouldNotDynamoPutItem = newError(503, "write_failed", "could not dynamo put item")
+	ErrorCouldNotDeleteItem    = newError(503, "delete_failed", "could not delete item")
+	ErrorCouldNotQueryDB       = newError(503, "query_failed", "could not query db")
+	ErrorCognitoActionFailed   = newError(503, "cognito_failed", "cognito action failed")
+	ErrorLoadingConfig         = newError(503, "config_unavailable", "could not load configuration")
+	ErrorServiceUnavailable    = newError(503, "service_unavailable", "service temporarily unavailable")
 )
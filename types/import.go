@@ -0,0 +1,46 @@
+package types
+
+// Import job statuses.
+const (
+	ImportJobPending   = "pending"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+)
+
+// Import row statuses. Only pending rows are provisioned, the others are final.
+const (
+	ImportRowPending   = "pending"
+	ImportRowCreated   = "created"
+	ImportRowInvalid   = "invalid"
+	ImportRowDuplicate = "duplicate"
+	ImportRowFailed    = "failed"
+)
+
+// ImportRow is one line of an imported CSV and the outcome of provisioning it.
+type ImportRow struct {
+	Line      int    `json:"line"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	User_ID   string `json:"user_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ImportJob tracks a bulk user import from upload until every row is final.
+type ImportJob struct {
+	Job_ID    string         `json:"job_id"`
+	Status    string         `json:"status"`
+	Requester string         `json:"requester"`
+	CreatedAt int64          `json:"created_at"`
+	UpdatedAt int64          `json:"updated_at"`
+	Summary   map[string]int `json:"summary"`
+	Rows      []ImportRow    `json:"rows,omitempty"`
+	TTL       int64          `json:"ttl"`
+}
+
+// ImportJobMessage is queued to have the pending rows of a job provisioned.
+type ImportJobMessage struct {
+	Job_ID string `json:"job_id"`
+}
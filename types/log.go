@@ -1,12 +1,13 @@
 package types
 
 type Log struct {
-	Log_ID      string `json:"log_id"`
-	IP          string `json:"ip"`
-	Description string `json:"description"`
-	UserAgent   string `json:"user_agent"`
-	Timestamp   int64  `json:"timestamp"`
-	TTL         int64  `json:"ttl"`
+	Log_ID      string       `json:"log_id"`
+	IP          string       `json:"ip"`
+	Description string       `json:"description"`
+	UserAgent   string       `json:"user_agent"`
+	Timestamp   int64        `json:"timestamp"`
+	TTL         int64        `json:"ttl"`
+	Report      *DriftReport `json:"report,omitempty"`
 }
 
 type ReturnLogData struct {
@@ -0,0 +1,54 @@
+package types
+
+// Migration statuses recorded in the migrations table. A migration left
+// running failed or was interrupted and resumes on the next run.
+const (
+	MigrationRunning = "running"
+	MigrationApplied = "applied"
+)
+
+// MigrationRequest is the input of the migration job.
+type MigrationRequest struct {
+	// DryRun scans the tables and counts the items that would change without
+	// writing them or recording the migrations.
+	DryRun bool `json:"dry_run"`
+	// Target is the last version to apply, every pending one when zero.
+	Target int `json:"target,omitempty"`
+	// Segments is how many parallel segments each table is scanned in.
+	Segments int `json:"segments,omitempty"`
+}
+
+// MigrationRecord is the item of an applied or running migration in the
+// migrations table. Running migrations also keep the key each segment of the
+// scan continues from.
+type MigrationRecord struct {
+	Version       int    `json:"version"`
+	Description   string `json:"description"`
+	Status        string `json:"status"`
+	TotalSegments int    `json:"total_segments"`
+	Scanned       int    `json:"scanned"`
+	Updated       int    `json:"updated"`
+	StartedAt     int64  `json:"started_at"`
+	AppliedAt     int64  `json:"applied_at,omitempty"`
+}
+
+// MigrationResult is what one run did for one migration.
+type MigrationResult struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Scanned     int    `json:"scanned"`
+	Updated     int    `json:"updated"`
+	// Skipped counts the items that changed or were deleted between being
+	// scanned and updated.
+	Skipped int  `json:"skipped"`
+	Resumed bool `json:"resumed,omitempty"`
+	Applied bool `json:"applied"`
+}
+
+// MigrationReport lists the migrations one run applied, or would apply in a
+// dry run, in version order.
+type MigrationReport struct {
+	DryRun         bool              `json:"dry_run"`
+	AlreadyApplied []int             `json:"already_applied"`
+	Migrations     []MigrationResult `json:"migrations"`
+}
@@ -0,0 +1,57 @@
+package types
+
+// ReconciliationItem records a saga that failed and could not fully undo the
+// steps it had already applied, leaving DynamoDB and Cognito out of sync.
+type ReconciliationItem struct {
+	Saga          string   `json:"saga"`
+	ResourceID    string   `json:"resource_id"`
+	FailedStep    string   `json:"failed_step"`
+	Reason        string   `json:"reason"`
+	Uncompensated []string `json:"uncompensated"`
+	Timestamp     int64    `json:"timestamp"`
+}
+
+// Drift categories reported by the reconciliation job.
+const (
+	DriftMissingInCognito = "missing_in_cognito"
+	DriftMissingInDynamo  = "missing_in_dynamo"
+	DriftRoleMismatch     = "role_mismatch"
+	DriftEmailMismatch    = "email_mismatch"
+	DriftStatusMismatch   = "status_mismatch"
+)
+
+// ReconciliationRequest is the input of the scheduled reconciliation job.
+type ReconciliationRequest struct {
+	AutoFix bool `json:"auto_fix"`
+}
+
+// Drift is a single difference between a user's DynamoDB item and Cognito user.
+type Drift struct {
+	UserID   string `json:"user_id"`
+	Category string `json:"category"`
+	Dynamo   string `json:"dynamo,omitempty"`
+	Cognito  string `json:"cognito,omitempty"`
+	Fixed    bool   `json:"fixed"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DriftReport groups the drift found by one reconciliation run by category.
+// The logs table only keeps the counts; the drifts are stored in pages under
+// the run id.
+type DriftReport struct {
+	Run_ID       string             `json:"run_id"`
+	GeneratedAt  int64              `json:"generated_at"`
+	AutoFix      bool               `json:"auto_fix"`
+	DynamoUsers  int                `json:"dynamo_users"`
+	CognitoUsers int                `json:"cognito_users"`
+	Counts       map[string]int     `json:"counts"`
+	Drifts       map[string][]Drift `json:"drifts"`
+}
+
+// DriftPage is one page of the drifts found by a reconciliation run.
+type DriftPage struct {
+	Run_ID string  `json:"run_id"`
+	Page   int     `json:"page"`
+	Drifts []Drift `json:"drifts"`
+	TTL    int64   `json:"ttl"`
+}
@@ -1,11 +1,33 @@
 package types
 
 type Role struct {
-	Role   string              `json:"role"`
-	Access map[string][]string `json:"access"`
+	Role     string              `json:"role"`
+	Inherits []string            `json:"inherits,omitempty"`
+	Access   map[string][]string `json:"access"`
+	Deny     map[string][]string `json:"deny,omitempty"`
+	Policy   Policy              `json:"policy"`
+}
+
+// Policy holds the conditions handlers evaluate on top of route access. An empty
+// field places no restriction on the corresponding action. The lists keep null
+// apart from [], which a role inheriting a restriction sets to lift it.
+type Policy struct {
+	// UserFields lists the user attributes the role may change through update-users.
+	UserFields []string `json:"user_fields"`
+	// MaxPointsChange caps the absolute difference of a single points update.
+	MaxPointsChange int `json:"max_points_change,omitempty"`
+	// TargetRoles lists the roles of users the role may view, update or delete.
+	TargetRoles []string `json:"target_roles"`
 }
 
 type ReturnRoleData struct {
 	Data []Role `json:"data"`
 	Key  string `json:"key"`
 }
+
+// RoleInUseData lists the users and the roles inheriting from it that block
+// the deletion of a role.
+type RoleInUseData struct {
+	Users []User   `json:"users"`
+	Roles []string `json:"roles,omitempty"`
+}
@@ -0,0 +1,39 @@
+package types
+
+const (
+	SignInAttempt = "attempt"
+	SignInSuccess = "success"
+)
+
+const (
+	SignInFlagNewIP            = "new_ip"
+	SignInFlagNewDevice        = "new_device"
+	SignInFlagRepeatedFailures = "repeated_failures"
+)
+
+// SignInEvent is recorded by the cognito authentication triggers. Attempts are
+// written before the password is checked, so attempts without a following
+// success are failed sign ins. Timestamp is in unix milliseconds. IP is the
+// address cognito saw, Client what the client reported about itself.
+type SignInEvent struct {
+	User_ID   string        `json:"user_id"`
+	Timestamp int64         `json:"timestamp"`
+	Outcome   string        `json:"outcome"`
+	IP        string        `json:"ip,omitempty"`
+	Client    *ClientReport `json:"client,omitempty"`
+	Flags     []string      `json:"flags,omitempty"`
+	TTL       int64         `json:"ttl"`
+}
+
+// ClientReport is what a client sends about itself with a sign in. It can be
+// spoofed, so it is kept for investigations and never used for flags.
+type ClientReport struct {
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Country   string `json:"country,omitempty"`
+}
+
+type ReturnSignInData struct {
+	Data []SignInEvent `json:"data"`
+	Key  string        `json:"key"`
+}
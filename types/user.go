@@ -1,11 +1,54 @@
 package types
 
+// User statuses. Users stored before statuses existed have none and are active.
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+	UserStatusDeleted  = "deleted"
+)
+
 type User struct {
 	Email     string `json:"email"`
 	User_ID   string `json:"user_id"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Role      string `json:"role"`
+	Status    string `json:"status,omitempty"`
+	DeletedAt int64  `json:"deleted_at,omitempty"`
+}
+
+// UserPatch holds the fields of a partial user update, nil fields are left as
+// they are.
+type UserPatch struct {
+	Email     *string `json:"email,omitempty"`
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+	Role      *string `json:"role,omitempty"`
+}
+
+// ProfilePatch holds the fields users may change on their own profile.
+type ProfilePatch struct {
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+}
+
+// TemporaryPassword is the password an admin sets for a user, who must change
+// it at their next sign in.
+type TemporaryPassword struct {
+	Password string `json:"password"`
+}
+
+const (
+	MFAFactorSMS           = "SMS"
+	MFAFactorSoftwareToken = "SOFTWARE_TOKEN"
+)
+
+// MFAPreference holds the mfa settings of a user, nil factors are left as
+// they are. Preferred is one of the MFAFactor values or empty for no preference.
+type MFAPreference struct {
+	SMS           *bool  `json:"sms,omitempty"`
+	SoftwareToken *bool  `json:"software_token,omitempty"`
+	Preferred     string `json:"preferred,omitempty"`
 }
 
 type CognitoUser struct {
@@ -1,9 +1,26 @@
 package types
 
+// Points account statuses. Accounts without a status are active. Accounts of
+// soft deleted users are frozen until the user is restored or purged.
+const (
+	PointsStatusActive = "active"
+	PointsStatusFrozen = "frozen"
+	PointsStatusClosed = "closed"
+)
+
 type UserPoint struct {
 	User_ID   string `json:"user_id"`
 	Points_ID string `json:"points_id"`
 	Points    int    `json:"points"`
+	Status    string `json:"status,omitempty"`
+}
+
+// PointsUpdate sets the balance of a points account. When ExpectedPoints is
+// set the update only applies while the account still holds that many points.
+type PointsUpdate struct {
+	Points_ID      string `json:"points_id"`
+	Points         int    `json:"points"`
+	ExpectedPoints *int   `json:"expected_points,omitempty"`
 }
 
 type ReturnUserPointData struct {
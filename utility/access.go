@@ -0,0 +1,66 @@
+package utility
+
+import (
+	"ascenda/types"
+	"strings"
+)
+
+const wildcard = "*"
+
+// IsAccessAllowed reports whether the role grants method on route. Route keys in
+// the role's access and deny maps may be glob patterns where "*" matches any
+// sequence of characters, and "*" in a method list matches every method. A
+// matching deny entry always overrides a matching allow entry.
+func IsAccessAllowed(role *types.Role, route, method string) bool {
+	if role == nil {
+		return false
+	}
+	if matchAccess(role.Deny, route, method) {
+		return false
+	}
+	return matchAccess(role.Access, route, method)
+}
+
+func matchAccess(access map[string][]string, route, method string) bool {
+	for pattern, methods := range access {
+		if MatchRoute(pattern, route) && matchMethod(methods, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == wildcard || strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRoute reports whether route matches the glob pattern. Unlike path.Match,
+// "*" also matches across "/" so that "/users*" covers "/users/sessions".
+func MatchRoute(pattern, route string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == '*' {
+			//collapse consecutive wildcards
+			pattern = strings.TrimLeft(pattern, wildcard)
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(route); i++ {
+				if MatchRoute(pattern, route[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(route) == 0 || pattern[0] != route[0] {
+			return false
+		}
+		pattern = pattern[1:]
+		route = route[1:]
+	}
+	return len(route) == 0
+}
@@ -0,0 +1,86 @@
+package utility
+
+import (
+	"ascenda/types"
+	"testing"
+)
+
+func TestMatchRoute(t *testing.T) {
+	tests := []struct {
+		pattern string
+		route   string
+		want    bool
+	}{
+		{"/users", "/users", true},
+		{"/users", "/users/sessions", false},
+		{"/users", "/user", false},
+		{"/users*", "/users", true},
+		{"/users*", "/users/sessions", true},
+		{"/users*", "/points", false},
+		{"*", "/logs", true},
+		{"*", "/", true},
+		{"/*/sessions", "/users/sessions", true},
+		{"/*/sessions", "/users/mfa", false},
+		{"/users/**", "/users/a/b", true},
+		{"", "", true},
+		{"", "/users", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchRoute(tt.pattern, tt.route); got != tt.want {
+			t.Errorf("MatchRoute(%q, %q) = %v, want %v", tt.pattern, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestIsAccessAllowed(t *testing.T) {
+	admin := &types.Role{
+		Role:   "admin",
+		Access: map[string][]string{"*": {"*"}},
+		Deny:   map[string][]string{"/roles": {"DELETE"}},
+	}
+	support := &types.Role{
+		Role: "support",
+		Access: map[string][]string{
+			"/users*": {"GET", "PUT"},
+			"/points": {"GET"},
+		},
+		Deny: map[string][]string{"/users/sessions": {"*"}},
+	}
+	exact := &types.Role{
+		Role:   "viewer",
+		Access: map[string][]string{"/logs": {"GET"}},
+	}
+
+	tests := []struct {
+		name   string
+		role   *types.Role
+		route  string
+		method string
+		want   bool
+	}{
+		{"wildcard route and method", admin, "/users", "POST", true},
+		{"wildcard allows nested route", admin, "/users/sessions", "GET", true},
+		{"deny overrides wildcard allow", admin, "/roles", "DELETE", false},
+		{"deny is method specific", admin, "/roles", "GET", true},
+		{"glob prefix allows base route", support, "/users", "GET", true},
+		{"glob prefix allows nested route", support, "/users/mfa", "PUT", true},
+		{"glob prefix keeps method list", support, "/users", "DELETE", false},
+		{"deny with method wildcard", support, "/users/sessions", "GET", false},
+		{"exact route", support, "/points", "GET", true},
+		{"exact route wrong method", support, "/points", "PUT", false},
+		{"route not listed", support, "/logs", "GET", false},
+		{"method is case insensitive", exact, "/logs", "get", true},
+		{"exact route does not match prefix", exact, "/logs/export", "GET", false},
+		{"nil role", nil, "/logs", "GET", false},
+		{"empty role", &types.Role{}, "/logs", "GET", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAccessAllowed(tt.role, tt.route, tt.method); got != tt.want {
+				t.Errorf("IsAccessAllowed(%s, %q, %q) = %v, want %v", tt.name, tt.route, tt.method, got, tt.want)
+			}
+		})
+	}
+}
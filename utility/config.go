@@ -0,0 +1,204 @@
+package utility
+
+import (
+	"ascenda/types"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+const (
+	ParamUserTable              = "USER_TABLE"
+	ParamEmailsTable            = "EMAILS_TABLE"
+	ParamPointsTable            = "POINTS_TABLE"
+	ParamMakerTable             = "MAKER_TABLE"
+	ParamRolesTable             = "ROLES_TABLE"
+	ParamLogsTable              = "LOGS_TABLE"
+	ParamSessionsTable          = "SESSIONS_TABLE"
+	ParamImportJobsTable        = "IMPORT_JOBS_TABLE"
+	ParamMigrationsTable        = "MIGRATIONS_TABLE"
+	ParamDriftsTable            = "DRIFTS_TABLE"
+	ParamTTL                    = "TTL"
+	ParamUserPoolID             = "USER_POOL_ID"
+	ParamReconciliationQueueURL = "RECONCILIATION_QUEUE_URL"
+	ParamImportQueueURL         = "IMPORT_QUEUE_URL"
+	ParamImportRate             = "IMPORT_RATE"
+	ParamRetentionDays          = "RETENTION_DAYS"
+	ParamAllowAdminPasswords    = "ALLOW_ADMIN_PASSWORDS"
+	ParamSessionTTL             = "SESSION_TTL"
+	ParamSignInFailureThreshold = "SIGN_IN_FAILURE_THRESHOLD"
+)
+
+// ConfigRefreshInterval is how long loaded parameters are served from the
+// cache before they are fetched again.
+const ConfigRefreshInterval = 5 * time.Minute
+
+// ssmBatchSize is the most names GetParameters accepts at once.
+const ssmBatchSize = 10
+
+// Config holds the parameters of the deployment. Fields are only set for the
+// parameters a handler asked for.
+type Config struct {
+	UserTable              string `param:"USER_TABLE"`
+	EmailsTable            string `param:"EMAILS_TABLE"`
+	PointsTable            string `param:"POINTS_TABLE"`
+	MakerTable             string `param:"MAKER_TABLE"`
+	RolesTable             string `param:"ROLES_TABLE"`
+	LogsTable              string `param:"LOGS_TABLE"`
+	SessionsTable          string `param:"SESSIONS_TABLE"`
+	ImportJobsTable        string `param:"IMPORT_JOBS_TABLE"`
+	MigrationsTable        string `param:"MIGRATIONS_TABLE"`
+	DriftsTable            string `param:"DRIFTS_TABLE"`
+	TTL                    string `param:"TTL"`
+	UserPoolID             string `param:"USER_POOL_ID"`
+	ReconciliationQueueURL string `param:"RECONCILIATION_QUEUE_URL"`
+	ImportQueueURL         string `param:"IMPORT_QUEUE_URL"`
+	ImportRate             int    `param:"IMPORT_RATE"`
+	RetentionDays          int    `param:"RETENTION_DAYS"`
+	AllowAdminPasswords    bool   `param:"ALLOW_ADMIN_PASSWORDS"`
+	SessionTTL             int    `param:"SESSION_TTL"`
+	SignInFailureThreshold int    `param:"SIGN_IN_FAILURE_THRESHOLD"`
+}
+
+// Value returns the parameter tagged name formatted as a string, such as the
+// table name of USER_TABLE.
+func (c *Config) Value(name string) string {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("param") == name {
+			return fmt.Sprint(v.Field(i).Interface())
+		}
+	}
+	return ""
+}
+
+type cachedParameter struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// ConfigLoader fetches parameters from SSM in batches and caches them for
+// Refresh. Environment variables named like a parameter take precedence and
+// are never fetched, so local runs can set every parameter without SSM.
+type ConfigLoader struct {
+	Client  ssmiface.SSMAPI
+	Refresh time.Duration
+	Now     func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedParameter
+}
+
+func NewConfigLoader(client ssmiface.SSMAPI, refresh time.Duration) *ConfigLoader {
+	return &ConfigLoader{
+		Client:  client,
+		Refresh: refresh,
+		Now:     time.Now,
+		cache:   map[string]cachedParameter{},
+	}
+}
+
+// Load returns a Config with the named parameters set, fetching those missing
+// from the cache or older than Refresh.
+func (l *ConfigLoader) Load(names ...string) (*Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	values := map[string]string{}
+	var stale []string
+	now := l.Now()
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			values[name] = value
+			continue
+		}
+		cached, ok := l.cache[name]
+		if ok && now.Sub(cached.fetchedAt) < l.Refresh {
+			values[name] = cached.value
+			continue
+		}
+		stale = append(stale, name)
+	}
+
+	if len(stale) > 0 {
+		fetched, err := l.fetch(stale)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fetched {
+			l.cache[name] = cachedParameter{value: value, fetchedAt: now}
+			values[name] = value
+		}
+	}
+
+	return buildConfig(values)
+}
+
+func (l *ConfigLoader) fetch(names []string) (map[string]string, error) {
+	values := map[string]string{}
+	var missing []string
+	for start := 0; start < len(names); start += ssmBatchSize {
+		end := start + ssmBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		output, err := l.Client.GetParameters(&ssm.GetParametersInput{
+			Names:          aws.StringSlice(names[start:end]),
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", types.ErrorLoadingConfig, err)
+		}
+		for _, parameter := range output.Parameters {
+			values[*parameter.Name] = *parameter.Value
+		}
+		missing = append(missing, aws.StringValueSlice(output.InvalidParameters)...)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: missing %s", types.ErrorLoadingConfig, strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// buildConfig sets the field tagged with each parameter name, parsing ints
+// and bools.
+func buildConfig(values map[string]string) (*Config, error) {
+	config := &Config{}
+	v := reflect.ValueOf(config).Elem()
+	fields := map[string]reflect.Value{}
+	for i := 0; i < v.NumField(); i++ {
+		fields[v.Type().Field(i).Tag.Get("param")] = v.Field(i)
+	}
+
+	for name, value := range values {
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown parameter %s", types.ErrorLoadingConfig, name)
+		}
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s is not a number", types.ErrorLoadingConfig, name)
+			}
+			field.SetInt(int64(n))
+		case reflect.Bool:
+			field.SetBool(value == "true")
+		default:
+			return nil, types.ErrorLoadingConfig
+		}
+	}
+	return config, nil
+}
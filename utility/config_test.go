@@ -0,0 +1,97 @@
+package utility
+
+import (
+	"ascenda/schema"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+type fakeSSM struct {
+	ssmiface.SSMAPI
+	values map[string]string
+	calls  int
+}
+
+func (f *fakeSSM) GetParameters(input *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	f.calls++
+	if len(input.Names) > ssmBatchSize {
+		return nil, fmt.Errorf("%d names in one batch", len(input.Names))
+	}
+	output := &ssm.GetParametersOutput{}
+	for _, name := range aws.StringValueSlice(input.Names) {
+		if value, ok := f.values[name]; ok {
+			output.Parameters = append(output.Parameters, &ssm.Parameter{Name: aws.String(name), Value: aws.String(value)})
+		} else {
+			output.InvalidParameters = append(output.InvalidParameters, aws.String(name))
+		}
+	}
+	return output, nil
+}
+
+func TestConfigLoader(t *testing.T) {
+	all := []string{ParamUserTable, ParamEmailsTable, ParamPointsTable, ParamMakerTable, ParamRolesTable, ParamLogsTable,
+		ParamTTL, ParamUserPoolID, ParamReconciliationQueueURL, ParamImportRate, ParamAllowAdminPasswords}
+	client := &fakeSSM{values: map[string]string{}}
+	for _, name := range all {
+		client.values[name] = "value"
+	}
+	client.values[ParamImportRate] = "5"
+	client.values[ParamAllowAdminPasswords] = "true"
+
+	now := time.Unix(0, 0)
+	loader := NewConfigLoader(client, time.Minute)
+	loader.Now = func() time.Time { return now }
+
+	config, err := loader.Load(all...)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if config.UserTable != "value" || config.ImportRate != 5 || !config.AllowAdminPasswords {
+		t.Errorf("Load() = %+v", config)
+	}
+	if client.calls != 2 {
+		t.Errorf("GetParameters calls = %d, want 2 batches", client.calls)
+	}
+
+	if _, err := loader.Load(ParamUserTable); err != nil || client.calls != 2 {
+		t.Errorf("cached Load() error = %v, calls = %d, want no fetch", err, client.calls)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := loader.Load(ParamUserTable); err != nil || client.calls != 3 {
+		t.Errorf("expired Load() error = %v, calls = %d, want a fetch", err, client.calls)
+	}
+
+	t.Setenv(ParamLogsTable, "local-logs")
+	config, err = loader.Load(ParamLogsTable)
+	if err != nil || config.LogsTable != "local-logs" {
+		t.Errorf("Load() with env = %+v, %v, want local-logs", config, err)
+	}
+
+	if _, err := loader.Load(ParamSessionsTable); err == nil {
+		t.Error("Load() of a missing parameter succeeded")
+	}
+
+	client.values[ParamRetentionDays] = "thirty"
+	if _, err := loader.Load(ParamRetentionDays); err == nil {
+		t.Error("Load() of a non numeric parameter succeeded")
+	}
+}
+
+func TestSchemaTablesHaveParameters(t *testing.T) {
+	for _, table := range schema.Tables {
+		config, err := buildConfig(map[string]string{table.Param: table.Name})
+		if err != nil {
+			t.Errorf("table %s: %v", table.Name, err)
+			continue
+		}
+		if got := config.Value(table.Param); got != table.Name {
+			t.Errorf("Value(%s) = %q, want %q", table.Param, got, table.Name)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package utility
+
+import (
+	"ascenda/types"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// EmailKey normalises an email for uniqueness checks, emails are compared
+// case insensitively.
+func EmailKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// PutUserItem writes the user to the users table together with the guard item
+// in the emails table that reserves the user's email. previous is the stored
+// user being replaced, nil when the user is new. The write fails with
+// ErrorEmailAlreadyExists when another user holds the email, and with
+// ErrorRecordChanged when the user or their email guard changed meanwhile.
+func PutUserItem(user types.User, previous *types.User, tableName, emailsTable string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	av, err := dynamodbattribute.MarshalMap(user)
+	if err != nil {
+		return types.ErrorCouldNotMarshalItem
+	}
+
+	put := &dynamodb.Put{
+		Item:      av,
+		TableName: aws.String(tableName),
+	}
+	if previous == nil {
+		put.ConditionExpression = aws.String("attribute_not_exists(user_id)")
+	}
+	items := []*dynamodb.TransactWriteItem{{Put: put}}
+
+	if previous == nil {
+		items = append(items, &dynamodb.TransactWriteItem{Put: reserveEmail(user, emailsTable)})
+	} else {
+		guards, err := moveEmail(*previous, user, emailsTable, dynaClient)
+		if err != nil {
+			return err
+		}
+		items = append(items, guards...)
+	}
+
+	_, err = dynaClient.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		if isEmailConflict(err) {
+			return types.ErrorEmailAlreadyExists
+		}
+		if isConditionFailed(err) {
+			return types.ErrorRecordChanged
+		}
+		return types.ErrorCouldNotDynamoPutItem
+	}
+	return nil
+}
+
+// DeleteUserItem removes the user from the users table and releases the email.
+func DeleteUserItem(user types.User, tableName, emailsTable string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	items := []*dynamodb.TransactWriteItem{{Delete: &dynamodb.Delete{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {S: aws.String(user.User_ID)},
+		},
+		TableName: aws.String(tableName),
+	}}}
+
+	release, err := releaseEmail(user, emailsTable, dynaClient)
+	if err != nil {
+		return err
+	}
+	if release != nil {
+		items = append(items, &dynamodb.TransactWriteItem{Delete: release})
+	}
+
+	_, err = dynaClient.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if isConditionFailed(err) {
+		return types.ErrorRecordChanged
+	}
+	if err != nil {
+		return types.ErrorCouldNotDeleteItem
+	}
+	return nil
+}
+
+// reserveEmail returns the guard item reserving the user's email, failing if
+// another user holds it.
+func reserveEmail(user types.User, emailsTable string) *dynamodb.Put {
+	return &dynamodb.Put{
+		Item: map[string]*dynamodb.AttributeValue{
+			"email":   {S: aws.String(EmailKey(user.Email))},
+			"user_id": {S: aws.String(user.User_ID)},
+		},
+		TableName:                 aws.String(emailsTable),
+		ConditionExpression:       aws.String("attribute_not_exists(email) OR user_id = :user_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":user_id": {S: aws.String(user.User_ID)}},
+	}
+}
+
+// moveEmail returns the writes reserving the updated email and releasing the
+// current one, none when the email is unchanged. The reservation comes first
+// so it is the second item of the transaction.
+func moveEmail(current, updated types.User, emailsTable string, dynaClient dynamodbiface.DynamoDBAPI) ([]*dynamodb.TransactWriteItem, error) {
+	if EmailKey(current.Email) == EmailKey(updated.Email) {
+		return nil, nil
+	}
+
+	items := []*dynamodb.TransactWriteItem{{Put: reserveEmail(updated, emailsTable)}}
+	release, err := releaseEmail(current, emailsTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if release != nil {
+		items = append(items, &dynamodb.TransactWriteItem{Delete: release})
+	}
+	return items, nil
+}
+
+// releaseEmail returns the deletion of the user's guard item, or nil when the
+// user holds none, as for users stored before guards existed or duplicates
+// whose email is reserved by another user.
+func releaseEmail(user types.User, emailsTable string, dynaClient dynamodbiface.DynamoDBAPI) (*dynamodb.Delete, error) {
+	key := map[string]*dynamodb.AttributeValue{
+		"email": {S: aws.String(EmailKey(user.Email))},
+	}
+
+	result, err := dynaClient.GetItem(&dynamodb.GetItemInput{
+		Key:       key,
+		TableName: aws.String(emailsTable),
+	})
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecord
+	}
+	if result.Item == nil || result.Item["user_id"] == nil || aws.StringValue(result.Item["user_id"].S) != user.User_ID {
+		return nil, nil
+	}
+
+	return &dynamodb.Delete{
+		Key:                       key,
+		TableName:                 aws.String(emailsTable),
+		ConditionExpression:       aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":user_id": {S: aws.String(user.User_ID)}},
+	}, nil
+}
+
+// isEmailConflict reports whether the transaction was cancelled by the email
+// guard condition, the guard being the second item written.
+func isEmailConflict(err error) bool {
+	cancelled, ok := err.(*dynamodb.TransactionCanceledException)
+	if !ok {
+		return false
+	}
+	reasons := cancelled.CancellationReasons
+	return len(reasons) > 1 && aws.StringValue(reasons[1].Code) == "ConditionalCheckFailed"
+}
+
+// isConditionFailed reports whether the write, or an item of the transaction,
+// failed its condition because the item changed since it was read.
+func isConditionFailed(err error) bool {
+	var failed *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &failed) {
+		return true
+	}
+	var cancelled *dynamodb.TransactionCanceledException
+	if !errors.As(err, &cancelled) {
+		return false
+	}
+	for _, reason := range cancelled.CancellationReasons {
+		if aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
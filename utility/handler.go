@@ -0,0 +1,218 @@
+package utility
+
+import (
+	"ascenda/types"
+	"ascenda/validation"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"runtime/debug"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsrequest "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// ConfigSource loads the named parameters, ConfigLoader in lambda and
+// StaticConfig in tests.
+type ConfigSource interface {
+	Load(names ...string) (*Config, error)
+}
+
+// StaticConfig serves the same config for every name.
+type StaticConfig Config
+
+func (c StaticConfig) Load(names ...string) (*Config, error) {
+	config := Config(c)
+	return &config, nil
+}
+
+// Deps holds the clients and configuration a function shares across its
+// invocations. Handlers take everything from Deps so tests can pass fakes.
+type Deps struct {
+	Dynamo  dynamodbiface.DynamoDBAPI
+	Cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	SES     sesiface.SESAPI
+	SQS     sqsiface.SQSAPI
+	Config  ConfigSource
+}
+
+// NewDeps builds the aws clients once per container. DYNAMODB_ENDPOINT points
+// the dynamo client elsewhere, such as at DynamoDB Local.
+func NewDeps() (*Deps, error) {
+	awsSession, err := session.NewSession(&aws.Config{
+		Region: aws.String(os.Getenv("AWS_REGION"))})
+	if err != nil {
+		return nil, err
+	}
+
+	dynamoConfig := aws.NewConfig()
+	if endpoint := os.Getenv("DYNAMODB_ENDPOINT"); endpoint != "" {
+		dynamoConfig = dynamoConfig.WithEndpoint(endpoint)
+	}
+
+	return &Deps{
+		Dynamo:  dynamodb.New(awsSession, dynamoConfig),
+		Cognito: cognitoidentityprovider.New(awsSession),
+		SES:     ses.New(awsSession),
+		SQS:     sqs.New(awsSession),
+		Config:  NewConfigLoader(ssm.New(awsSession), ConfigRefreshInterval),
+	}, nil
+}
+
+// MustDeps is NewDeps for init, where a function cannot start without its
+// clients.
+func MustDeps() *Deps {
+	deps, err := NewDeps()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return deps
+}
+
+// Reconciler returns a reconciler sending to the queue at queueURL.
+func (d *Deps) Reconciler(queueURL string) Reconciler {
+	return &SQSReconciler{
+		Client:   d.SQS,
+		QueueURL: queueURL,
+	}
+}
+
+// APIHandler handles an api gateway request with the function's Deps.
+type APIHandler func(deps *Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// API adapts h for lambda.Start, answering with an error response instead of
+// failing the invocation when h returns an error or panics.
+func API(deps *Deps, h APIHandler) func(events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(request events.APIGatewayProxyRequest) (response events.APIGatewayProxyResponse, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic: %v\n%s", r, debug.Stack())
+				response, err = Error(request, types.ErrorInternal), nil
+			}
+		}()
+		response, err = h(deps, request)
+		if err != nil {
+			return Error(request, err), nil
+		}
+		return response, nil
+	}
+}
+
+// Invoke adapts h for lambda.Start for events other than api requests,
+// failing the invocation instead of crashing when h panics.
+func Invoke[E, R any](deps *Deps, h func(deps *Deps, event E) (R, error)) func(E) (R, error) {
+	return func(event E) (response R, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic: %v\n%s", r, debug.Stack())
+				var zero R
+				response, err = zero, types.ErrorInternal
+			}
+		}()
+		return h(deps, event)
+	}
+}
+
+// Event is Invoke for events that only return an error, such as sqs batches
+// and schedules.
+func Event[E any](deps *Deps, h func(deps *Deps, event E) error) func(E) error {
+	invoke := Invoke(deps, func(deps *Deps, event E) (struct{}, error) {
+		return struct{}{}, h(deps, event)
+	})
+	return func(event E) error {
+		_, err := invoke(event)
+		return err
+	}
+}
+
+// Text is a plain text response.
+func Text(status int, body string) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       body,
+	}
+}
+
+// JSON is a response with body marshalled as json.
+func JSON(status int, body interface{}) events.APIGatewayProxyResponse {
+	data, err := json.Marshal(body)
+	if err != nil {
+		log.Println(err)
+		return Text(500, `{"code":"internal","message":"internal error"}`)
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(data),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+	}
+}
+
+// Error is the json error response for err. Errors other than *types.Error
+// are logged and answered as internal errors so their cause is not leaked,
+// or as unavailable when aws says the call can be retried.
+func Error(request events.APIGatewayProxyRequest, err error) events.APIGatewayProxyResponse {
+	var apiErr *types.Error
+	var awsErr awserr.Error
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErr.Status >= 500 {
+			log.Println(err)
+		}
+	case errors.As(err, &awsErr) && (awsrequest.IsErrorRetryable(awsErr) || awsrequest.IsErrorThrottle(awsErr)):
+		log.Println(err)
+		apiErr = types.ErrorServiceUnavailable
+	default:
+		log.Println(err)
+		apiErr = types.ErrorInternal
+	}
+	return JSON(apiErr.Status, types.ErrorResponse{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Details:   apiErr.Details,
+		RequestID: request.RequestContext.RequestID,
+	})
+}
+
+// RequestBody returns the body of the request, decoding base64 bodies.
+func RequestBody(request events.APIGatewayProxyRequest) (string, error) {
+	if !request.IsBase64Encoded {
+		return request.Body, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(request.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// Decode checks the json body of the request against rules, failing as
+// validation.Check does, and unmarshals it into a T.
+func Decode[T any](request events.APIGatewayProxyRequest, rules validation.Rules) (T, error) {
+	var v T
+	body, err := RequestBody(request)
+	if err != nil {
+		return v, types.ErrorInvalidBody
+	}
+	if err := validation.Check([]byte(body), rules); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		log.Println(err)
+		return v, types.ErrorInvalidBody
+	}
+	return v, nil
+}
@@ -0,0 +1,88 @@
+package utility
+
+import (
+	"ascenda/types"
+	"ascenda/validation"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+func TestAPIRecoversFromPanic(t *testing.T) {
+	h := API(&Deps{}, func(deps *Deps, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		panic("boom")
+	})
+
+	res, err := h(events.APIGatewayProxyRequest{})
+	if err != nil || res.StatusCode != 500 {
+		t.Errorf("API() = %d, %v, want 500 and no error", res.StatusCode, err)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"typed", types.ErrorUserDoesNotExist, 404, "user_not_found"},
+		{"wrapped", fmt.Errorf("%w: missing USER_TABLE", types.ErrorLoadingConfig), 503, "config_unavailable"},
+		{"with details", types.MissingParameter("id"), 400, "missing_parameter"},
+		{"throttled", awserr.New("ThrottlingException", "rate exceeded", nil), 503, "service_unavailable"},
+		{"untyped", errors.New("boom"), 500, "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{}
+			request.RequestContext.RequestID = "req-1"
+			res := Error(request, tt.err)
+
+			var body types.ErrorResponse
+			if err := json.Unmarshal([]byte(res.Body), &body); err != nil {
+				t.Fatalf("Error() body = %q, not json", res.Body)
+			}
+			if res.StatusCode != tt.wantStatus || body.Code != tt.wantCode || body.RequestID != "req-1" {
+				t.Errorf("Error() = %d %+v, want %d %s", res.StatusCode, body, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		request events.APIGatewayProxyRequest
+		want    string
+		wantErr error
+	}{
+		{"json body", events.APIGatewayProxyRequest{Body: `{"password":"secret"}`}, "secret", nil},
+		{"base64 body", events.APIGatewayProxyRequest{
+			Body:            base64.StdEncoding.EncodeToString([]byte(`{"password":"secret"}`)),
+			IsBase64Encoded: true,
+		}, "secret", nil},
+		{"invalid json", events.APIGatewayProxyRequest{Body: `{"password":`}, "", types.ErrorInvalidBody},
+		{"unknown field", events.APIGatewayProxyRequest{Body: `{"password":"secret","permanent":true}`}, "", types.ErrorValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode[types.TemporaryPassword](tt.request, validation.TemporaryPassword)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Decode() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.Password != tt.want {
+				t.Errorf("Decode() = %+v, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
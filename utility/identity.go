@@ -0,0 +1,31 @@
+package utility
+
+import (
+	"ascenda/types"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// UserIDContextKey is the authorizer context key carrying the caller's user_id.
+const UserIDContextKey = "user_id"
+
+// UserIDFromRequest returns the user_id of the caller, as verified from their
+// token by the lambda authorizer.
+func UserIDFromRequest(req events.APIGatewayProxyRequest) (string, error) {
+	value, _ := authorizerValue(req.RequestContext.Authorizer, UserIDContextKey)
+	userID, ok := value.(string)
+	if !ok || userID == "" {
+		return "", types.ErrorNotAuthenticated
+	}
+	return userID, nil
+}
+
+// Requester identifies the caller of req in audit records: the user_id the
+// authorizer verified, or the client supplied requester parameter only when
+// the request carries no authorizer context.
+func Requester(req events.APIGatewayProxyRequest) string {
+	if userID, err := UserIDFromRequest(req); err == nil {
+		return userID
+	}
+	return req.QueryStringParameters["requester"]
+}
@@ -0,0 +1,191 @@
+package utility
+
+import (
+	"ascenda/types"
+	"ascenda/validation"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// MaxImportRows keeps an import job within the dynamo item size limit.
+const MaxImportRows = 1000
+
+// importColumns are the CSV columns an import must have, in any order.
+var importColumns = []string{"email", "first_name", "last_name", "role"}
+
+// ParseImportCSV reads the rows of an import CSV. The first line is a header
+// naming the columns, extra columns are ignored.
+func ParseImportCSV(body string) ([]types.ImportRow, error) {
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, types.ErrorInvalidCSV
+	}
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range importColumns {
+		if _, ok := index[column]; !ok {
+			return nil, types.ErrorInvalidCSV
+		}
+	}
+
+	rows := []types.ImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, types.ErrorInvalidCSV
+		}
+		if len(rows) == MaxImportRows {
+			return nil, types.ErrorImportTooLarge
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, types.ImportRow{
+			Line:      line,
+			Email:     importField(record, index["email"]),
+			FirstName: importField(record, index["first_name"]),
+			LastName:  importField(record, index["last_name"]),
+			Role:      importField(record, index["role"]),
+			Status:    types.ImportRowPending,
+		})
+	}
+
+	return rows, nil
+}
+
+// importField returns the trimmed column of the record, empty for short rows.
+func importField(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// ValidateImportRows marks pending rows that cannot be provisioned as invalid,
+// or as duplicate when the email already exists or appeared on an earlier row.
+func ValidateImportRows(rows []types.ImportRow, existingEmails map[string]bool, rolesTable string,
+	dynaClient dynamodbiface.DynamoDBAPI, policy *types.Policy) error {
+	roles := map[string]bool{}
+	seen := map[string]bool{}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Status != types.ImportRowPending {
+			continue
+		}
+
+		if _, ok := roles[row.Role]; !ok && row.Role != "" {
+			exists, err := RoleExists(row.Role, rolesTable, dynaClient)
+			if err != nil {
+				return err
+			}
+			roles[row.Role] = exists
+		}
+
+		email := EmailKey(row.Email)
+		switch {
+		case !validation.IsEmail(row.Email):
+			row.Status, row.Error = types.ImportRowInvalid, types.ErrorInvalidEmail.Error()
+		case len(row.FirstName) == 0:
+			row.Status, row.Error = types.ImportRowInvalid, types.ErrorInvalidFirstName.Error()
+		case len(row.LastName) == 0:
+			row.Status, row.Error = types.ImportRowInvalid, types.ErrorInvalidLastName.Error()
+		case !roles[row.Role]:
+			row.Status, row.Error = types.ImportRowInvalid, types.ErrorUnknownRole.Error()
+		case !CanTargetRole(policy, row.Role):
+			row.Status, row.Error = types.ImportRowInvalid, types.ErrorNotPermittedByPolicy.Error()
+		case existingEmails[email] || seen[email]:
+			row.Status, row.Error = types.ImportRowDuplicate, types.ErrorEmailAlreadyExists.Error()
+		}
+		seen[email] = true
+	}
+
+	return nil
+}
+
+// SummarizeImport counts the rows of the job by status.
+func SummarizeImport(job *types.ImportJob) {
+	job.Summary = map[string]int{}
+	for _, row := range job.Rows {
+		job.Summary[row.Status]++
+	}
+}
+
+// ImportResultCSV renders the outcome of every row of the job as CSV.
+func ImportResultCSV(job *types.ImportJob) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	records := [][]string{{"line", "email", "first_name", "last_name", "role", "status", "user_id", "error"}}
+	for _, row := range job.Rows {
+		records = append(records, []string{strconv.Itoa(row.Line), row.Email, row.FirstName, row.LastName, row.Role,
+			row.Status, row.User_ID, row.Error})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// SaveImportJob writes the job with its summary recomputed.
+func SaveImportJob(job *types.ImportJob, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	SummarizeImport(job)
+	job.UpdatedAt = time.Now().Unix()
+
+	av, err := dynamodbattribute.MarshalMap(job)
+	if err != nil {
+		return types.ErrorCouldNotMarshalItem
+	}
+
+	_, err = dynaClient.PutItem(&dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return types.ErrorCouldNotDynamoPutItem
+	}
+	return nil
+}
+
+// FetchImportJob returns the import job with the id.
+func FetchImportJob(jobID, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.ImportJob, error) {
+	result, err := dynaClient.GetItem(&dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"job_id": {
+				S: aws.String(jobID),
+			},
+		},
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+
+	if result.Item == nil {
+		return nil, types.ErrorImportJobDoesNotExist
+	}
+
+	job := new(types.ImportJob)
+	if err := dynamodbattribute.UnmarshalMap(result.Item, job); err != nil {
+		return nil, types.ErrorFailedToUnmarshalRecord
+	}
+	return job, nil
+}
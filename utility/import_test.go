@@ -0,0 +1,85 @@
+package utility
+
+import (
+	"ascenda/types"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// importRoles knows the customer role only.
+type importRoles struct {
+	dynamodbiface.DynamoDBAPI
+}
+
+func (f *importRoles) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	if role := input.Key["role"]; role != nil && *role.S == "customer" {
+		return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{"role": role}}, nil
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func TestParseImportCSV(t *testing.T) {
+	body := "Role,email,first_name,last_name,notes\n" +
+		"customer, jane@example.com ,Jane,Doe,vip\n" +
+		"customer,john@example.com\n"
+
+	rows, err := ParseImportCSV(body)
+	if err != nil {
+		t.Fatalf("ParseImportCSV() error = %v", err)
+	}
+
+	want := []types.ImportRow{
+		{Line: 2, Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", Role: "customer", Status: types.ImportRowPending},
+		{Line: 3, Email: "john@example.com", Role: "customer", Status: types.ImportRowPending},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("ParseImportCSV() = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestParseImportCSVRejectsMissingColumn(t *testing.T) {
+	if _, err := ParseImportCSV("email,first_name,last_name\n"); !errors.Is(err, types.ErrorInvalidCSV) {
+		t.Fatalf("ParseImportCSV() error = %v, want %s", err, types.ErrorInvalidCSV)
+	}
+}
+
+func TestValidateImportRows(t *testing.T) {
+	rows := []types.ImportRow{
+		{Email: "new@example.com", FirstName: "A", LastName: "B", Role: "customer"},
+		{Email: "not-an-email", FirstName: "A", LastName: "B", Role: "customer"},
+		{Email: "x@example.com", FirstName: "A", LastName: "B", Role: "ghost"},
+		{Email: "Taken@example.com", FirstName: "A", LastName: "B", Role: "customer"},
+		{Email: "NEW@example.com", FirstName: "A", LastName: "B", Role: "customer"},
+		{Email: "y@example.com", LastName: "B", Role: "customer"},
+	}
+	for i := range rows {
+		rows[i].Status = types.ImportRowPending
+	}
+
+	err := ValidateImportRows(rows, map[string]bool{"taken@example.com": true}, "roles", &importRoles{}, nil)
+	if err != nil {
+		t.Fatalf("ValidateImportRows() error = %v", err)
+	}
+
+	want := []struct{ status, err string }{
+		{types.ImportRowPending, ""},
+		{types.ImportRowInvalid, types.ErrorInvalidEmail.Error()},
+		{types.ImportRowInvalid, types.ErrorUnknownRole.Error()},
+		{types.ImportRowDuplicate, types.ErrorEmailAlreadyExists.Error()},
+		{types.ImportRowDuplicate, types.ErrorEmailAlreadyExists.Error()},
+		{types.ImportRowInvalid, types.ErrorInvalidFirstName.Error()},
+	}
+	for i, w := range want {
+		if rows[i].Status != w.status || rows[i].Error != w.err {
+			t.Errorf("row %d = %s %q, want %s %q", i, rows[i].Status, rows[i].Error, w.status, w.err)
+		}
+	}
+}
@@ -2,8 +2,7 @@ package utility
 
 import (
 	"ascenda/types"
-	"errors"
-	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,12 +15,7 @@ import (
 	"github.com/google/uuid"
 )
 
-var (
-	ErrorFailedToUnmarshalRecord = "failed to unmarshal record"
-	ErrorFailedToFetchRecordID   = "failed to fetch record by uuid"
-)
-
-func FetchUserByID(id string, req events.APIGatewayProxyRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.User, error) {
+func FetchUserByID(id string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.User, error) {
 	//get single user from dynamo
 	input := &dynamodb.GetItemInput{
 		Key: map[string]*dynamodb.AttributeValue{
@@ -34,28 +28,45 @@ func FetchUserByID(id string, req events.APIGatewayProxyRequest, tableName strin
 
 	result, err := dynaClient.GetItem(input)
 	if err != nil {
-		return nil, errors.New(ErrorFailedToFetchRecordID)
+		return nil, types.ErrorFailedToFetchRecordID
 	}
 
 	if result.Item == nil {
-		return nil, errors.New("user does not exist")
+		return nil, types.ErrorUserDoesNotExist
 	}
 
 	item := new(types.User)
 	err = dynamodbattribute.UnmarshalMap(result.Item, item)
 	if err != nil {
-		return nil, errors.New(ErrorFailedToUnmarshalRecord)
+		return nil, types.ErrorFailedToUnmarshalRecord
 	}
 
 	return item, nil
 }
 
+// requesterName is the actor logged for req: the user_id from the authorizer,
+// or without one the "first-last" requester parameter with a space for the
+// first hyphen, or the parameter as given when it has no hyphen.
+func requesterName(req events.APIGatewayProxyRequest) string {
+	if userID, err := UserIDFromRequest(req); err == nil {
+		return userID
+	}
+	requester := req.QueryStringParameters["requester"]
+	if first, last, ok := strings.Cut(requester, "-"); ok {
+		return first + " " + last
+	}
+	if requester != "" {
+		return requester
+	}
+	return "unknown requester"
+}
+
 func SendCreateUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbiface.DynamoDBAPI, logTABLE string, ttl string,
 	firstName string, lastName string, role string) error {
 	// Calculate the TTL value (one month from now)
 	ttlNum, err := strconv.Atoi(ttl)
 	if err != nil {
-		return errors.New("invalid ttl")
+		return types.ErrorLoadingConfig
 	}
 
 	now := time.Now()
@@ -63,8 +74,7 @@ func SendCreateUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	ttlValue := oneWeekFromNow.Unix()
 
 	//requester
-	requester := req.QueryStringParameters["requester"]
-	s := strings.Split(requester, "-")
+	requester := requesterName(req)
 
 	//create log struct
 	log := types.Log{}
@@ -74,15 +84,15 @@ func SendCreateUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	log.TTL = ttlValue
 
 	if role != "" {
-		log.Description = s[0] + " " + s[1] + " enrolled " + role + " " + firstName + " " + lastName
+		log.Description = requester + " enrolled " + role + " " + firstName + " " + lastName
 	} else {
-		log.Description = s[0] + " " + s[1] + " enrolled user " + firstName + " " + lastName
+		log.Description = requester + " enrolled user " + firstName + " " + lastName
 	}
 	log.Timestamp = time.Now().Unix()
 	av, err := dynamodbattribute.MarshalMap(log)
 
 	if err != nil {
-		return errors.New("failed to marshal log")
+		return types.ErrorCouldNotMarshalItem
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -91,7 +101,7 @@ func SendCreateUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	}
 	_, err = dynaClient.PutItem(input)
 	if err != nil {
-		return errors.New("Could not dynamo put")
+		return types.ErrorCouldNotDynamoPutItem
 	}
 
 	return nil
@@ -102,7 +112,7 @@ func SendDeleteUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	// Calculate the TTL value (one month from now)
 	ttlNum, err := strconv.Atoi(ttl)
 	if err != nil {
-		return errors.New("invalid ttl")
+		return types.ErrorLoadingConfig
 	}
 
 	now := time.Now()
@@ -110,8 +120,7 @@ func SendDeleteUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	ttlValue := oneWeekFromNow.Unix()
 
 	//requester
-	requester := req.QueryStringParameters["requester"]
-	s := strings.Split(requester, "-")
+	requester := requesterName(req)
 
 	//create log struct
 	log := types.Log{}
@@ -119,12 +128,12 @@ func SendDeleteUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	log.IP = req.Headers["x-forwarded-for"]
 	log.UserAgent = req.Headers["user-agent"]
 	log.TTL = ttlValue
-	log.Description = s[0] + " " + s[1] + " deleted user " + firstName + " " + lastName
+	log.Description = requester + " deleted user " + firstName + " " + lastName
 	log.Timestamp = time.Now().Unix()
 	av, err := dynamodbattribute.MarshalMap(log)
 
 	if err != nil {
-		return errors.New("failed to marshal log")
+		return types.ErrorCouldNotMarshalItem
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -133,7 +142,7 @@ func SendDeleteUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	}
 	_, err = dynaClient.PutItem(input)
 	if err != nil {
-		return errors.New("Could not dynamo put")
+		return types.ErrorCouldNotDynamoPutItem
 	}
 
 	return nil
@@ -144,14 +153,13 @@ func SendUpdatePointLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbi
 	// Calculate the TTL value (one month from now)
 	ttlNum, err := strconv.Atoi(ttl)
 	if err != nil {
-		return errors.New("invalid ttl")
+		return types.ErrorLoadingConfig
 	}
 
 	//get updated user points name
-	res, err := FetchUserByID(userID, req, userTable, dynaClient)
+	res, err := FetchUserByID(userID, userTable, dynaClient)
 	if err != nil {
-		log.Println(err)
-		return errors.New("failed to get user")
+		return err
 	}
 
 	now := time.Now()
@@ -159,8 +167,7 @@ func SendUpdatePointLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbi
 	ttlValue := oneWeekFromNow.Unix()
 
 	//requester
-	requester := req.QueryStringParameters["requester"]
-	s := strings.Split(requester, "-")
+	requester := requesterName(req)
 
 	//create log struct
 	log := types.Log{}
@@ -172,12 +179,12 @@ func SendUpdatePointLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbi
 	stringOld := strconv.Itoa(oldPoints)
 	stringNew := strconv.Itoa(newPoints)
 
-	log.Description = s[0] + " " + s[1] + " adjusted points of " + res.FirstName + " " + res.LastName + " from " + stringOld + " to " + stringNew
+	log.Description = requester + " adjusted points of " + res.FirstName + " " + res.LastName + " from " + stringOld + " to " + stringNew
 	log.Timestamp = time.Now().Unix()
 	av, err := dynamodbattribute.MarshalMap(log)
 
 	if err != nil {
-		return errors.New("failed to marshal log")
+		return types.ErrorCouldNotMarshalItem
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -186,18 +193,18 @@ func SendUpdatePointLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbi
 	}
 	_, err = dynaClient.PutItem(input)
 	if err != nil {
-		return errors.New("Could not dynamo put")
+		return types.ErrorCouldNotDynamoPutItem
 	}
 
 	return nil
 }
 
 func SendUpdateUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbiface.DynamoDBAPI, logTable string, ttl string,
-	firstName string, lastName string) error {
+	firstName string, lastName string, fields []string) error {
 	// Calculate the TTL value (one month from now)
 	ttlNum, err := strconv.Atoi(ttl)
 	if err != nil {
-		return errors.New("invalid ttl")
+		return types.ErrorLoadingConfig
 	}
 
 	now := time.Now()
@@ -205,8 +212,215 @@ func SendUpdateUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	ttlValue := oneWeekFromNow.Unix()
 
 	//requester
-	requester := req.QueryStringParameters["requester"]
-	s := strings.Split(requester, "-")
+	requester := requesterName(req)
+
+	//create log struct
+	log := types.Log{}
+	log.Log_ID = uuid.NewString()
+	log.IP = req.Headers["x-forwarded-for"]
+	log.UserAgent = req.Headers["user-agent"]
+	log.TTL = ttlValue
+	log.Description = requester + " updated " + strings.Join(fields, ", ") + " for " + firstName + " " + lastName
+	log.Timestamp = time.Now().Unix()
+	av, err := dynamodbattribute.MarshalMap(log)
+
+	if err != nil {
+		return types.ErrorCouldNotMarshalItem
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(logTable),
+	}
+	_, err = dynaClient.PutItem(input)
+	if err != nil {
+		return types.ErrorCouldNotDynamoPutItem
+	}
+
+	return nil
+}
+
+// ReconciliationReportID is the log_id under which the latest drift report is kept.
+const ReconciliationReportID = "reconciliation-latest"
+
+// DriftPageSize is how many drifts are stored in one item of the drifts table,
+// keeping each item well below DynamoDB's 400KB limit.
+const DriftPageSize = 500
+
+// SendReconciliationLogs stores the drifts of the report in pages of the drifts
+// table under its run id, and logs the report with only the counts, both in the
+// history and as the latest report.
+func SendReconciliationLogs(dynaClient dynamodbiface.DynamoDBAPI, logTable string, driftsTable string, ttl string,
+	report *types.DriftReport) error {
+	// Calculate the TTL value (one month from now)
+	ttlNum, err := strconv.Atoi(ttl)
+	if err != nil {
+		return types.ErrorLoadingConfig
+	}
+
+	now := time.Now()
+	oneWeekFromNow := now.AddDate(0, 0, ttlNum)
+	ttlValue := oneWeekFromNow.Unix()
+
+	categories := make([]string, 0, len(report.Drifts))
+	for category := range report.Drifts {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	counts := make([]string, 0, len(categories))
+	drifts := []types.Drift{}
+	for _, category := range categories {
+		counts = append(counts, category+" "+strconv.Itoa(len(report.Drifts[category])))
+		drifts = append(drifts, report.Drifts[category]...)
+	}
+
+	//store the drifts in pages
+	for page := 0; page*DriftPageSize < len(drifts); page++ {
+		end := min((page+1)*DriftPageSize, len(drifts))
+		av, err := dynamodbattribute.MarshalMap(types.DriftPage{Run_ID: report.Run_ID, Page: page,
+			Drifts: drifts[page*DriftPageSize : end], TTL: ttlValue})
+		if err != nil {
+			return types.ErrorCouldNotMarshalItem
+		}
+
+		_, err = dynaClient.PutItem(&dynamodb.PutItemInput{
+			Item:      av,
+			TableName: aws.String(driftsTable),
+		})
+		if err != nil {
+			return types.ErrorCouldNotDynamoPutItem
+		}
+	}
+
+	summary := *report
+	summary.Drifts = nil
+
+	//create log struct
+	log := types.Log{}
+	log.TTL = ttlValue
+	log.Description = "reconciliation found " + strconv.Itoa(len(drifts)) + " drifted users"
+	if len(counts) > 0 {
+		log.Description += " (" + strings.Join(counts, ", ") + ")"
+	}
+	log.Timestamp = report.GeneratedAt
+	log.Report = &summary
+
+	//keep the run in history and as the latest report
+	for _, id := range []string{report.Run_ID, ReconciliationReportID} {
+		log.Log_ID = id
+		av, err := dynamodbattribute.MarshalMap(log)
+
+		if err != nil {
+			return types.ErrorCouldNotMarshalItem
+		}
+
+		input := &dynamodb.PutItemInput{
+			Item:      av,
+			TableName: aws.String(logTable),
+		}
+		_, err = dynaClient.PutItem(input)
+		if err != nil {
+			return types.ErrorCouldNotDynamoPutItem
+		}
+	}
+
+	return nil
+}
+
+// FetchDrifts returns the drifts stored for the run, grouped by category.
+func FetchDrifts(runID string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (map[string][]types.Drift, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("run_id = :run_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":run_id": {S: aws.String(runID)},
+		},
+	}
+
+	drifts := map[string][]types.Drift{}
+	for {
+		result, err := dynaClient.Query(input)
+		if err != nil {
+			return nil, types.ErrorCouldNotQueryDB
+		}
+
+		pages := []types.DriftPage{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &pages); err != nil {
+			return nil, types.ErrorFailedToUnmarshal
+		}
+		for _, page := range pages {
+			for _, drift := range page.Drifts {
+				drifts[drift.Category] = append(drifts[drift.Category], drift)
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return drifts, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func SendClosePointsLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbiface.DynamoDBAPI, logTable string, ttl string,
+	firstName string, lastName string, point types.UserPoint) error {
+	// Calculate the TTL value (one month from now)
+	ttlNum, err := strconv.Atoi(ttl)
+	if err != nil {
+		return types.ErrorLoadingConfig
+	}
+
+	now := time.Now()
+	oneWeekFromNow := now.AddDate(0, 0, ttlNum)
+	ttlValue := oneWeekFromNow.Unix()
+
+	//requester
+	requester := requesterName(req)
+
+	//create log struct
+	log := types.Log{}
+	log.Log_ID = uuid.NewString()
+	log.IP = req.Headers["x-forwarded-for"]
+	log.UserAgent = req.Headers["user-agent"]
+	log.TTL = ttlValue
+
+	log.Description = requester + " closed points account " + point.Points_ID + " of " + firstName + " " + lastName +
+		" with balance " + strconv.Itoa(point.Points)
+	log.Timestamp = time.Now().Unix()
+	av, err := dynamodbattribute.MarshalMap(log)
+
+	if err != nil {
+		return types.ErrorCouldNotMarshalItem
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(logTable),
+	}
+	_, err = dynaClient.PutItem(input)
+	if err != nil {
+		return types.ErrorCouldNotDynamoPutItem
+	}
+
+	return nil
+}
+
+// SendUserActionLogs records an action taken on a user, action being the past
+// tense phrase preceding "user", such as "disabled" or "reset the password of".
+func SendUserActionLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbiface.DynamoDBAPI, logTable string, ttl string,
+	firstName string, lastName string, action string) error {
+	// Calculate the TTL value (one month from now)
+	ttlNum, err := strconv.Atoi(ttl)
+	if err != nil {
+		return types.ErrorLoadingConfig
+	}
+
+	now := time.Now()
+	oneWeekFromNow := now.AddDate(0, 0, ttlNum)
+	ttlValue := oneWeekFromNow.Unix()
+
+	//requester
+	requester := requesterName(req)
 
 	//create log struct
 	log := types.Log{}
@@ -214,12 +428,12 @@ func SendUpdateUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	log.IP = req.Headers["x-forwarded-for"]
 	log.UserAgent = req.Headers["user-agent"]
 	log.TTL = ttlValue
-	log.Description = s[0] + " " + s[1] + " updated user information for " + firstName + " " + lastName
+	log.Description = requester + " " + action + " user " + firstName + " " + lastName
 	log.Timestamp = time.Now().Unix()
 	av, err := dynamodbattribute.MarshalMap(log)
 
 	if err != nil {
-		return errors.New("failed to marshal log")
+		return types.ErrorCouldNotMarshalItem
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -228,7 +442,7 @@ func SendUpdateUserLogs(req events.APIGatewayProxyRequest, dynaClient dynamodbif
 	}
 	_, err = dynaClient.PutItem(input)
 	if err != nil {
-		return errors.New("Could not dynamo put")
+		return types.ErrorCouldNotDynamoPutItem
 	}
 
 	return nil
@@ -0,0 +1,35 @@
+package utility
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestRequesterName(t *testing.T) {
+	tests := []struct {
+		name       string
+		requester  string
+		authorizer map[string]interface{}
+		want       string
+	}{
+		{"first and last name", "Ada-Admin", nil, "Ada Admin"},
+		{"hyphenated last name", "Ada-Lovelace-Byron", nil, "Ada Lovelace-Byron"},
+		{"no hyphen", "Ada", nil, "Ada"},
+		{"authorized user", "", map[string]interface{}{UserIDContextKey: "u-1"}, "u-1"},
+		{"authorized user claiming another name", "Ada-Admin", map[string]interface{}{UserIDContextKey: "u-1"}, "u-1"},
+		{"nothing to go by", "", nil, "unknown requester"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{"requester": tt.requester},
+				RequestContext:        events.APIGatewayProxyRequestContext{Authorizer: tt.authorizer},
+			}
+			if got := requesterName(req); got != tt.want {
+				t.Errorf("requesterName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
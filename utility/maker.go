@@ -2,26 +2,22 @@ package utility
 
 import (
 	"ascenda/types"
-	"errors"
+	"encoding/json"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/google/uuid"
 )
 
-var (
-	ErrorCouldNotDynamoPutItem = "could not dynamo put item"
-	ErrorCouldNotUnmarshalItem = "could not unmarshal maker request"
-)
-
 func BatchWriteToDynamoDB(roleCount int, makerRequests []types.MakerRequest, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.ReturnMakerRequest, error) {
 	writeRequests := make([]*dynamodb.WriteRequest, roleCount)
 
 	for i, request := range makerRequests {
 		item, err := dynamodbattribute.MarshalMap(request)
 		if err != nil {
-			return nil, errors.New(ErrorCouldNotUnmarshalItem)
+			return nil, types.ErrorCouldNotMarshalItem
 		}
 
 		writeRequest := &dynamodb.WriteRequest{
@@ -41,7 +37,7 @@ func BatchWriteToDynamoDB(roleCount int, makerRequests []types.MakerRequest, tab
 	_, err := dynaClient.BatchWriteItem(input)
 
 	if err != nil {
-		return nil, errors.New(ErrorCouldNotDynamoPutItem)
+		return nil, types.ErrorCouldNotDynamoPutItem
 	}
 	return FormatMakerRequest(makerRequests), nil
 }
@@ -93,3 +89,75 @@ func DeconstructPostMakerRequest(postMakerRequest types.NewMakerRequest) []types
 	}
 	return makerRequests
 }
+
+// makerTarget holds the user_id both user and points request data carry.
+type makerTarget struct {
+	User_ID string `json:"user_id"`
+}
+
+// FetchRequestsForUser returns the maker request rows in status whose request
+// data targets the user.
+func FetchRequestsForUser(userID, status, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.MakerRequest, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(tableName),
+		FilterExpression: aws.String("#request_status = :request_status"),
+		ExpressionAttributeNames: map[string]*string{
+			"#request_status": aws.String("request_status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":request_status": {S: aws.String(status)},
+		},
+	}
+
+	targeted := []types.MakerRequest{}
+	for {
+		result, err := dynaClient.Scan(input)
+		if err != nil {
+			return nil, types.ErrorFailedToFetchRecord
+		}
+
+		page := []types.MakerRequest{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, types.ErrorFailedToUnmarshal
+		}
+		for _, request := range page {
+			var target makerTarget
+			if err := json.Unmarshal(request.RequestData, &target); err != nil {
+				continue
+			}
+			if target.User_ID == userID {
+				targeted = append(targeted, request)
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return targeted, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// SetMakerRequestsStatus moves the maker request rows to status on behalf of checkerID.
+func SetMakerRequestsStatus(makerRequests []types.MakerRequest, status, checkerID, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	for _, request := range makerRequests {
+		_, err := dynaClient.UpdateItem(&dynamodb.UpdateItemInput{
+			Key: map[string]*dynamodb.AttributeValue{
+				"req_id":       {S: aws.String(request.RequestUUID)},
+				"checker_role": {S: aws.String(request.CheckerRole)},
+			},
+			TableName:        aws.String(tableName),
+			UpdateExpression: aws.String("SET #request_status = :request_status, checker_id = :checker_id"),
+			ExpressionAttributeNames: map[string]*string{
+				"#request_status": aws.String("request_status"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":request_status": {S: aws.String(status)},
+				":checker_id":     {S: aws.String(checkerID)},
+			},
+		})
+		if err != nil {
+			return types.ErrorCouldNotDynamoPutItem
+		}
+	}
+	return nil
+}
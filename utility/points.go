@@ -0,0 +1,137 @@
+package utility
+
+import (
+	"ascenda/types"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// FetchPointsByUser returns every points account of the user.
+func FetchPointsByUser(userID, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.UserPoint, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user_id": {S: aws.String(userID)},
+		},
+	}
+
+	points := []types.UserPoint{}
+	for {
+		result, err := dynaClient.Query(input)
+		if err != nil {
+			return nil, types.ErrorFailedToFetchRecord
+		}
+
+		page := []types.UserPoint{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, types.ErrorFailedToUnmarshalRecord
+		}
+		points = append(points, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return points, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ClosePointsAccounts zeroes and closes the accounts, keeping them as an archive,
+// or deletes them when hard is set.
+func ClosePointsAccounts(points []types.UserPoint, hard bool, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	for _, point := range points {
+		key := map[string]*dynamodb.AttributeValue{
+			"user_id":   {S: aws.String(point.User_ID)},
+			"points_id": {S: aws.String(point.Points_ID)},
+		}
+
+		if hard {
+			_, err := dynaClient.DeleteItem(&dynamodb.DeleteItemInput{
+				Key:       key,
+				TableName: aws.String(tableName),
+			})
+			if err != nil {
+				return types.ErrorCouldNotDeleteItem
+			}
+			continue
+		}
+
+		_, err := dynaClient.UpdateItem(&dynamodb.UpdateItemInput{
+			Key:              key,
+			TableName:        aws.String(tableName),
+			UpdateExpression: aws.String("SET points = :points, #status = :status"),
+			ExpressionAttributeNames: map[string]*string{
+				"#status": aws.String("status"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":points": {N: aws.String("0")},
+				":status": {S: aws.String(types.PointsStatusClosed)},
+			},
+		})
+		if err != nil {
+			return types.ErrorCouldNotDynamoPutItem
+		}
+	}
+	return nil
+}
+
+// SetPointsStatus moves the accounts to status, keeping their balances.
+func SetPointsStatus(points []types.UserPoint, status string, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	for _, point := range points {
+		_, err := dynaClient.UpdateItem(&dynamodb.UpdateItemInput{
+			Key: map[string]*dynamodb.AttributeValue{
+				"user_id":   {S: aws.String(point.User_ID)},
+				"points_id": {S: aws.String(point.Points_ID)},
+			},
+			TableName:        aws.String(tableName),
+			UpdateExpression: aws.String("SET #status = :status"),
+			ExpressionAttributeNames: map[string]*string{
+				"#status": aws.String("status"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":status": {S: aws.String(status)},
+			},
+		})
+		if err != nil {
+			return types.ErrorCouldNotDynamoPutItem
+		}
+	}
+	return nil
+}
+
+// PointsWithStatus returns the accounts in one of the statuses, an empty
+// status matching accounts stored before statuses existed.
+func PointsWithStatus(points []types.UserPoint, statuses ...string) []types.UserPoint {
+	matching := []types.UserPoint{}
+	for _, point := range points {
+		for _, status := range statuses {
+			if point.Status == status {
+				matching = append(matching, point)
+				break
+			}
+		}
+	}
+	return matching
+}
+
+// RestorePointsAccounts writes the accounts back as they were before closing.
+func RestorePointsAccounts(points []types.UserPoint, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	for _, point := range points {
+		av, err := dynamodbattribute.MarshalMap(point)
+		if err != nil {
+			return types.ErrorCouldNotMarshalItem
+		}
+
+		_, err = dynaClient.PutItem(&dynamodb.PutItemInput{
+			Item:      av,
+			TableName: aws.String(tableName),
+		})
+		if err != nil {
+			return types.ErrorCouldNotDynamoPutItem
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package utility
+
+import (
+	"ascenda/types"
+	"encoding/json"
+	"slices"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// PolicyContextKey is the authorizer context key carrying the caller's role policy.
+const PolicyContextKey = "policy"
+
+// PolicyFromRequest returns the role policy the lambda authorizer attached to the
+// request. Requests that did not pass through the authorizer carry no policy and
+// are refused with ErrorForbidden rather than treated as unrestricted.
+func PolicyFromRequest(req events.APIGatewayProxyRequest) (*types.Policy, error) {
+	policy := new(types.Policy)
+
+	value, ok := authorizerValue(req.RequestContext.Authorizer, PolicyContextKey)
+	if !ok {
+		return nil, types.ErrorForbidden
+	}
+
+	encoded, ok := value.(string)
+	if !ok {
+		return nil, types.ErrorInvalidPolicy
+	}
+	if err := json.Unmarshal([]byte(encoded), policy); err != nil {
+		return nil, types.ErrorInvalidPolicy
+	}
+	return policy, nil
+}
+
+// authorizerValue looks up key in the authorizer context, which API Gateway
+// nests under "lambda" for HTTP APIs.
+func authorizerValue(authorizer map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := authorizer[key]; ok {
+		return value, true
+	}
+	if nested, ok := authorizer["lambda"].(map[string]interface{}); ok {
+		value, ok := nested[key]
+		return value, ok
+	}
+	return nil, false
+}
+
+// CanTargetRole reports whether the policy lets the caller act on users holding role.
+func CanTargetRole(policy *types.Policy, role string) bool {
+	if policy == nil || len(policy.TargetRoles) == 0 {
+		return true
+	}
+	return slices.Contains(policy.TargetRoles, role)
+}
+
+// CanUpdateUserFields reports whether every changed field is in the policy's allowlist.
+func CanUpdateUserFields(policy *types.Policy, fields []string) bool {
+	if policy == nil || len(policy.UserFields) == 0 {
+		return true
+	}
+	for _, field := range fields {
+		if !slices.Contains(policy.UserFields, field) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanChangePoints reports whether moving a balance from oldPoints to newPoints
+// stays within the policy's limit.
+func CanChangePoints(policy *types.Policy, oldPoints, newPoints int) bool {
+	if policy == nil || policy.MaxPointsChange == 0 {
+		return true
+	}
+	change := newPoints - oldPoints
+	if change < 0 {
+		change = -change
+	}
+	return change <= policy.MaxPointsChange
+}
+
+// ChangedUserFields returns the json names of the attributes that differ between
+// the stored user and the update.
+func ChangedUserFields(current, update types.User) []string {
+	var fields []string
+	if current.Email != update.Email {
+		fields = append(fields, "email")
+	}
+	if current.FirstName != update.FirstName {
+		fields = append(fields, "first_name")
+	}
+	if current.LastName != update.LastName {
+		fields = append(fields, "last_name")
+	}
+	if current.Role != update.Role {
+		fields = append(fields, "role")
+	}
+	return fields
+}
@@ -0,0 +1,79 @@
+package utility
+
+import (
+	"ascenda/types"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestPolicyFromRequest(t *testing.T) {
+	encoded := `{"user_fields":["first_name"],"max_points_change":500,"target_roles":["customer"]}`
+	tests := []struct {
+		name       string
+		authorizer map[string]interface{}
+		want       types.Policy
+		wantErr    bool
+	}{
+		{"no authorizer", nil, types.Policy{}, true},
+		{"top level context", map[string]interface{}{"policy": encoded},
+			types.Policy{UserFields: []string{"first_name"}, MaxPointsChange: 500, TargetRoles: []string{"customer"}}, false},
+		{"http api lambda context", map[string]interface{}{"lambda": map[string]interface{}{"policy": encoded}},
+			types.Policy{UserFields: []string{"first_name"}, MaxPointsChange: 500, TargetRoles: []string{"customer"}}, false},
+		{"malformed policy", map[string]interface{}{"policy": "{"}, types.Policy{}, true},
+		{"non string policy", map[string]interface{}{"policy": 1}, types.Policy{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := events.APIGatewayProxyRequest{}
+			req.RequestContext.Authorizer = tt.authorizer
+			got, err := PolicyFromRequest(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PolicyFromRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(got.UserFields, tt.want.UserFields) || got.MaxPointsChange != tt.want.MaxPointsChange ||
+				!slices.Equal(got.TargetRoles, tt.want.TargetRoles) {
+				t.Errorf("PolicyFromRequest() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyChecks(t *testing.T) {
+	policy := &types.Policy{
+		UserFields:      []string{"first_name", "last_name"},
+		MaxPointsChange: 500,
+		TargetRoles:     []string{"customer"},
+	}
+
+	if !CanTargetRole(policy, "customer") || CanTargetRole(policy, "admin") {
+		t.Error("CanTargetRole did not respect target roles")
+	}
+	if !CanTargetRole(&types.Policy{}, "admin") || !CanTargetRole(nil, "admin") {
+		t.Error("CanTargetRole restricted an empty policy")
+	}
+
+	if !CanUpdateUserFields(policy, []string{"first_name"}) || CanUpdateUserFields(policy, []string{"first_name", "role"}) {
+		t.Error("CanUpdateUserFields did not respect field allowlist")
+	}
+
+	if !CanChangePoints(policy, 100, 600) || !CanChangePoints(policy, 600, 100) || CanChangePoints(policy, 0, 501) {
+		t.Error("CanChangePoints did not respect points limit")
+	}
+	if !CanChangePoints(&types.Policy{}, 0, 100000) {
+		t.Error("CanChangePoints restricted an empty policy")
+	}
+
+	current := types.User{Email: "a@b.com", FirstName: "A", LastName: "B", Role: "customer"}
+	update := current
+	update.FirstName = "C"
+	update.Role = "admin"
+	if got := ChangedUserFields(current, update); !slices.Equal(got, []string{"first_name", "role"}) {
+		t.Errorf("ChangedUserFields() = %v", got)
+	}
+}
@@ -0,0 +1,145 @@
+package utility
+
+import (
+	"ascenda/types"
+	"errors"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+// ProvisionUser creates the user in dynamo then cognito, undoing earlier steps
+// on failure. The email is reserved in emailsTable, failing with
+// ErrorEmailAlreadyExists if taken. Without a password cognito emails the user
+// a temporary one.
+func ProvisionUser(user types.CognitoUser, tableName string, emailsTable string, userPoolID string, dynaClient dynamodbiface.DynamoDBAPI,
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI, reconciler Reconciler) error {
+	createInput := &cognitoidentityprovider.AdminCreateUserInput{
+		DesiredDeliveryMediums: []*string{
+			aws.String("EMAIL"),
+		},
+		ForceAliasCreation: aws.Bool(false),
+		UserAttributes: []*cognitoidentityprovider.AttributeType{
+			{
+				Name:  aws.String("name"),
+				Value: aws.String(user.FirstName + user.LastName),
+			},
+			{
+				Name:  aws.String("given_name"),
+				Value: aws.String(user.User_ID),
+			},
+			{
+				Name:  aws.String("email_verified"),
+				Value: aws.String("True"),
+			},
+			{
+				Name:  aws.String("email"),
+				Value: aws.String(user.Email),
+			},
+			{
+				Name:  aws.String("custom:role"),
+				Value: aws.String(user.Role),
+			},
+		},
+		UserPoolId: aws.String(userPoolID),
+		Username:   aws.String(user.User_ID),
+	}
+
+	saga := NewSaga("create-user", user.User_ID, reconciler)
+	saga.AddStep("dynamo-put-user", func() error {
+		return PutUserItem(*user.User, nil, tableName, emailsTable, dynaClient)
+	}, func() error {
+		return DeleteUserItem(*user.User, tableName, emailsTable, dynaClient)
+	})
+	saga.AddStep("cognito-create-user", func() error {
+		_, createErr := cognitoClient.AdminCreateUser(createInput)
+		if createErr != nil {
+			//the email may still be held by a cognito user the users table no longer has
+			var aliasExists *cognitoidentityprovider.AliasExistsException
+			var usernameExists *cognitoidentityprovider.UsernameExistsException
+			if errors.As(createErr, &aliasExists) || errors.As(createErr, &usernameExists) {
+				return types.ErrorEmailAlreadyExists
+			}
+			log.Println(createErr)
+			return types.ErrorCognitoActionFailed
+		}
+		return nil
+	}, func() error {
+		_, err := cognitoClient.AdminDeleteUser(&cognitoidentityprovider.AdminDeleteUserInput{
+			Username:   aws.String(user.User_ID),
+			UserPoolId: aws.String(userPoolID),
+		})
+		return err
+	})
+	if user.Password != "" {
+		saga.AddStep("cognito-set-password", func() error {
+			_, passwdErr := cognitoClient.AdminSetUserPassword(&cognitoidentityprovider.AdminSetUserPasswordInput{
+				Password:   aws.String(user.Password),
+				Permanent:  aws.Bool(true),
+				Username:   aws.String(user.User_ID),
+				UserPoolId: aws.String(userPoolID),
+			})
+			if passwdErr != nil {
+				var invalid *cognitoidentityprovider.InvalidPasswordException
+				if errors.As(passwdErr, &invalid) {
+					return types.ErrorInvalidPassword
+				}
+				log.Println(passwdErr)
+				return types.ErrorCognitoActionFailed
+			}
+			return nil
+		}, nil)
+	}
+
+	return saga.Run()
+}
+
+// FetchAllEmails returns the lower cased email of every user in the table.
+func FetchAllEmails(tableName string, dynaClient dynamodbiface.DynamoDBAPI) (map[string]bool, error) {
+	input := &dynamodb.ScanInput{
+		TableName:            aws.String(tableName),
+		ProjectionExpression: aws.String("email"),
+	}
+
+	emails := map[string]bool{}
+	for {
+		result, err := dynaClient.Scan(input)
+		if err != nil {
+			return nil, types.ErrorFailedToFetchRecord
+		}
+
+		page := []types.User{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, types.ErrorFailedToUnmarshalRecord
+		}
+		for _, user := range page {
+			emails[EmailKey(user.Email)] = true
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return emails, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// EmailVerification asks ses to verify the address so the user can be sent
+// notifications.
+func EmailVerification(emailAddress string, sesClient sesiface.SESAPI) error {
+	_, err := sesClient.VerifyEmailIdentity(&ses.VerifyEmailIdentityInput{
+		EmailAddress: aws.String(emailAddress),
+	})
+	if err != nil {
+		log.Println("Failed to verify email identity", err)
+		return err
+	}
+	log.Println("Verification request sent to", emailAddress)
+	return nil
+}
@@ -0,0 +1,234 @@
+package utility
+
+import (
+	"ascenda/types"
+	"slices"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// FetchRole returns the stored role, or nil if it does not exist.
+func FetchRole(role, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.Role, error) {
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"role": {
+				S: aws.String(role),
+			},
+		},
+		TableName: aws.String(tableName),
+	}
+
+	result, err := dynaClient.GetItem(input)
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	item := new(types.Role)
+	err = dynamodbattribute.UnmarshalMap(result.Item, item)
+	if err != nil {
+		return nil, types.ErrorFailedToUnmarshalRecord
+	}
+
+	return item, nil
+}
+
+// FetchInheritingRoles returns the names of the roles that inherit directly
+// from role.
+func FetchInheritingRoles(role, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]string, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(tableName),
+		FilterExpression: aws.String("contains(inherits, :role)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":role": {S: aws.String(role)},
+		},
+	}
+
+	var names []string
+	for {
+		result, err := dynaClient.Scan(input)
+		if err != nil {
+			return nil, types.ErrorFailedToFetchRecord
+		}
+
+		page := []types.Role{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, types.ErrorFailedToUnmarshalRecord
+		}
+		for _, inheriting := range page {
+			names = append(names, inheriting.Role)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return names, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ValidateRoleInheritance checks that every role the given role inherits from
+// exists and that saving it would not introduce an inheritance cycle.
+func ValidateRoleInheritance(role types.Role, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	//the role being saved replaces whatever is stored under its name
+	roles := map[string]*types.Role{role.Role: &role}
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		if slices.Contains(path, name) {
+			return types.ErrorRoleInheritanceCycle
+		}
+
+		current, ok := roles[name]
+		if !ok {
+			fetched, err := FetchRole(name, tableName, dynaClient)
+			if err != nil {
+				return err
+			}
+			if fetched == nil {
+				return types.ErrorParentRoleDoesNotExist
+			}
+			roles[name] = fetched
+			current = fetched
+		}
+
+		for _, parent := range current.Inherits {
+			if err := visit(parent, append(path, name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return visit(role.Role, nil)
+}
+
+// MarshalRole returns the item a role is stored as. Empty lists and maps are
+// kept rather than stored as null, so a policy field set to [] still reads
+// back as specified.
+func MarshalRole(role types.Role) (map[string]*dynamodb.AttributeValue, error) {
+	encoder := dynamodbattribute.NewEncoder(func(e *dynamodbattribute.Encoder) {
+		e.EnableEmptyCollections = true
+	})
+	av, err := encoder.Encode(role)
+	if err != nil {
+		return nil, err
+	}
+	return av.M, nil
+}
+
+// EffectiveRole returns the role with the access, deny entries and policy of
+// every role it inherits from merged in. A role closer to the requested one
+// takes precedence over those it inherits from:
+//
+//   - a policy field the role sets, even to an empty and so unrestricted list,
+//     is kept, and only unset fields are filled from the inherited policies.
+//     A max_points_change of 0 counts as unset.
+//   - an inherited deny entry is dropped for the methods a closer role grants
+//     under the same route in its own access, while a role's own deny entries
+//     still override the access it inherits.
+//
+// Inherited roles that no longer exist are skipped, as are roles already
+// visited, so a cycle stored before validation existed cannot loop forever.
+func EffectiveRole(role, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (*types.Role, error) {
+	base, err := FetchRole(role, tableName, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return nil, types.ErrorRoleDoesNotExist
+	}
+
+	effective := &types.Role{
+		Role:     base.Role,
+		Inherits: base.Inherits,
+		Access:   map[string][]string{},
+		Deny:     map[string][]string{},
+	}
+
+	visited := map[string]bool{}
+	//granted is the own access of the roles between base and current
+	var merge func(current *types.Role, granted map[string][]string) error
+	merge = func(current *types.Role, granted map[string][]string) error {
+		if visited[current.Role] {
+			return nil
+		}
+		visited[current.Role] = true
+
+		mergeAccess(effective.Access, current.Access)
+		mergeAccess(effective.Deny, withoutGranted(current.Deny, granted))
+		mergePolicy(&effective.Policy, current.Policy)
+
+		closer := map[string][]string{}
+		mergeAccess(closer, granted)
+		mergeAccess(closer, current.Access)
+		for _, name := range current.Inherits {
+			parent, err := FetchRole(name, tableName, dynaClient)
+			if err != nil {
+				return err
+			}
+			if parent == nil {
+				continue
+			}
+			if err := merge(parent, closer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := merge(base, nil); err != nil {
+		return nil, err
+	}
+	return effective, nil
+}
+
+func mergeAccess(dst, src map[string][]string) {
+	for route, methods := range src {
+		for _, method := range methods {
+			if !slices.Contains(dst[route], method) {
+				dst[route] = append(dst[route], method)
+			}
+		}
+	}
+}
+
+// roleMethods are the methods role access lists besides "*", as validated by
+// validation.Role.
+var roleMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// withoutGranted returns the deny entries with the methods granted lists under
+// the same route removed. A "*" entry is narrowed to the methods not granted.
+func withoutGranted(deny, granted map[string][]string) map[string][]string {
+	remaining := map[string][]string{}
+	for route, methods := range deny {
+		if len(granted[route]) > 0 && slices.Contains(methods, wildcard) {
+			methods = roleMethods
+		}
+		for _, method := range methods {
+			if !matchMethod(granted[route], method) {
+				remaining[route] = append(remaining[route], method)
+			}
+		}
+	}
+	return remaining
+}
+
+// mergePolicy fills the fields dst leaves unset from src. A nil list is unset,
+// an empty one places no restriction and is kept.
+func mergePolicy(dst *types.Policy, src types.Policy) {
+	if dst.UserFields == nil {
+		dst.UserFields = src.UserFields
+	}
+	if dst.MaxPointsChange == 0 {
+		dst.MaxPointsChange = src.MaxPointsChange
+	}
+	if dst.TargetRoles == nil {
+		dst.TargetRoles = src.TargetRoles
+	}
+}
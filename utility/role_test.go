@@ -0,0 +1,163 @@
+package utility
+
+import (
+	"ascenda/types"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// roleTable serves GetItem from an in-memory set of roles.
+type roleTable struct {
+	dynamodbiface.DynamoDBAPI
+	roles map[string]types.Role
+}
+
+func (t *roleTable) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	role, ok := t.roles[*input.Key["role"].S]
+	if !ok {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	item, err := MarshalRole(role)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.GetItemOutput{Item: item}, nil
+}
+
+func newRoleTable(roles ...types.Role) *roleTable {
+	table := &roleTable{roles: map[string]types.Role{}}
+	for _, role := range roles {
+		table.roles[role.Role] = role
+	}
+	return table
+}
+
+func TestValidateRoleInheritance(t *testing.T) {
+	table := newRoleTable(
+		types.Role{Role: "viewer"},
+		types.Role{Role: "support", Inherits: []string{"viewer"}},
+		types.Role{Role: "admin", Inherits: []string{"support"}},
+	)
+
+	tests := []struct {
+		name    string
+		role    types.Role
+		wantErr error
+	}{
+		{"no parents", types.Role{Role: "guest"}, nil},
+		{"existing chain", types.Role{Role: "owner", Inherits: []string{"admin", "viewer"}}, nil},
+		{"missing parent", types.Role{Role: "owner", Inherits: []string{"ghost"}}, types.ErrorParentRoleDoesNotExist},
+		{"self inheritance", types.Role{Role: "guest", Inherits: []string{"guest"}}, types.ErrorRoleInheritanceCycle},
+		{"indirect cycle on update", types.Role{Role: "viewer", Inherits: []string{"admin"}}, types.ErrorRoleInheritanceCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRoleInheritance(tt.role, "roles", table)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("ValidateRoleInheritance() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateRoleInheritance() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEffectiveRole(t *testing.T) {
+	table := newRoleTable(
+		types.Role{
+			Role:   "viewer",
+			Access: map[string][]string{"/users": {"GET"}, "/logs": {"GET"}},
+			Policy: types.Policy{TargetRoles: []string{"customer"}, MaxPointsChange: 100},
+		},
+		types.Role{
+			Role:     "support",
+			Inherits: []string{"viewer", "missing"},
+			Access:   map[string][]string{"/users": {"PUT"}},
+			Deny:     map[string][]string{"/logs": {"GET"}},
+			Policy:   types.Policy{MaxPointsChange: 500},
+		},
+		//a stored cycle must not loop
+		types.Role{Role: "a", Inherits: []string{"b"}, Access: map[string][]string{"/a": {"GET"}}},
+		types.Role{Role: "b", Inherits: []string{"a"}, Access: map[string][]string{"/b": {"GET"}}},
+	)
+
+	role, err := EffectiveRole("support", "roles", table)
+	if err != nil {
+		t.Fatalf("EffectiveRole() error = %v", err)
+	}
+	if !slices.Equal(role.Access["/users"], []string{"PUT", "GET"}) {
+		t.Errorf("merged /users access = %v", role.Access["/users"])
+	}
+	if !IsAccessAllowed(role, "/users", "GET") || IsAccessAllowed(role, "/logs", "GET") {
+		t.Error("effective role did not combine inherited access with own deny entries")
+	}
+	if role.Policy.MaxPointsChange != 500 || !slices.Equal(role.Policy.TargetRoles, []string{"customer"}) {
+		t.Errorf("merged policy = %+v", role.Policy)
+	}
+
+	cyclic, err := EffectiveRole("a", "roles", table)
+	if err != nil {
+		t.Fatalf("EffectiveRole() error = %v", err)
+	}
+	if !IsAccessAllowed(cyclic, "/b", "GET") {
+		t.Error("cyclic role did not inherit access")
+	}
+
+	if _, err := EffectiveRole("ghost", "roles", table); !errors.Is(err, types.ErrorRoleDoesNotExist) {
+		t.Errorf("EffectiveRole() of missing role error = %v", err)
+	}
+}
+
+func TestEffectiveRolePrecedence(t *testing.T) {
+	table := newRoleTable(
+		types.Role{
+			Role:   "viewer",
+			Access: map[string][]string{"/users": {"GET"}, "/logs": {"*"}},
+			Deny:   map[string][]string{"/logs": {"*"}, "/users/sessions": {"GET"}},
+			Policy: types.Policy{UserFields: []string{"first_name"}, TargetRoles: []string{"customer"}},
+		},
+		types.Role{
+			Role:     "auditor",
+			Inherits: []string{"viewer"},
+			Access:   map[string][]string{"/logs": {"GET"}},
+			Policy:   types.Policy{TargetRoles: []string{}},
+		},
+		types.Role{
+			Role:     "lead",
+			Inherits: []string{"auditor"},
+			Access:   map[string][]string{"/users/sessions": {"*"}},
+		},
+	)
+
+	role, err := EffectiveRole("lead", "roles", table)
+	if err != nil {
+		t.Fatalf("EffectiveRole() error = %v", err)
+	}
+	if role.Policy.TargetRoles == nil || len(role.Policy.TargetRoles) != 0 {
+		t.Errorf("target_roles = %#v, want the empty list auditor set", role.Policy.TargetRoles)
+	}
+	if !slices.Equal(role.Policy.UserFields, []string{"first_name"}) {
+		t.Errorf("user_fields = %v, want the list inherited from viewer", role.Policy.UserFields)
+	}
+
+	tests := []struct {
+		route, method string
+		want          bool
+	}{
+		{"/logs", "GET", true},
+		{"/logs", "DELETE", false},
+		{"/users/sessions", "GET", true},
+		{"/users", "GET", true},
+	}
+	for _, tt := range tests {
+		if got := IsAccessAllowed(role, tt.route, tt.method); got != tt.want {
+			t.Errorf("IsAccessAllowed(%s %s) = %v, want %v", tt.method, tt.route, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package utility
+
+import (
+	"ascenda/types"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// Reconciler receives the inconsistencies a saga could not compensate.
+type Reconciler interface {
+	Enqueue(item types.ReconciliationItem) error
+}
+
+// SQSReconciler sends reconciliation items to an SQS queue.
+type SQSReconciler struct {
+	Client   sqsiface.SQSAPI
+	QueueURL string
+}
+
+func (r *SQSReconciler) Enqueue(item types.ReconciliationItem) error {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return types.ErrorCouldNotMarshalItem
+	}
+
+	_, err = r.Client.SendMessage(&sqs.SendMessageInput{
+		MessageBody: aws.String(string(body)),
+		QueueUrl:    aws.String(r.QueueURL),
+	})
+	return err
+}
+
+// SagaStep is one action of a saga together with the compensation that undoes
+// it. Compensate may be nil for a step that has nothing to undo, which is usually
+// the last one.
+type SagaStep struct {
+	Name       string
+	Action     func() error
+	Compensate func() error
+}
+
+// Saga runs a sequence of steps across services that cannot share a
+// transaction, undoing the completed steps when a later one fails.
+type Saga struct {
+	Name       string
+	ResourceID string
+	Reconciler Reconciler
+	steps      []SagaStep
+}
+
+func NewSaga(name, resourceID string, reconciler Reconciler) *Saga {
+	return &Saga{
+		Name:       name,
+		ResourceID: resourceID,
+		Reconciler: reconciler,
+	}
+}
+
+// AddStep appends a step to the saga.
+func (s *Saga) AddStep(name string, action, compensate func() error) {
+	s.steps = append(s.steps, SagaStep{Name: name, Action: action, Compensate: compensate})
+}
+
+// Run executes the steps in order. When a step fails the compensations of the
+// completed steps run in reverse order and the step's error is returned as is.
+// Compensations that fail are sent to the reconciler.
+func (s *Saga) Run() error {
+	for i, step := range s.steps {
+		err := step.Action()
+		if err == nil {
+			continue
+		}
+
+		log.Printf("saga %s failed at %s for %s: %v", s.Name, step.Name, s.ResourceID, err)
+		uncompensated := s.compensate(i)
+		if len(uncompensated) > 0 {
+			s.reconcile(step.Name, err, uncompensated)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Saga) compensate(failed int) []string {
+	var uncompensated []string
+	for i := failed - 1; i >= 0; i-- {
+		step := s.steps[i]
+		if step.Compensate == nil {
+			continue
+		}
+		if err := step.Compensate(); err != nil {
+			log.Printf("saga %s could not compensate %s for %s: %v", s.Name, step.Name, s.ResourceID, err)
+			uncompensated = append(uncompensated, step.Name)
+		}
+	}
+	return uncompensated
+}
+
+func (s *Saga) reconcile(failedStep string, reason error, uncompensated []string) {
+	item := types.ReconciliationItem{
+		Saga:          s.Name,
+		ResourceID:    s.ResourceID,
+		FailedStep:    failedStep,
+		Reason:        reason.Error(),
+		Uncompensated: uncompensated,
+		Timestamp:     time.Now().Unix(),
+	}
+
+	if s.Reconciler == nil {
+		log.Printf("saga %s has no reconciler, dropping %+v", s.Name, item)
+		return
+	}
+	if err := s.Reconciler.Enqueue(item); err != nil {
+		log.Printf("saga %s could not enqueue reconciliation for %s: %v", s.Name, s.ResourceID, err)
+	}
+}
@@ -0,0 +1,98 @@
+package utility
+
+import (
+	"ascenda/types"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type recordingReconciler struct {
+	items []types.ReconciliationItem
+}
+
+func (r *recordingReconciler) Enqueue(item types.ReconciliationItem) error {
+	r.items = append(r.items, item)
+	return nil
+}
+
+func TestSagaRun(t *testing.T) {
+	failure := errors.New("step failed")
+
+	tests := []struct {
+		name              string
+		failAt            string
+		failCompensation  string
+		wantErr           error
+		wantCalls         []string
+		wantUncompensated []string
+	}{
+		{
+			name:      "all steps succeed",
+			wantCalls: []string{"a", "b", "c"},
+		},
+		{
+			name:      "first step fails",
+			failAt:    "a",
+			wantErr:   failure,
+			wantCalls: []string{"a"},
+		},
+		{
+			name:      "later step compensates in reverse",
+			failAt:    "c",
+			wantErr:   failure,
+			wantCalls: []string{"a", "b", "c", "undo-b", "undo-a"},
+		},
+		{
+			name:              "failed compensation is reconciled",
+			failAt:            "c",
+			failCompensation:  "undo-b",
+			wantErr:           failure,
+			wantCalls:         []string{"a", "b", "c", "undo-b", "undo-a"},
+			wantUncompensated: []string{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			step := func(name string) func() error {
+				return func() error {
+					calls = append(calls, name)
+					if name == tt.failAt || name == tt.failCompensation {
+						return failure
+					}
+					return nil
+				}
+			}
+
+			reconciler := &recordingReconciler{}
+			saga := NewSaga("test", "resource-1", reconciler)
+			saga.AddStep("a", step("a"), step("undo-a"))
+			saga.AddStep("b", step("b"), step("undo-b"))
+			saga.AddStep("c", step("c"), nil)
+
+			if err := saga.Run(); err != tt.wantErr {
+				t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+
+			if len(tt.wantUncompensated) == 0 {
+				if len(reconciler.items) != 0 {
+					t.Errorf("unexpected reconciliation items %+v", reconciler.items)
+				}
+				return
+			}
+			if len(reconciler.items) != 1 {
+				t.Fatalf("got %d reconciliation items, want 1", len(reconciler.items))
+			}
+			item := reconciler.items[0]
+			if item.Saga != "test" || item.ResourceID != "resource-1" || item.FailedStep != tt.failAt ||
+				!slices.Equal(item.Uncompensated, tt.wantUncompensated) {
+				t.Errorf("reconciliation item = %+v", item)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package utility
+
+import (
+	"ascenda/types"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// SignInHistorySize is the number of recent events new sign ins are compared
+// against.
+const SignInHistorySize = 50
+
+// FailureWindow bounds how far back failed attempts count towards
+// repeated_failures.
+const FailureWindow = 15 * time.Minute
+
+// FetchSignInEvents returns a page of the user's sign in events, newest first.
+// key is the timestamp of the last event of the previous page.
+func FetchSignInEvents(userID, key string, limit int64, flaggedOnly bool, tableName string,
+	dynaClient dynamodbiface.DynamoDBAPI) (*types.ReturnSignInData, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user_id": {S: aws.String(userID)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(limit),
+	}
+	if flaggedOnly {
+		input.FilterExpression = aws.String("attribute_exists(flags)")
+	}
+	if len(key) != 0 {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			"user_id":   {S: aws.String(userID)},
+			"timestamp": {N: aws.String(key)},
+		}
+	}
+
+	result, err := dynaClient.Query(input)
+	if err != nil {
+		return nil, types.ErrorCouldNotQueryDB
+	}
+
+	res := &types.ReturnSignInData{Data: []types.SignInEvent{}}
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &res.Data); err != nil {
+		return nil, types.ErrorFailedToUnmarshal
+	}
+	if last, ok := result.LastEvaluatedKey["timestamp"]; ok {
+		res.Key = *last.N
+	}
+	return res, nil
+}
+
+// SaveSignInEvent stores the event, expiring it ttlDays from now.
+func SaveSignInEvent(event types.SignInEvent, ttlDays int, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	event.TTL = time.Now().AddDate(0, 0, ttlDays).Unix()
+	av, err := dynamodbattribute.MarshalMap(event)
+	if err != nil {
+		return types.ErrorCouldNotMarshalItem
+	}
+
+	_, err = dynaClient.PutItem(&dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return types.ErrorCouldNotDynamoPutItem
+	}
+	return nil
+}
+
+// FlagSignIn returns the unusual patterns of the event given the user's
+// history, newest first. Successes are compared with earlier successes by the
+// IP cognito saw, the first sign in of a user is never flagged. Attempts are flagged once
+// failureThreshold attempts without a success precede them in FailureWindow.
+func FlagSignIn(event types.SignInEvent, history []types.SignInEvent, newDevice bool, failureThreshold int) []string {
+	var flags []string
+	if event.Outcome == types.SignInAttempt {
+		failures := 0
+		since := event.Timestamp - FailureWindow.Milliseconds()
+		for _, previous := range history {
+			if previous.Outcome == types.SignInSuccess || previous.Timestamp < since {
+				break
+			}
+			failures++
+		}
+		if failures >= failureThreshold {
+			flags = append(flags, types.SignInFlagRepeatedFailures)
+		}
+		return flags
+	}
+
+	seenIP, signedIn := false, false
+	for _, previous := range history {
+		if previous.Outcome != types.SignInSuccess {
+			continue
+		}
+		signedIn = true
+		seenIP = seenIP || previous.IP == event.IP
+	}
+	if !signedIn {
+		return flags
+	}
+	if !seenIP && event.IP != "" {
+		flags = append(flags, types.SignInFlagNewIP)
+	}
+	if newDevice {
+		flags = append(flags, types.SignInFlagNewDevice)
+	}
+	return flags
+}
@@ -0,0 +1,49 @@
+package utility
+
+import (
+	"ascenda/types"
+	"reflect"
+	"testing"
+)
+
+func TestFlagSignIn(t *testing.T) {
+	const now = int64(1700000000000)
+	success := func(ip string) types.SignInEvent {
+		return types.SignInEvent{Outcome: types.SignInSuccess, Timestamp: now - 60000, IP: ip}
+	}
+	attempt := func(age int64) types.SignInEvent {
+		return types.SignInEvent{Outcome: types.SignInAttempt, Timestamp: now - age}
+	}
+
+	tests := []struct {
+		name      string
+		event     types.SignInEvent
+		history   []types.SignInEvent
+		newDevice bool
+		want      []string
+	}{
+		{"first sign in", types.SignInEvent{Outcome: types.SignInSuccess, IP: "1.1.1.1"}, nil, true, nil},
+		{"known ip", types.SignInEvent{Outcome: types.SignInSuccess, IP: "1.1.1.1"},
+			[]types.SignInEvent{success("2.2.2.2"), success("1.1.1.1")}, false, nil},
+		{"new ip", types.SignInEvent{Outcome: types.SignInSuccess, IP: "3.3.3.3"},
+			[]types.SignInEvent{success("1.1.1.1")}, true, []string{types.SignInFlagNewIP, types.SignInFlagNewDevice}},
+		{"client reported ip ignored", types.SignInEvent{Outcome: types.SignInSuccess,
+			Client: &types.ClientReport{IP: "3.3.3.3", Country: "US"}}, []types.SignInEvent{success("1.1.1.1")}, false, nil},
+		{"failures below threshold", types.SignInEvent{Outcome: types.SignInAttempt, Timestamp: now},
+			[]types.SignInEvent{attempt(1000), attempt(2000)}, false, nil},
+		{"repeated failures", types.SignInEvent{Outcome: types.SignInAttempt, Timestamp: now},
+			[]types.SignInEvent{attempt(1000), attempt(2000), attempt(3000)}, false, []string{types.SignInFlagRepeatedFailures}},
+		{"failures before last success", types.SignInEvent{Outcome: types.SignInAttempt, Timestamp: now},
+			[]types.SignInEvent{attempt(1000), success("1.1.1.1"), attempt(2000), attempt(3000)}, false, nil},
+		{"failures outside window", types.SignInEvent{Outcome: types.SignInAttempt, Timestamp: now},
+			[]types.SignInEvent{attempt(1000), attempt(2000), attempt(FailureWindow.Milliseconds() + 1)}, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FlagSignIn(tt.event, tt.history, tt.newDevice, 3); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FlagSignIn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package utility
+
+import (
+	"ascenda/types"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// ApplyUserPatch returns the user with the supplied fields of the patch set.
+func ApplyUserPatch(user types.User, patch types.UserPatch) types.User {
+	if patch.Email != nil {
+		user.Email = strings.TrimSpace(*patch.Email)
+	}
+	if patch.FirstName != nil {
+		user.FirstName = strings.TrimSpace(*patch.FirstName)
+	}
+	if patch.LastName != nil {
+		user.LastName = strings.TrimSpace(*patch.LastName)
+	}
+	if patch.Role != nil {
+		user.Role = strings.TrimSpace(*patch.Role)
+	}
+	return user
+}
+
+// userFieldValues maps the json names of the user's editable fields to their values.
+func userFieldValues(user types.User) map[string]string {
+	return map[string]string{
+		"email":      user.Email,
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+		"role":       user.Role,
+	}
+}
+
+// BuildUpdateExpression generates a SET expression for the attributes, with
+// placeholders for every name and value so reserved words need no care.
+func BuildUpdateExpression(values map[string]*dynamodb.AttributeValue) (string, map[string]*string, map[string]*dynamodb.AttributeValue) {
+	attributes := make([]string, 0, len(values))
+	for attribute := range values {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+
+	names := map[string]*string{}
+	placeholders := map[string]*dynamodb.AttributeValue{}
+	sets := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		names["#"+attribute] = aws.String(attribute)
+		placeholders[":"+attribute] = values[attribute]
+		sets = append(sets, "#"+attribute+" = :"+attribute)
+	}
+
+	return "SET " + strings.Join(sets, ", "), names, placeholders
+}
+
+// UpdateUserItem writes the fields that differ between current and updated to
+// the users table, moving the email reservation when the email changes. It
+// fails with ErrorEmailAlreadyExists when another user holds the new email,
+// and with ErrorRecordChanged when the user was deleted meanwhile.
+func UpdateUserItem(current, updated types.User, tableName, emailsTable string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	fields := ChangedUserFields(current, updated)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	values := userFieldValues(updated)
+	changed := map[string]*dynamodb.AttributeValue{}
+	for _, field := range fields {
+		changed[field] = &dynamodb.AttributeValue{S: aws.String(values[field])}
+	}
+	expression, names, placeholders := BuildUpdateExpression(changed)
+
+	items := []*dynamodb.TransactWriteItem{{Update: &dynamodb.Update{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {S: aws.String(updated.User_ID)},
+		},
+		TableName:                 aws.String(tableName),
+		UpdateExpression:          aws.String(expression),
+		ConditionExpression:       aws.String("attribute_exists(user_id)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: placeholders,
+	}}}
+
+	guards, err := moveEmail(current, updated, emailsTable, dynaClient)
+	if err != nil {
+		return err
+	}
+	items = append(items, guards...)
+
+	_, err = dynaClient.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		if isEmailConflict(err) {
+			return types.ErrorEmailAlreadyExists
+		}
+		if isConditionFailed(err) {
+			return types.ErrorRecordChanged
+		}
+		return types.ErrorCouldNotDynamoPutItem
+	}
+	return nil
+}
+
+// SaveUserChanges writes the changes from current to updated to dynamo then
+// cognito, restoring the stored fields if cognito fails.
+func SaveUserChanges(current, updated types.User, tableName, emailsTable, userPoolID string, dynaClient dynamodbiface.DynamoDBAPI,
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI, reconciler Reconciler) error {
+	saga := NewSaga("update-user", updated.User_ID, reconciler)
+	saga.AddStep("dynamo-update-user", func() error {
+		return UpdateUserItem(current, updated, tableName, emailsTable, dynaClient)
+	}, func() error {
+		return UpdateUserItem(updated, current, tableName, emailsTable, dynaClient)
+	})
+	if attributes := CognitoAttributes(current, updated); len(attributes) > 0 {
+		saga.AddStep("cognito-update-user", func() error {
+			_, cognitoErr := cognitoClient.AdminUpdateUserAttributes(&cognitoidentityprovider.AdminUpdateUserAttributesInput{
+				UserAttributes: attributes,
+				UserPoolId:     aws.String(userPoolID),
+				Username:       aws.String(updated.User_ID),
+			})
+			if cognitoErr != nil {
+				//the new email may still be held by a cognito user the users table no longer has
+				var aliasExists *cognitoidentityprovider.AliasExistsException
+				if errors.As(cognitoErr, &aliasExists) {
+					return types.ErrorEmailAlreadyExists
+				}
+				log.Println(cognitoErr)
+				return types.ErrorCognitoActionFailed
+			}
+			return nil
+		}, nil)
+	}
+
+	return saga.Run()
+}
+
+// CognitoAttributes returns the cognito attributes that change between the
+// stored and the updated user.
+func CognitoAttributes(current, user types.User) []*cognitoidentityprovider.AttributeType {
+	var attributes []*cognitoidentityprovider.AttributeType
+	if current.FirstName+current.LastName != user.FirstName+user.LastName {
+		attributes = append(attributes, &cognitoidentityprovider.AttributeType{
+			Name:  aws.String("name"),
+			Value: aws.String(user.FirstName + user.LastName),
+		})
+	}
+	if current.Email != user.Email {
+		attributes = append(attributes, &cognitoidentityprovider.AttributeType{
+			Name:  aws.String("email"),
+			Value: aws.String(user.Email),
+		})
+	}
+	if current.Role != user.Role {
+		attributes = append(attributes, &cognitoidentityprovider.AttributeType{
+			Name:  aws.String("custom:role"),
+			Value: aws.String(user.Role),
+		})
+	}
+	return attributes
+}
@@ -0,0 +1,385 @@
+package utility
+
+import (
+	"ascenda/types"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// RoleExists reports whether the role is defined in the roles table.
+func RoleExists(role, tableName string, dynaClient dynamodbiface.DynamoDBAPI) (bool, error) {
+	item, err := FetchRole(role, tableName, dynaClient)
+	if err != nil {
+		return false, err
+	}
+	return item != nil, nil
+}
+
+// FetchAllUsersByRole returns every user holding the role, following the
+// role-index pagination to the end.
+func FetchAllUsersByRole(role, tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.User, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		IndexName:              aws.String("role-index"),
+		KeyConditionExpression: aws.String("#role = :role"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":role": {S: aws.String(role)},
+		},
+		ExpressionAttributeNames: map[string]*string{
+			"#role": aws.String("role"),
+		},
+	}
+
+	users := []types.User{}
+	for {
+		result, err := dynaClient.Query(input)
+		if err != nil {
+			return nil, types.ErrorCouldNotQueryDB
+		}
+
+		page := []types.User{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, types.ErrorFailedToUnmarshalRecord
+		}
+		users = append(users, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return users, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ScanAllUsers reads every item of the users table.
+func ScanAllUsers(tableName string, dynaClient dynamodbiface.DynamoDBAPI) ([]types.User, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+	}
+
+	users := []types.User{}
+	for {
+		result, err := dynaClient.Scan(input)
+		if err != nil {
+			return nil, types.ErrorFailedToFetchRecord
+		}
+
+		page := []types.User{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, types.ErrorFailedToUnmarshalRecord
+		}
+		users = append(users, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return users, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ReassignUserRole moves the user to role in both the users table and the
+// user's custom:role attribute in Cognito. The table is only changed while the
+// user still holds their old role, and is moved back if Cognito fails.
+func ReassignUserRole(user types.User, role, tableName, userPoolID string, dynaClient dynamodbiface.DynamoDBAPI,
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI, reconciler Reconciler) error {
+	saga := NewSaga("reassign-role", user.User_ID, reconciler)
+	saga.AddStep("dynamo-update-role", func() error {
+		return updateUserRole(user.User_ID, user.Role, role, tableName, dynaClient)
+	}, func() error {
+		return updateUserRole(user.User_ID, role, user.Role, tableName, dynaClient)
+	})
+	saga.AddStep("cognito-update-role", func() error {
+		_, err := cognitoClient.AdminUpdateUserAttributes(&cognitoidentityprovider.AdminUpdateUserAttributesInput{
+			UserAttributes: []*cognitoidentityprovider.AttributeType{
+				{
+					Name:  aws.String("custom:role"),
+					Value: aws.String(role),
+				},
+			},
+			UserPoolId: aws.String(userPoolID),
+			Username:   aws.String(user.User_ID),
+		})
+		if err != nil {
+			log.Println(err)
+			return types.ErrorCognitoActionFailed
+		}
+		return nil
+	}, nil)
+
+	return saga.Run()
+}
+
+// updateUserRole moves the user from one role to another, failing with
+// ErrorRecordChanged if they no longer hold from.
+func updateUserRole(userID, from, to, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	input := &dynamodb.UpdateItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(userID),
+			},
+		},
+		TableName:           aws.String(tableName),
+		UpdateExpression:    aws.String("SET #role = :to"),
+		ConditionExpression: aws.String("#role = :from"),
+		ExpressionAttributeNames: map[string]*string{
+			"#role": aws.String("role"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":from": {S: aws.String(from)},
+			":to":   {S: aws.String(to)},
+		},
+	}
+	_, err := dynaClient.UpdateItem(input)
+	if isConditionFailed(err) {
+		return types.ErrorRecordChanged
+	}
+	if err != nil {
+		return types.ErrorCouldNotDynamoPutItem
+	}
+	return nil
+}
+
+// SystemCheckerID marks maker requests rejected automatically rather than by a checker.
+const SystemCheckerID = "system"
+
+// HardDeleteUser irreversibly removes the user from dynamo and cognito and
+// releases the user's email. The user's points accounts are zeroed and closed,
+// or deleted when deletePoints is set, and pending maker requests targeting the
+// user are rejected. The points accounts are returned with their former
+// balances for logging.
+func HardDeleteUser(user types.User, deletePoints bool, tableName, emailsTable, pointsTable, makerTable, userPoolID string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	reconciler Reconciler) ([]types.UserPoint, error) {
+	//gathering points accounts and pending maker requests targeting the user
+	points, err := FetchPointsByUser(user.User_ID, pointsTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+	pendingRequests, err := FetchRequestsForUser(user.User_ID, "pending", makerTable, dynaClient)
+	if err != nil {
+		return nil, err
+	}
+
+	//close points and reject maker requests before removing the user, delete from
+	//dynamo before cognito so a failed cognito delete can restore the item,
+	//cognito deletion cannot be undone and so runs last
+	saga := NewSaga("delete-user", user.User_ID, reconciler)
+	saga.AddStep("close-points", func() error {
+		return ClosePointsAccounts(points, deletePoints, pointsTable, dynaClient)
+	}, func() error {
+		return RestorePointsAccounts(points, pointsTable, dynaClient)
+	})
+	saga.AddStep("reject-maker-requests", func() error {
+		return SetMakerRequestsStatus(pendingRequests, "rejected", SystemCheckerID, makerTable, dynaClient)
+	}, func() error {
+		return SetMakerRequestsStatus(pendingRequests, "pending", "", makerTable, dynaClient)
+	})
+	saga.AddStep("dynamo-delete-user", func() error {
+		return DeleteUserItem(user, tableName, emailsTable, dynaClient)
+	}, func() error {
+		return PutUserItem(user, nil, tableName, emailsTable, dynaClient)
+	})
+	saga.AddStep("cognito-delete-user", func() error {
+		_, cognitoErr := cognitoClient.AdminDeleteUser(&cognitoidentityprovider.AdminDeleteUserInput{
+			Username:   aws.String(user.User_ID),
+			UserPoolId: aws.String(userPoolID),
+		})
+		if cognitoErr != nil {
+			log.Println(cognitoErr)
+			return types.ErrorCognitoActionFailed
+		}
+		return nil
+	}, nil)
+
+	if err := saga.Run(); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// SoftDeleteUser marks the user deleted and disables their cognito login. The
+// user's open points accounts are frozen and pending maker requests targeting
+// the user are rejected, so their balances cannot change until they are
+// restored or purged.
+func SoftDeleteUser(user types.User, deletedAt int64, tableName, pointsTable, makerTable, userPoolID string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	reconciler Reconciler) error {
+	points, err := FetchPointsByUser(user.User_ID, pointsTable, dynaClient)
+	if err != nil {
+		return err
+	}
+	openPoints := PointsWithStatus(points, "", types.PointsStatusActive)
+	pendingRequests, err := FetchRequestsForUser(user.User_ID, "pending", makerTable, dynaClient)
+	if err != nil {
+		return err
+	}
+
+	saga := NewSaga("soft-delete-user", user.User_ID, reconciler)
+	saga.AddStep("freeze-points", func() error {
+		return SetPointsStatus(openPoints, types.PointsStatusFrozen, pointsTable, dynaClient)
+	}, func() error {
+		return SetPointsStatus(openPoints, types.PointsStatusActive, pointsTable, dynaClient)
+	})
+	saga.AddStep("reject-maker-requests", func() error {
+		return SetMakerRequestsStatus(pendingRequests, "rejected", SystemCheckerID, makerTable, dynaClient)
+	}, func() error {
+		return SetMakerRequestsStatus(pendingRequests, "pending", "", makerTable, dynaClient)
+	})
+	addStatusSteps(saga, user, types.UserStatusDeleted, deletedAt, tableName, userPoolID, dynaClient, cognitoClient)
+
+	return saga.Run()
+}
+
+// UndeleteUser reverses SoftDeleteUser: the user is made active again, their
+// frozen points accounts are reopened and the maker requests rejected when they
+// were deleted are pending again.
+func UndeleteUser(user types.User, tableName, pointsTable, makerTable, userPoolID string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI,
+	reconciler Reconciler) error {
+	points, err := FetchPointsByUser(user.User_ID, pointsTable, dynaClient)
+	if err != nil {
+		return err
+	}
+	frozenPoints := PointsWithStatus(points, types.PointsStatusFrozen)
+	rejectedRequests, err := FetchRequestsForUser(user.User_ID, "rejected", makerTable, dynaClient)
+	if err != nil {
+		return err
+	}
+	systemRejected := []types.MakerRequest{}
+	for _, request := range rejectedRequests {
+		if request.CheckerUUID == SystemCheckerID {
+			systemRejected = append(systemRejected, request)
+		}
+	}
+
+	saga := NewSaga("undelete-user", user.User_ID, reconciler)
+	saga.AddStep("unfreeze-points", func() error {
+		return SetPointsStatus(frozenPoints, types.PointsStatusActive, pointsTable, dynaClient)
+	}, func() error {
+		return SetPointsStatus(frozenPoints, types.PointsStatusFrozen, pointsTable, dynaClient)
+	})
+	saga.AddStep("reopen-maker-requests", func() error {
+		return SetMakerRequestsStatus(systemRejected, "pending", "", makerTable, dynaClient)
+	}, func() error {
+		return SetMakerRequestsStatus(systemRejected, "rejected", SystemCheckerID, makerTable, dynaClient)
+	})
+	addStatusSteps(saga, user, types.UserStatusActive, 0, tableName, userPoolID, dynaClient, cognitoClient)
+
+	return saga.Run()
+}
+
+// SetUserStatus moves the user to status in dynamo and enables or disables the
+// cognito user to match, restoring the previous status if cognito fails.
+// deletedAt is stored for deleted users and cleared otherwise.
+func SetUserStatus(user types.User, status string, deletedAt int64, tableName, userPoolID string, dynaClient dynamodbiface.DynamoDBAPI,
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI, reconciler Reconciler) error {
+	saga := NewSaga("set-user-status", user.User_ID, reconciler)
+	addStatusSteps(saga, user, status, deletedAt, tableName, userPoolID, dynaClient, cognitoClient)
+	return saga.Run()
+}
+
+// addStatusSteps adds the steps moving the user to status in dynamo and then
+// cognito to the saga.
+func addStatusSteps(saga *Saga, user types.User, status string, deletedAt int64, tableName, userPoolID string,
+	dynaClient dynamodbiface.DynamoDBAPI, cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI) {
+	saga.AddStep("dynamo-update-status", func() error {
+		return updateUserStatus(user.User_ID, status, deletedAt, tableName, dynaClient)
+	}, func() error {
+		return updateUserStatus(user.User_ID, user.Status, user.DeletedAt, tableName, dynaClient)
+	})
+	saga.AddStep("cognito-update-status", func() error {
+		var err error
+		if status == types.UserStatusActive {
+			_, err = cognitoClient.AdminEnableUser(&cognitoidentityprovider.AdminEnableUserInput{
+				UserPoolId: aws.String(userPoolID),
+				Username:   aws.String(user.User_ID),
+			})
+		} else {
+			_, err = cognitoClient.AdminDisableUser(&cognitoidentityprovider.AdminDisableUserInput{
+				UserPoolId: aws.String(userPoolID),
+				Username:   aws.String(user.User_ID),
+			})
+		}
+		if err != nil {
+			log.Println(err)
+			return types.ErrorCognitoActionFailed
+		}
+		return nil
+	}, nil)
+}
+
+func updateUserStatus(userID, status string, deletedAt int64, tableName string, dynaClient dynamodbiface.DynamoDBAPI) error {
+	input := &dynamodb.UpdateItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(userID),
+			},
+		},
+		TableName:        aws.String(tableName),
+		UpdateExpression: aws.String("SET #status = :status REMOVE deleted_at"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":status": {S: aws.String(status)},
+		},
+	}
+	if deletedAt != 0 {
+		input.UpdateExpression = aws.String("SET #status = :status, deleted_at = :deleted_at")
+		input.ExpressionAttributeValues[":deleted_at"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(deletedAt, 10))}
+	}
+
+	if _, err := dynaClient.UpdateItem(input); err != nil {
+		return types.ErrorCouldNotDynamoPutItem
+	}
+	return nil
+}
+
+// FetchTargetUser fetches the user an admin action is aimed at, rejecting
+// deleted users and users whose role the policy cannot target.
+func FetchTargetUser(id, tableName string, dynaClient dynamodbiface.DynamoDBAPI, policy *types.Policy) (*types.User, error) {
+	result, err := dynaClient.GetItem(&dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id": {
+				S: aws.String(id),
+			},
+		},
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return nil, types.ErrorFailedToFetchRecordID
+	}
+	if result.Item == nil {
+		return nil, types.ErrorUserDoesNotExist
+	}
+
+	var user types.User
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &user); err != nil {
+		return nil, types.ErrorFailedToUnmarshal
+	}
+	if !CanTargetRole(policy, user.Role) {
+		return nil, types.ErrorNotPermittedByPolicy
+	}
+	if IsUserDeleted(user) {
+		return nil, types.ErrorUserAlreadyDeleted
+	}
+	return &user, nil
+}
+
+// IsUserDeleted reports whether the user has been soft deleted.
+func IsUserDeleted(user types.User) bool {
+	return user.Status == types.UserStatusDeleted
+}
+
+// IsPastRetention reports whether the soft deleted user has been deleted for
+// longer than retentionDays as of now.
+func IsPastRetention(user types.User, retentionDays int, now time.Time) bool {
+	return now.After(time.Unix(user.DeletedAt, 0).AddDate(0, 0, retentionDays))
+}
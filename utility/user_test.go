@@ -0,0 +1,61 @@
+package utility
+
+import (
+	"ascenda/dynamotest"
+	"ascenda/types"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIsPastRetention(t *testing.T) {
+	deletedAt := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	user := types.User{User_ID: "1", Status: types.UserStatusDeleted, DeletedAt: deletedAt.Unix()}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{"same day", deletedAt.Add(time.Hour), false},
+		{"last day of window", deletedAt.AddDate(0, 0, 30), false},
+		{"after window", deletedAt.AddDate(0, 0, 30).Add(time.Second), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPastRetention(user, 30, tt.now); got != tt.want {
+				t.Errorf("IsPastRetention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConditionalWritesConflict checks writes whose condition fails because
+// the user changed answer a conflict rather than a retryable failure.
+func TestConditionalWritesConflict(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(db *dynamotest.DB) error
+	}{
+		{"role changed before the reassignment", func(db *dynamotest.DB) error {
+			return updateUserRole("1", "support", "viewer", "users", db)
+		}},
+		{"user deleted before the update", func(db *dynamotest.DB) error {
+			current := types.User{User_ID: "2", Email: "gone@example.com", FirstName: "Gone"}
+			updated := current
+			updated.FirstName = "Back"
+			return UpdateUserItem(current, updated, "users", "emails", db)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dynamotest.New(dynamotest.Tables...)
+			db.Seed(t, "users", types.User{User_ID: "1", Role: "admin"})
+			if err := tt.write(db); !errors.Is(err, types.ErrorRecordChanged) {
+				t.Errorf("error = %v, want %v", err, types.ErrorRecordChanged)
+			}
+		})
+	}
+}
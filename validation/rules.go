@@ -0,0 +1,103 @@
+package validation
+
+import "ascenda/types"
+
+const (
+	nameLength     = 100
+	roleLength     = 64
+	passwordLength = 256
+)
+
+var methods = OneOf("GET", "POST", "PUT", "PATCH", "DELETE", "*")
+
+var userFields = OneOf("email", "first_name", "last_name", "role")
+
+// User is the body of create-users. Ids and statuses are set by the server.
+var User = Rules{
+	"email":      Required(String, Email),
+	"first_name": Required(String, MaxLength(nameLength)),
+	"last_name":  Required(String, MaxLength(nameLength)),
+	"role":       Required(String, MaxLength(roleLength)),
+	"password":   Optional(String, MaxLength(passwordLength)),
+}
+
+// UserPatch is the body of update-users, where every field is optional but
+// may not be blanked.
+var UserPatch = Rules{
+	"email":      Optional(String, Email),
+	"first_name": Optional(String, NotBlank, MaxLength(nameLength)),
+	"last_name":  Optional(String, NotBlank, MaxLength(nameLength)),
+	"role":       Optional(String, NotBlank, MaxLength(roleLength)),
+}
+
+// ProfilePatch is the body of update-profile. Users cannot change their email
+// or role.
+var ProfilePatch = Rules{
+	"first_name": Optional(String, NotBlank, MaxLength(nameLength)),
+	"last_name":  Optional(String, NotBlank, MaxLength(nameLength)),
+}
+
+// TemporaryPassword is the body of set-temporary-password.
+var TemporaryPassword = Rules{
+	"password": Required(String, MaxLength(passwordLength)),
+}
+
+// MFAPreference is the body of update-mfa.
+var MFAPreference = Rules{
+	"sms":            Optional(Bool),
+	"software_token": Optional(Bool),
+	"preferred":      Optional(String, OneOf(types.MFAFactorSMS, types.MFAFactorSoftwareToken)),
+}
+
+// Policy is the policy of a role.
+var Policy = Rules{
+	"user_fields":       Optional(List(String, userFields)),
+	"max_points_change": Optional(Integer, Min(0)),
+	"target_roles":      Optional(List(String, NotBlank)),
+}
+
+// Role is the body of create-roles.
+var Role = roleRules(Required(String, MaxLength(roleLength)))
+
+// RoleUpdate is the body of update-roles, which takes the role from the query.
+var RoleUpdate = roleRules(Optional(String))
+
+func roleRules(role Field) Rules {
+	return Rules{
+		"role":     role,
+		"inherits": Optional(List(String, NotBlank)),
+		"access":   Optional(Map(List(String, methods))),
+		"deny":     Optional(Map(List(String, methods))),
+		"policy":   Optional(Object(Policy)),
+	}
+}
+
+// UserPoint is the body of create-points. Accounts always open with 0 points.
+var UserPoint = Rules{
+	"user_id": Required(String, UUID),
+}
+
+// UserPointUpdate is the body of update-points, which takes the user from the
+// query.
+var UserPointUpdate = Rules{
+	"user_id":         Optional(String),
+	"points_id":       Required(String, UUID),
+	"points":          Required(Integer, Min(0)),
+	"expected_points": Optional(Integer, Min(0)),
+}
+
+// NewMakerRequest is the body of create-makers.
+var NewMakerRequest = Rules{
+	"checker_roles": Required(List(String, NotBlank)),
+	"maker_id":      Required(String, UUID),
+	"resource_type": Required(String, OneOf("user", "points")),
+	"request_data":  Required(AnyObject),
+}
+
+// DecisionBody is the body of update-checkers.
+var DecisionBody = Rules{
+	"request_id":   Required(String, UUID),
+	"checker_role": Required(String),
+	"checker_id":   Required(String, UUID),
+	"decision":     Required(String, OneOf("approve", "reject")),
+}
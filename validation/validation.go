@@ -0,0 +1,343 @@
+// Package validation checks json request bodies against declarative rules
+// before they are decoded, so a handler sees every problem of a body at once
+// instead of the first check it happens to make.
+package validation
+
+import (
+	"ascenda/openapi"
+	"ascenda/types"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxBodySize is the largest json body accepted, in bytes.
+const MaxBodySize = 64 << 10
+
+var rxEmail = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]{1,64}@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+
+var rxUUID = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// FieldError is one problem with a field of the body. Field is the path to
+// the field, such as policy.max_points_change or checker_roles[1].
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Rule checks a value as decoded by encoding/json into an interface{}, and
+// describes what it checks in the json schema of the value.
+type Rule struct {
+	// check returns the problems found, with Field relative to the value and
+	// empty for the value itself.
+	check func(value interface{}) []FieldError
+	// describe adds the constraint to the schema of the value.
+	describe func(schema *openapi.Schema)
+}
+
+// Field holds the rules of one field of a body.
+type Field struct {
+	Required bool
+	Rules    []Rule
+}
+
+// Rules maps the json name of every field a body may hold to its rules.
+// Fields not in Rules are rejected.
+type Rules map[string]Field
+
+// Required fields must be present, not null and not blank. Their rules run in
+// order and stop at the first problem.
+func Required(rules ...Rule) Field {
+	return Field{Required: true, Rules: rules}
+}
+
+// Optional fields are only checked when present and not null.
+func Optional(rules ...Rule) Field {
+	return Field{Rules: rules}
+}
+
+// Check validates a json body against rules, failing with ErrorBodyTooLarge,
+// ErrorInvalidBody when the body is not a json object, or
+// ErrorValidationFailed listing every field error.
+func Check(body []byte, rules Rules) error {
+	if len(body) > MaxBodySize {
+		return types.ErrorBodyTooLarge.WithDetails(map[string]int{"max_bytes": MaxBodySize})
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return types.ErrorInvalidBody
+	}
+
+	if errs := rules.check(fields); len(errs) > 0 {
+		return types.ErrorValidationFailed.WithDetails(errs)
+	}
+	return nil
+}
+
+func (r Rules) check(fields map[string]interface{}) []FieldError {
+	var errs []FieldError
+	for name := range fields {
+		if _, ok := r[name]; !ok {
+			errs = append(errs, FieldError{Field: name, Message: "is not a known field"})
+		}
+	}
+
+	for name, field := range r {
+		value, ok := fields[name]
+		if !ok || value == nil {
+			if field.Required {
+				errs = append(errs, FieldError{Field: name, Message: "is required"})
+			}
+			continue
+		}
+		if field.Required && isBlank(value) {
+			errs = append(errs, FieldError{Field: name, Message: "is required"})
+			continue
+		}
+		errs = append(errs, each(name, value, field.Rules)...)
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+	return errs
+}
+
+// Schema describes the bodies the rules accept, for the api documentation.
+// Required fields may not be blank.
+func (r Rules) Schema() *openapi.Schema {
+	schema := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{}, AdditionalProperties: false}
+	for name, field := range r {
+		property := describe(field.Rules)
+		if field.Required {
+			NotBlank.describe(property)
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func describe(rules []Rule) *openapi.Schema {
+	schema := &openapi.Schema{}
+	for _, rule := range rules {
+		rule.describe(schema)
+	}
+	return schema
+}
+
+func isBlank(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func join(parent string, child string) string {
+	switch {
+	case child == "":
+		return parent
+	case strings.HasPrefix(child, "["):
+		return parent + child
+	}
+	return parent + "." + child
+}
+
+// rule makes a Rule reporting message when ok is false.
+func rule(message string, ok func(value interface{}) bool, describe func(schema *openapi.Schema)) Rule {
+	return Rule{
+		check: func(value interface{}) []FieldError {
+			if ok(value) {
+				return nil
+			}
+			return []FieldError{{Message: message}}
+		},
+		describe: describe,
+	}
+}
+
+// typed describes values of a json schema type.
+func typed(name string) func(schema *openapi.Schema) {
+	return func(schema *openapi.Schema) {
+		schema.Type = name
+	}
+}
+
+// formatted describes strings of a json schema format.
+func formatted(format string) func(schema *openapi.Schema) {
+	return func(schema *openapi.Schema) {
+		schema.Format = format
+	}
+}
+
+// IsEmail reports whether s is a well formed email address.
+func IsEmail(s string) bool {
+	return len(s) >= 3 && len(s) <= 254 && rxEmail.MatchString(s)
+}
+
+// Type and format rules. Format rules expect the value to have passed String.
+var (
+	String = rule("must be a string", func(value interface{}) bool {
+		_, ok := value.(string)
+		return ok
+	}, typed("string"))
+	Bool = rule("must be a boolean", func(value interface{}) bool {
+		_, ok := value.(bool)
+		return ok
+	}, typed("boolean"))
+	Integer = rule("must be an integer", func(value interface{}) bool {
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	}, typed("integer"))
+	AnyObject = rule("must be an object", func(value interface{}) bool {
+		_, ok := value.(map[string]interface{})
+		return ok
+	}, typed("object"))
+	NotBlank = rule("must not be blank", func(value interface{}) bool {
+		return !isBlank(value)
+	}, func(schema *openapi.Schema) {
+		switch schema.Type {
+		case "array":
+			schema.MinItems = openapi.Int(1)
+		case "string":
+			schema.MinLength = openapi.Int(1)
+		}
+	})
+	Email = rule("must be a valid email", func(value interface{}) bool {
+		s, _ := value.(string)
+		return IsEmail(s)
+	}, formatted("email"))
+	UUID = rule("must be a uuid", func(value interface{}) bool {
+		s, _ := value.(string)
+		return rxUUID.MatchString(s)
+	}, formatted("uuid"))
+)
+
+// MaxLength limits strings to n characters.
+func MaxLength(n int) Rule {
+	return rule(fmt.Sprintf("must be at most %d characters", n), func(value interface{}) bool {
+		s, _ := value.(string)
+		return utf8.RuneCountInString(s) <= n
+	}, func(schema *openapi.Schema) {
+		schema.MaxLength = openapi.Int(n)
+	})
+}
+
+// Min requires numbers of at least n.
+func Min(n float64) Rule {
+	return rule(fmt.Sprintf("must be at least %v", n), func(value interface{}) bool {
+		v, _ := value.(float64)
+		return v >= n
+	}, func(schema *openapi.Schema) {
+		schema.Minimum = openapi.Float(n)
+	})
+}
+
+// Max requires numbers of at most n.
+func Max(n float64) Rule {
+	return rule(fmt.Sprintf("must be at most %v", n), func(value interface{}) bool {
+		v, _ := value.(float64)
+		return v <= n
+	}, func(schema *openapi.Schema) {
+		schema.Maximum = openapi.Float(n)
+	})
+}
+
+// OneOf limits strings to values.
+func OneOf(values ...string) Rule {
+	return rule("must be one of "+strings.Join(values, ", "), func(value interface{}) bool {
+		s, _ := value.(string)
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+		return false
+	}, func(schema *openapi.Schema) {
+		schema.Enum = values
+	})
+}
+
+// List requires an array and checks every element against rules.
+func List(rules ...Rule) Rule {
+	return Rule{
+		check: func(value interface{}) []FieldError {
+			items, ok := value.([]interface{})
+			if !ok {
+				return []FieldError{{Message: "must be an array"}}
+			}
+			var errs []FieldError
+			for i, item := range items {
+				errs = append(errs, each(fmt.Sprintf("[%d]", i), item, rules)...)
+			}
+			return errs
+		},
+		describe: func(schema *openapi.Schema) {
+			schema.Type = "array"
+			schema.Items = describe(rules)
+		},
+	}
+}
+
+// Map requires an object and checks every value against rules, whatever its
+// key.
+func Map(rules ...Rule) Rule {
+	return Rule{
+		check: func(value interface{}) []FieldError {
+			entries, ok := value.(map[string]interface{})
+			if !ok {
+				return []FieldError{{Message: "must be an object"}}
+			}
+			var errs []FieldError
+			for key, entry := range entries {
+				errs = append(errs, each(key, entry, rules)...)
+			}
+			return errs
+		},
+		describe: func(schema *openapi.Schema) {
+			schema.Type = "object"
+			schema.AdditionalProperties = describe(rules)
+		},
+	}
+}
+
+// Object requires an object whose fields follow rules.
+func Object(rules Rules) Rule {
+	return Rule{
+		check: func(value interface{}) []FieldError {
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				return []FieldError{{Message: "must be an object"}}
+			}
+			return rules.check(fields)
+		},
+		describe: func(schema *openapi.Schema) {
+			*schema = *rules.Schema()
+		},
+	}
+}
+
+// each runs rules on the value at path, stopping at the first problem.
+func each(path string, value interface{}, rules []Rule) []FieldError {
+	for _, rule := range rules {
+		if found := rule.check(value); len(found) > 0 {
+			errs := make([]FieldError, len(found))
+			for i, err := range found {
+				errs[i] = FieldError{Field: join(path, err.Field), Message: err.Message}
+			}
+			return errs
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"ascenda/openapi"
+	"ascenda/types"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		rules Rules
+		want  []FieldError
+	}{
+		{"valid user", `{"email":"jane@example.com","first_name":"Jane","last_name":"Doe","role":"customer"}`, User, nil},
+		{"every user error at once", `{"email":"nope","first_name":" ","user_id":"1"}`, User, []FieldError{
+			{"email", "must be a valid email"},
+			{"first_name", "is required"},
+			{"last_name", "is required"},
+			{"role", "is required"},
+			{"user_id", "is not a known field"},
+		}},
+		{"blanked patch field", `{"last_name":""}`, UserPatch, []FieldError{{"last_name", "must not be blank"}}},
+		{"nested policy", `{"role":"support","policy":{"max_points_change":-5,"user_fields":["email","status"]}}`, Role, []FieldError{
+			{"policy.max_points_change", "must be at least 0"},
+			{"policy.user_fields[1]", "must be one of email, first_name, last_name, role"},
+		}},
+		{"access methods", `{"role":"support","access":{"/users":["GET","FETCH"]}}`, Role, []FieldError{
+			{"access./users[1]", "must be one of GET, POST, PUT, PATCH, DELETE, *"},
+		}},
+		{"fractional points", `{"points_id":"9b2f8a52-3f5e-4b8e-9a3c-2f1d6e7c8b90","points":1.5}`, UserPointUpdate, []FieldError{
+			{"points", "must be an integer"},
+		}},
+		{"no checker roles", `{"checker_roles":[],"maker_id":"9b2f8a52-3f5e-4b8e-9a3c-2f1d6e7c8b90","resource_type":"user","request_data":{}}`,
+			NewMakerRequest, []FieldError{{"checker_roles", "is required"}, {"request_data", "is required"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check([]byte(tt.body), tt.rules)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+				return
+			}
+			var apiErr *types.Error
+			if !errors.As(err, &apiErr) || !errors.Is(err, types.ErrorValidationFailed) {
+				t.Fatalf("Check() error = %v, want %s", err, types.ErrorValidationFailed)
+			}
+			if !reflect.DeepEqual(apiErr.Details, tt.want) {
+				t.Errorf("Check() details = %v, want %v", apiErr.Details, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRejectsMalformedBodies(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"not json", `{"email":`, types.ErrorInvalidBody},
+		{"not an object", `["jane@example.com"]`, types.ErrorInvalidBody},
+		{"too large", `{"password":"` + strings.Repeat("a", MaxBodySize) + `"}`, types.ErrorBodyTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check([]byte(tt.body), TemporaryPassword); !errors.Is(err, tt.want) {
+				t.Errorf("Check() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesSchema(t *testing.T) {
+	schema := Role.Schema()
+
+	if want := []string{"role"}; !reflect.DeepEqual(schema.Required, want) {
+		t.Errorf("required = %v, want %v", schema.Required, want)
+	}
+	if role := schema.Properties["role"]; role.Type != "string" || *role.MinLength != 1 || *role.MaxLength != roleLength {
+		t.Errorf("role = %+v, want a required string of at most %d characters", role, roleLength)
+	}
+	access := schema.Properties["access"]
+	methods, ok := access.AdditionalProperties.(*openapi.Schema)
+	if access.Type != "object" || !ok || methods.Type != "array" || len(methods.Items.Enum) != 6 {
+		t.Errorf("access = %+v, want an object of method lists", access)
+	}
+	policy := schema.Properties["policy"]
+	if policy.Type != "object" || policy.Properties["max_points_change"].Type != "integer" ||
+		*policy.Properties["max_points_change"].Minimum != 0 || policy.AdditionalProperties != false {
+		t.Errorf("policy = %+v, want the policy rules", policy)
+	}
+	if uuid := UserPoint.Schema().Properties["user_id"]; uuid.Format != "uuid" {
+		t.Errorf("user_id = %+v, want a uuid", uuid)
+	}
+}